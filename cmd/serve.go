@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/senatroxx/filmix-backend/internal/config"
 	"github.com/senatroxx/filmix-backend/internal/database"
 	"github.com/senatroxx/filmix-backend/internal/http"
+	"github.com/senatroxx/filmix-backend/internal/integrations/realtime"
 	"github.com/senatroxx/filmix-backend/internal/utilities"
+	"github.com/senatroxx/filmix-backend/internal/workers"
 	"github.com/spf13/cobra"
 )
 
@@ -21,8 +25,55 @@ var serveCmd = &cobra.Command{
 		}
 		defer db.Close()
 
-		hr := config.InitializeHandlers(config.InitializeServices(config.InitializeRepositories(db)))
+		expiryInterval, err := time.ParseDuration(cfg.Booking.ExpiryInterval)
+		if err != nil {
+			utilities.Logger.Fatal().Err(err).Msg("Invalid BOOKING_EXPIRY_INTERVAL")
+		}
+		if expiryInterval <= 0 {
+			utilities.Logger.Fatal().Msgf("BOOKING_EXPIRY_INTERVAL must be positive, got %s", expiryInterval)
+		}
+
+		idempotencyPurgeInterval, err := time.ParseDuration(cfg.Idempotency.PurgeInterval)
+		if err != nil {
+			utilities.Logger.Fatal().Err(err).Msg("Invalid IDEMPOTENCY_PURGE_INTERVAL")
+		}
+		if idempotencyPurgeInterval <= 0 {
+			utilities.Logger.Fatal().Msgf("IDEMPOTENCY_PURGE_INTERVAL must be positive, got %s", idempotencyPurgeInterval)
+		}
+
+		waitlistInterval, err := time.ParseDuration(cfg.Waitlist.Interval)
+		if err != nil {
+			utilities.Logger.Fatal().Err(err).Msg("Invalid WAITLIST_INTERVAL")
+		}
+		if waitlistInterval <= 0 {
+			utilities.Logger.Fatal().Msgf("WAITLIST_INTERVAL must be positive, got %s", waitlistInterval)
+		}
+
+		var seatEvents realtime.Broker
+		var seatEventsListener *realtime.PostgresBroker
+		switch cfg.SeatEvents.Driver {
+		case "local":
+			seatEvents = realtime.NewLocalBroker()
+		case "postgres":
+			seatEventsListener = realtime.NewPostgresBroker(db, database.DSN(&cfg.Database), utilities.Logger)
+			seatEvents = seatEventsListener
+		default:
+			utilities.Logger.Fatal().Msgf("Invalid SEAT_EVENTS_DRIVER %q", cfg.SeatEvents.Driver)
+		}
+
+		svc, err := config.InitializeServices(&cfg, config.InitializeRepositories(db), seatEvents)
+		if err != nil {
+			utilities.Logger.Fatal().Err(err).Msg("Failed to initialize services")
+		}
+		hr := config.InitializeHandlers(svc)
 		srv := http.InitializeAPI(&cfg, hr, db, utilities.Logger)
+		srv.BeforeShutdown(seatEvents.Close)
+		if seatEventsListener != nil {
+			srv.AddWorker(seatEventsListener)
+		}
+		srv.AddWorker(workers.NewExpiryWorker(svc.BookingService, expiryInterval, cfg.Booking.ExpiryBatchSize, utilities.Logger))
+		srv.AddWorker(workers.NewIdempotencyPurgeWorker(svc.IdempotencyService, idempotencyPurgeInterval, utilities.Logger))
+		srv.AddWorker(workers.NewWaitlistWorker(svc.WaitlistService, waitlistInterval, utilities.Logger))
 		srv.Run()
 	},
 }
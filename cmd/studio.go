@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/senatroxx/filmix-backend/internal/config"
+	"github.com/senatroxx/filmix-backend/internal/database"
+	"github.com/senatroxx/filmix-backend/internal/services"
+)
+
+var (
+	studioID     string
+	studioDryRun bool
+)
+
+var studioCmd = &cobra.Command{
+	Use:   "studio",
+	Short: "Studio seat map commands",
+}
+
+var studioImportCmd = &cobra.Command{
+	Use:   "import --studio <id> <layout.csv|layout.json>",
+	Short: "Replace a studio's seat map with a layout file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := parseStudioID()
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			log.Fatalf("Failed to open layout: %v", err)
+		}
+		defer file.Close()
+
+		var layout *services.StudioLayout
+		if strings.EqualFold(filepath.Ext(args[0]), ".json") {
+			layout, err = services.ParseLayoutJSON(file)
+		} else {
+			layout, err = services.ParseLayoutCSV(file)
+		}
+		if err != nil {
+			log.Fatal(describeLayoutError(err))
+		}
+
+		var changes *services.LayoutChanges
+		err = withStudioService(func(s services.IStudioService) error {
+			var err error
+			changes, err = s.ImportLayout(context.Background(), id, layout, studioDryRun)
+			return err
+		})
+		if err != nil {
+			log.Fatal(describeLayoutError(err))
+		}
+
+		for _, change := range []struct {
+			name   string
+			labels []string
+		}{
+			{"Added", changes.Added},
+			{"Reactivated", changes.Reactivated},
+			{"Deactivated", changes.Deactivated},
+			{"Retyped", changes.Retyped},
+			{"Updated", changes.Updated},
+		} {
+			fmt.Printf("%-12s %d %s\n", change.name+":", len(change.labels), strings.Join(change.labels, " "))
+		}
+		fmt.Printf("%-12s %d\n", "Gaps:", changes.Gaps)
+
+		if changes.Applied {
+			fmt.Println("Layout imported.")
+		} else {
+			fmt.Println("Dry run, nothing changed.")
+		}
+	},
+}
+
+var studioExportCmd = &cobra.Command{
+	Use:   "export --studio <id> [layout.csv|layout.json]",
+	Short: "Write a studio's seat map as a layout file, or to stdout as CSV",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := parseStudioID()
+
+		var layout *services.StudioLayout
+		err := withStudioService(func(s services.IStudioService) error {
+			var err error
+			layout, err = s.ExportLayout(context.Background(), id)
+			return err
+		})
+		if err != nil {
+			log.Fatal(describeLayoutError(err))
+		}
+
+		var out io.Writer = os.Stdout
+		var file *os.File
+		path := ""
+		if len(args) == 1 {
+			path = args[0]
+			file, err = os.Create(path)
+			if err != nil {
+				log.Fatalf("Failed to create layout file: %v", err)
+			}
+			out = file
+		}
+
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(layout)
+		} else {
+			err = layout.WriteCSV(out)
+		}
+		if err != nil {
+			log.Fatalf("Failed to write layout: %v", err)
+		}
+
+		// A failed close can mean the layout never fully reached the disk.
+		if file != nil {
+			if err := file.Close(); err != nil {
+				log.Fatalf("Failed to write layout: %v", err)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(studioCmd)
+	studioCmd.AddCommand(studioImportCmd, studioExportCmd)
+
+	studioCmd.PersistentFlags().StringVar(&studioID, "studio", "", "ID of the studio")
+	studioCmd.MarkPersistentFlagRequired("studio")
+	studioImportCmd.Flags().BoolVar(&studioDryRun, "dry-run", false, "only report what would change")
+}
+
+func parseStudioID() uuid.UUID {
+	id, err := uuid.Parse(studioID)
+	if err != nil {
+		log.Fatalf("Invalid studio ID %q", studioID)
+	}
+	return id
+}
+
+// withStudioService runs fn against the database from the configuration.
+func withStudioService(fn func(services.IStudioService) error) error {
+	cfg := config.Load()
+
+	db, err := database.Connect(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	r := config.InitializeRepositories(db)
+	return fn(services.NewStudioService(r.StudioRepository, r.SeatRepository))
+}
+
+// describeLayoutError spells out what went wrong with a layout import or
+// export, one problem per line.
+func describeLayoutError(err error) string {
+	var invalid *services.LayoutError
+	if errors.As(err, &invalid) {
+		return "Invalid layout:\n  " + strings.Join(invalid.Problems, "\n  ")
+	}
+
+	var selection *services.SeatSelectionError
+	if errors.As(err, &selection) && errors.Is(err, services.ErrSeatsInUse) {
+		ids := make([]string, len(selection.SeatIDs))
+		for i, id := range selection.SeatIDs {
+			ids[i] = id.String()
+		}
+		return "Seats to remove are booked for upcoming showtimes:\n  " + strings.Join(ids, "\n  ")
+	}
+
+	return err.Error()
+}
@@ -2,10 +2,18 @@ package config
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/senatroxx/filmix-backend/internal/http/handlers"
+	"github.com/senatroxx/filmix-backend/internal/integrations/notification"
+	"github.com/senatroxx/filmix-backend/internal/integrations/payment"
+	"github.com/senatroxx/filmix-backend/internal/integrations/realtime"
 	"github.com/senatroxx/filmix-backend/internal/repositories"
 	"github.com/senatroxx/filmix-backend/internal/services"
+	"github.com/senatroxx/filmix-backend/internal/utilities"
 )
 
 func InitializeHandlers(s *services.Services) *handlers.Handlers {
@@ -16,6 +24,85 @@ func InitializeRepositories(db *sql.DB) *repositories.Repositories {
 	return repositories.RegisterRepositories(db)
 }
 
-func InitializeServices(r *repositories.Repositories) *services.Services {
-	return services.RegisterServices(r)
+func InitializeServices(cfg *Config, r *repositories.Repositories, seatEvents realtime.Broker) (*services.Services, error) {
+	payments, err := InitializePaymentProviders(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APP_TIMEZONE %q: %w", cfg.Timezone, err)
+	}
+
+	refundCutoff, err := time.ParseDuration(cfg.Booking.RefundCutoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BOOKING_REFUND_CUTOFF %q: %w", cfg.Booking.RefundCutoff, err)
+	}
+
+	velocityWindow, err := time.ParseDuration(cfg.Booking.VelocityWindow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BOOKING_VELOCITY_WINDOW %q: %w", cfg.Booking.VelocityWindow, err)
+	}
+
+	checkInOpensBefore, err := time.ParseDuration(cfg.Ticket.CheckInOpensBefore)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TICKET_CHECKIN_OPENS_BEFORE %q: %w", cfg.Ticket.CheckInOpensBefore, err)
+	}
+
+	checkInClosesAfter, err := time.ParseDuration(cfg.Ticket.CheckInClosesAfter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TICKET_CHECKIN_CLOSES_AFTER %q: %w", cfg.Ticket.CheckInClosesAfter, err)
+	}
+
+	idempotencyTTL, err := time.ParseDuration(cfg.Idempotency.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_TTL %q: %w", cfg.Idempotency.TTL, err)
+	}
+
+	waitlistHoldTTL, err := time.ParseDuration(cfg.Waitlist.HoldTTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WAITLIST_HOLD_TTL %q: %w", cfg.Waitlist.HoldTTL, err)
+	}
+
+	return services.RegisterServices(r, services.Options{
+		Payments:               payments,
+		AllowPaymentSimulation: cfg.Mode != "prod",
+		Location:               location,
+		Refunds: services.RefundPolicy{
+			Cutoff:     refundCutoff,
+			FeePercent: cfg.Booking.RefundFeePercent,
+		},
+		Limits: services.PurchaseLimits{
+			MaxSeatsPerBooking:   cfg.Booking.MaxSeatsPerBooking,
+			MaxSeatsPerShowtime:  cfg.Booking.MaxSeatsPerShowtime,
+			MaxPendingBookings:   cfg.Booking.MaxPendingBookings,
+			MaxBookingsPerWindow: cfg.Booking.MaxBookingsPerWindow,
+			VelocityWindow:       velocityWindow,
+		},
+		Tickets: services.TicketPolicy{
+			Secret:      []byte(cfg.Ticket.Secret),
+			OpensBefore: checkInOpensBefore,
+			ClosesAfter: checkInClosesAfter,
+		},
+		IdempotencyTTL:  idempotencyTTL,
+		Notifier:        notification.NewLogNotifier(utilities.Logger),
+		WaitlistHoldTTL: waitlistHoldTTL,
+		SeatEvents:      seatEvents,
+	})
+}
+
+// InitializePaymentProviders maps each seeded payment method code to its
+// provider. Only the local simulator is wired up for now. Webhooks are
+// signed with PAYMENT_WEBHOOK_SECRET, which must be set.
+func InitializePaymentProviders(cfg *Config) (*payment.Registry, error) {
+	if cfg.Payment.WebhookSecret == "" {
+		return nil, errors.New("PAYMENT_WEBHOOK_SECRET must be set")
+	}
+
+	registry := payment.NewRegistry()
+	registry.Register("GOPAY", payment.NewSimulatedProvider("GOPAY", payment.KindDeeplink, cfg.Payment.WebhookSecret))
+	registry.Register("OVO", payment.NewSimulatedProvider("OVO", payment.KindDeeplink, cfg.Payment.WebhookSecret))
+	registry.Register("BCA_VA", payment.NewSimulatedProvider("BCA_VA", payment.KindVirtualAccount, cfg.Payment.WebhookSecret))
+	return registry, nil
 }
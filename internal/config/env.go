@@ -12,9 +12,16 @@ type Config struct {
 	Port      string
 	JWTSecret string
 	Mode      string
+	Timezone  string
 
-	Database   DatabaseConfig
-	TmdbApiKey string
+	Database    DatabaseConfig
+	Booking     BookingConfig
+	Payment     PaymentConfig
+	Ticket      TicketConfig
+	Idempotency IdempotencyConfig
+	Waitlist    WaitlistConfig
+	SeatEvents  SeatEventsConfig
+	TmdbApiKey  string
 }
 
 type DatabaseConfig struct {
@@ -29,6 +36,45 @@ type DatabaseConfig struct {
 	SSLMode      string
 }
 
+type BookingConfig struct {
+	ExpiryInterval   string
+	ExpiryBatchSize  int
+	RefundCutoff     string
+	RefundFeePercent int
+
+	MaxSeatsPerBooking   int
+	MaxSeatsPerShowtime  int
+	MaxPendingBookings   int
+	MaxBookingsPerWindow int
+	VelocityWindow       string
+}
+
+type PaymentConfig struct {
+	WebhookSecret string
+}
+
+type IdempotencyConfig struct {
+	TTL           string
+	PurgeInterval string
+}
+
+type WaitlistConfig struct {
+	HoldTTL  string
+	Interval string
+}
+
+// SeatEventsConfig selects how live seat updates reach the API instances:
+// "local" keeps them in process, "postgres" fans them out via LISTEN/NOTIFY.
+type SeatEventsConfig struct {
+	Driver string
+}
+
+type TicketConfig struct {
+	Secret             string
+	CheckInOpensBefore string
+	CheckInClosesAfter string
+}
+
 func Load() Config {
 	// load .env file if exists
 	if err := godotenv.Load(); err != nil {
@@ -39,6 +85,7 @@ func Load() Config {
 		Port:       getEnv("PORT", "3000"),
 		JWTSecret:  getEnv("JWT_SECRET", ""),
 		Mode:       getEnv("APP_MODE", "development"),
+		Timezone:   getEnv("APP_TIMEZONE", "Asia/Jakarta"),
 		TmdbApiKey: getEnv("TMDB_API_KEY", ""),
 
 		Database: DatabaseConfig{
@@ -52,6 +99,43 @@ func Load() Config {
 			MaxIdleTime:  getEnv("DB_MAX_IDLE_TIME", "15m"),
 			SSLMode:      getEnv("DB_SSL_MODE", "disable"),
 		},
+
+		Booking: BookingConfig{
+			ExpiryInterval:   getEnv("BOOKING_EXPIRY_INTERVAL", "1m"),
+			ExpiryBatchSize:  getEnv("BOOKING_EXPIRY_BATCH_SIZE", 100),
+			RefundCutoff:     getEnv("BOOKING_REFUND_CUTOFF", "2h"),
+			RefundFeePercent: getEnv("BOOKING_REFUND_FEE_PERCENT", 0),
+
+			MaxSeatsPerBooking:   getEnv("BOOKING_MAX_SEATS_PER_BOOKING", 10),
+			MaxSeatsPerShowtime:  getEnv("BOOKING_MAX_SEATS_PER_SHOWTIME", 10),
+			MaxPendingBookings:   getEnv("BOOKING_MAX_PENDING", 3),
+			MaxBookingsPerWindow: getEnv("BOOKING_VELOCITY_LIMIT", 10),
+			VelocityWindow:       getEnv("BOOKING_VELOCITY_WINDOW", "1h"),
+		},
+
+		Payment: PaymentConfig{
+			WebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		},
+
+		Ticket: TicketConfig{
+			Secret:             getEnv("TICKET_SECRET", ""),
+			CheckInOpensBefore: getEnv("TICKET_CHECKIN_OPENS_BEFORE", "1h"),
+			CheckInClosesAfter: getEnv("TICKET_CHECKIN_CLOSES_AFTER", "30m"),
+		},
+
+		Idempotency: IdempotencyConfig{
+			TTL:           getEnv("IDEMPOTENCY_TTL", "24h"),
+			PurgeInterval: getEnv("IDEMPOTENCY_PURGE_INTERVAL", "1h"),
+		},
+
+		Waitlist: WaitlistConfig{
+			HoldTTL:  getEnv("WAITLIST_HOLD_TTL", "10m"),
+			Interval: getEnv("WAITLIST_INTERVAL", "30s"),
+		},
+
+		SeatEvents: SeatEventsConfig{
+			Driver: getEnv("SEAT_EVENTS_DRIVER", "local"),
+		},
 	}
 
 	if cfg.JWTSecret == "" {
@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// How the price difference of an amendment is settled with the customer.
+const (
+	AmendmentSettlementNone   = "none"
+	AmendmentSettlementCharge = "charge"
+	AmendmentSettlementCredit = "credit"
+)
+
+// BookingAmendment records a change of seats or showtime on a booking. The
+// items it replaced stay on the transaction, marked with ReplacedAt. A change
+// that costs extra only moves the booking once its difference is paid; until
+// ExpiresAt its new seats (Items) are held for the booking. A change paid too
+// late to cancel its charge waits for the payment (SettlementPendingAt); a
+// payment that can no longer be applied is refunded (RefundAmount).
+type BookingAmendment struct {
+	ID                  uuid.UUID            `json:"id"`
+	TransactionID       uuid.UUID            `json:"transaction_id"`
+	FromShowtimeID      uuid.UUID            `json:"from_showtime_id"`
+	ToShowtimeID        uuid.UUID            `json:"to_showtime_id"`
+	PreviousAmount      int64                `json:"previous_amount"`
+	NewAmount           int64                `json:"new_amount"`
+	Difference          int64                `json:"difference"`
+	Settlement          string               `json:"settlement"`
+	SettlementRef       *string              `json:"settlement_ref,omitempty"`
+	PaymentInstructions *PaymentInstructions `json:"payment_instructions,omitempty"`
+	SettledAt           *time.Time           `json:"settled_at,omitempty"`
+	ExpiresAt           *time.Time           `json:"expires_at,omitempty"`
+	ExpiredAt           *time.Time           `json:"expired_at,omitempty"`
+	SettlementPendingAt *time.Time           `json:"settlement_pending_at,omitempty"`
+	RefundAmount        *int64               `json:"refund_amount,omitempty"`
+	RefundRef           *string              `json:"refund_ref,omitempty"`
+	RefundedAt          *time.Time           `json:"refunded_at,omitempty"`
+	Actor               string               `json:"actor"`
+	CreatedAt           time.Time            `json:"created_at"`
+
+	Items []TransactionItem `json:"items,omitempty"`
+}
@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConcessionItem is food or drink a theater sells alongside tickets, e.g. a
+// popcorn combo. What is actually ordered is one of its variants.
+type ConcessionItem struct {
+	ID          uuid.UUID `json:"id"`
+	TheaterID   uuid.UUID `json:"theater_id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	Category    string    `json:"category"`
+	ImageURL    *string   `json:"image_url,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Variants []ConcessionVariant `json:"variants,omitempty"`
+}
+
+// ConcessionVariant is an orderable size or flavour of a concession item.
+// Available is the stock left after unpaid orders, when loaded for sale.
+type ConcessionVariant struct {
+	ID        uuid.UUID `json:"id"`
+	ItemID    uuid.UUID `json:"item_id"`
+	Name      string    `json:"name"`
+	Price     int64     `json:"price"`
+	Stock     int       `json:"stock"`
+	Available int       `json:"available"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Item *ConcessionItem `json:"item,omitempty"`
+}
+
+// TransactionAddon is a concession line on a transaction, priced when ordered.
+type TransactionAddon struct {
+	ID              uuid.UUID  `json:"id"`
+	TransactionID   uuid.UUID  `json:"transaction_id"`
+	VariantID       uuid.UUID  `json:"variant_id"`
+	Quantity        int        `json:"quantity"`
+	UnitPrice       int64      `json:"unit_price"`
+	StockDeductedAt *time.Time `json:"stock_deducted_at,omitempty"`
+	StockRestoredAt *time.Time `json:"stock_restored_at,omitempty"`
+
+	Variant *ConcessionVariant `json:"variant,omitempty"`
+}
@@ -0,0 +1,47 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// What a fee rule or transaction charge adds to the order.
+const (
+	ChargeKindConvenienceFee = "convenience_fee"
+	ChargeKindPaymentFee     = "payment_fee"
+	ChargeKindTax            = "tax"
+)
+
+// How a fee rule's value is applied. Per-ticket and per-order values are in
+// minor units; percent values are in basis points (1100 = 11%).
+const (
+	FeeBasisPerTicket = "per_ticket"
+	FeeBasisPerOrder  = "per_order"
+	FeeBasisPercent   = "percent"
+)
+
+// FeeRule is a fee or tax a theater adds on top of the ticket price. Payment
+// fees only apply when PaymentMethodTypeID matches the chosen method's type.
+type FeeRule struct {
+	ID                  uuid.UUID  `json:"id"`
+	TheaterID           uuid.UUID  `json:"theater_id"`
+	Kind                string     `json:"kind"`
+	Name                string     `json:"name"`
+	Basis               string     `json:"basis"`
+	Value               int64      `json:"value"`
+	PaymentMethodTypeID *uuid.UUID `json:"payment_method_type_id,omitempty"`
+	Active              bool       `json:"active"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// TransactionCharge is one fee or tax line on a transaction, next to its
+// seat items.
+type TransactionCharge struct {
+	ID            uuid.UUID  `json:"id"`
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Kind          string     `json:"kind"`
+	Name          string     `json:"name"`
+	Amount        int64      `json:"amount"`
+	FeeRuleID     *uuid.UUID `json:"fee_rule_id,omitempty"`
+}
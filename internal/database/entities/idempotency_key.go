@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey is a client supplied key reserving a mutating request. Until
+// the request completes StatusCode is nil; afterwards the stored response is
+// replayed to retries that carry the same key and body.
+type IdempotencyKey struct {
+	UserID       uuid.UUID `json:"user_id"`
+	Key          string    `json:"key"`
+	Fingerprint  string    `json:"fingerprint"`
+	StatusCode   *int      `json:"status_code,omitempty"`
+	ContentType  string    `json:"content_type"`
+	ResponseBody []byte    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DiscountTypePercent = "percent"
+	DiscountTypeFixed   = "fixed"
+)
+
+// Promotion is a promo code customers can redeem at checkout. Nil limits and
+// scopes mean unlimited and unrestricted.
+type Promotion struct {
+	ID              uuid.UUID  `json:"id"`
+	Code            string     `json:"code"`
+	Description     string     `json:"description"`
+	DiscountType    string     `json:"discount_type"`
+	DiscountValue   int64      `json:"discount_value"`
+	MaxDiscount     *int64     `json:"max_discount,omitempty"`
+	MinSpend        int64      `json:"min_spend"`
+	UsageLimit      *int       `json:"usage_limit,omitempty"`
+	PerUserLimit    *int       `json:"per_user_limit,omitempty"`
+	Active          bool       `json:"active"`
+	StartsAt        *time.Time `json:"starts_at,omitempty"`
+	EndsAt          *time.Time `json:"ends_at,omitempty"`
+	MovieID         *uuid.UUID `json:"movie_id,omitempty"`
+	TheaterID       *uuid.UUID `json:"theater_id,omitempty"`
+	SeatTypeID      *uuid.UUID `json:"seat_type_id,omitempty"`
+	PaymentMethodID *uuid.UUID `json:"payment_method_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type PromotionRedemption struct {
+	ID            uuid.UUID `json:"id"`
+	PromotionID   uuid.UUID `json:"promotion_id"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	UserID        uuid.UUID `json:"user_id"`
+	Amount        int64     `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -9,6 +9,15 @@ type Seat struct {
     Active     bool      `json:"active"`
     StudioID   uuid.UUID `json:"studio_id"`
     SeatTypeID uuid.UUID `json:"seat_type_id"`
+    // GridRow and GridColumn place the seat on its studio's seat map, 1-based.
+    GridRow    int `json:"grid_row"`
+    GridColumn int `json:"grid_column"`
+    // Wheelchair marks a space for a wheelchair; Companion a seat next to one
+    // for whoever accompanies its user.
+    Wheelchair bool `json:"wheelchair"`
+    Companion  bool `json:"companion"`
+    // PairedWithID is the other half of a couple seat.
+    PairedWithID *uuid.UUID `json:"paired_with_id,omitempty"`
 
     Studio   *Studio   `json:"studio,omitempty"`
     SeatType *SeatType `json:"seat_type,omitempty"`
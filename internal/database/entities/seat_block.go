@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SeatBlock takes a seat off sale, either for one showtime or for every
+// showtime of its studio starting in [StartsAt, EndsAt). It stays in force
+// until staff release it.
+type SeatBlock struct {
+	ID         uuid.UUID  `json:"id"`
+	SeatID     uuid.UUID  `json:"seat_id"`
+	ShowtimeID *uuid.UUID `json:"showtime_id,omitempty"`
+	StartsAt   *time.Time `json:"starts_at,omitempty"`
+	EndsAt     *time.Time `json:"ends_at,omitempty"`
+	Reason     string     `json:"reason"`
+	CreatedBy  uuid.UUID  `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReleasedBy *uuid.UUID `json:"released_by,omitempty"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+
+	Seat *Seat `json:"seat,omitempty"`
+}
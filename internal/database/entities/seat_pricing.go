@@ -2,6 +2,11 @@ package entities
 
 import "github.com/google/uuid"
 
+const (
+	DayTypeWeekday = "weekday"
+	DayTypeWeekend = "weekend"
+)
+
 type SeatPricing struct {
 	ID         uuid.UUID `json:"id"`
 	Price      int64     `json:"price"`
@@ -1,14 +1,21 @@
 package entities
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type SeatPricingOverride struct {
-	ID         uuid.UUID `json:"id"`
-	Price      int64     `json:"price"`
-	Notes      string    `json:"notes"`
-	MovieID    uuid.UUID `json:"movie_id"`
-	SeatTypeID uuid.UUID `json:"seat_type_id"`
-	TheaterID  uuid.UUID `json:"theater_id"`
+	ID         uuid.UUID  `json:"id"`
+	Price      int64      `json:"price"`
+	Notes      string     `json:"notes"`
+	Active     bool       `json:"active"`
+	StartsAt   *time.Time `json:"starts_at,omitempty"`
+	EndsAt     *time.Time `json:"ends_at,omitempty"`
+	MovieID    uuid.UUID  `json:"movie_id"`
+	SeatTypeID uuid.UUID  `json:"seat_type_id"`
+	TheaterID  uuid.UUID  `json:"theater_id"`
 
 	Movie    *Movie    `json:"movie,omitempty"`
 	SeatType *SeatType `json:"seat_type,omitempty"`
@@ -21,4 +21,19 @@ type Showtime struct {
     Theater  *Theater            `json:"theater,omitempty"`
     Pricing  *SeatPricing        `json:"pricing,omitempty"`
     Override *SeatPricingOverride `json:"override,omitempty"`
+
+    Availability []SeatAvailability `json:"availability,omitempty"`
+}
+
+// SeatAvailability counts the seats of one seat type for a showtime. Each
+// seat counts once: as booked if paid for, else as held (by a pending booking
+// or for a waitlisted user), else as blocked, else as available.
+type SeatAvailability struct {
+    SeatTypeID uuid.UUID `json:"seat_type_id"`
+    SeatType   string    `json:"seat_type"`
+    Total      int       `json:"total"`
+    Available  int       `json:"available"`
+    Held       int       `json:"held"`
+    Booked     int       `json:"booked"`
+    Blocked    int       `json:"blocked"`
 }
@@ -2,11 +2,34 @@ package entities
 
 import "github.com/google/uuid"
 
+// Where the screen is drawn on a studio's seat map.
+const (
+    ScreenPositionTop    = "top"
+    ScreenPositionBottom = "bottom"
+)
+
+// Kinds of seat map cells that hold no seat but are drawn.
+const (
+    GapKindAisle  = "aisle"
+    GapKindStairs = "stairs"
+)
+
 type Studio struct {
-    ID        uuid.UUID `json:"id"`
-    Name      string    `json:"name"`
-    TheaterID uuid.UUID `json:"theater_id"`
+    ID             uuid.UUID `json:"id"`
+    Name           string    `json:"name"`
+    TheaterID      uuid.UUID `json:"theater_id"`
+    ScreenPosition string    `json:"screen_position"`
+
+    Theater *Theater    `json:"theater,omitempty"`
+    Seats   []Seat      `json:"seats,omitempty"`
+    Gaps    []StudioGap `json:"gaps,omitempty"`
+}
 
-    Theater *Theater `json:"theater,omitempty"`
-    Seats   []Seat   `json:"seats,omitempty"`
+// StudioGap is a seat map cell without a seat, such as an aisle.
+type StudioGap struct {
+    ID         uuid.UUID `json:"id"`
+    StudioID   uuid.UUID `json:"studio_id"`
+    GridRow    int       `json:"grid_row"`
+    GridColumn int       `json:"grid_column"`
+    Kind       string    `json:"kind"`
 }
@@ -4,6 +4,7 @@ import "github.com/google/uuid"
 
 type Theater struct {
     ID       uuid.UUID `json:"id"`
+    Code     string    `json:"code"`
     Name     string    `json:"name"`
     Address  string    `json:"address"`
     Latitude  float64   `json:"latitude"`
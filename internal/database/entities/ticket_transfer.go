@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lifecycle of a ticket transfer: pending until the recipient accepts or
+// declines it, or the sender cancels it.
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusAccepted  = "accepted"
+	TransferStatusDeclined  = "declined"
+	TransferStatusCancelled = "cancelled"
+)
+
+// TicketTransfer hands the ticket of one seat from its current holder to
+// another user. Accepted transfers of a seat, oldest first, are its chain of
+// custody.
+type TicketTransfer struct {
+	ID                uuid.UUID  `json:"id"`
+	TransactionID     uuid.UUID  `json:"transaction_id"`
+	TransactionItemID uuid.UUID  `json:"transaction_item_id"`
+	FromUserID        uuid.UUID  `json:"from_user_id"`
+	ToUserID          uuid.UUID  `json:"to_user_id"`
+	Status            string     `json:"status"`
+	RespondedAt       *time.Time `json:"responded_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+
+	FromUser *User     `json:"from_user,omitempty"`
+	ToUser   *User     `json:"to_user,omitempty"`
+	Seat     *Seat     `json:"seat,omitempty"`
+	Showtime *Showtime `json:"showtime,omitempty"`
+}
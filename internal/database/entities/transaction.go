@@ -7,20 +7,44 @@ import (
 
 type Transaction struct {
     ID             uuid.UUID  `json:"id"`
-    Status         string     `json:"status"`
-    ExternalRef    string     `json:"external_ref"`
+    Status         TransactionStatus `json:"status"`
+    ExternalRef    *string    `json:"external_ref,omitempty"`
     InvoiceNumber  *string    `json:"invoice_number,omitempty"`
+    PickupCode     *string    `json:"pickup_code,omitempty"`
     Amount         int64      `json:"amount"`
+    DiscountAmount int64      `json:"discount_amount"`
+    PromotionID    *uuid.UUID `json:"promotion_id,omitempty"`
     ExpiredAt      time.Time  `json:"expired_at"`
     PaidAt         *time.Time `json:"paid_at,omitempty"`
+    CancelledAt    *time.Time `json:"cancelled_at,omitempty"`
+    RefundAmount   *int64     `json:"refund_amount,omitempty"`
+    RefundRef      *string    `json:"refund_ref,omitempty"`
+    RefundedAt     *time.Time `json:"refunded_at,omitempty"`
+    CheckedInAt    *time.Time `json:"checked_in_at,omitempty"`
+    CheckedInBy    *uuid.UUID `json:"checked_in_by,omitempty"`
     PaymentMethodID uuid.UUID `json:"payment_method_id"`
     ShowtimeID      uuid.UUID `json:"showtime_id"`
     TheaterID       uuid.UUID `json:"theater_id"`
     UserID          uuid.UUID `json:"user_id"`
 
+    PaymentInstructions *PaymentInstructions `json:"payment_instructions,omitempty"`
+
     PaymentMethod *PaymentMethod `json:"payment_method,omitempty"`
+    Promotion     *Promotion     `json:"promotion,omitempty"`
     Showtime      *Showtime      `json:"showtime,omitempty"`
     Theater       *Theater       `json:"theater,omitempty"`
     User          *User          `json:"user,omitempty"`
     Items         []TransactionItem `json:"items,omitempty"`
+    Charges       []TransactionCharge `json:"charges,omitempty"`
+    Addons        []TransactionAddon `json:"addons,omitempty"`
+    Amendments    []BookingAmendment `json:"amendments,omitempty"`
+}
+
+// PaymentInstructions is what the provider told the customer to do to pay,
+// persisted as JSON on the transaction.
+type PaymentInstructions struct {
+    Kind      string    `json:"kind"`
+    VANumber  string    `json:"va_number,omitempty"`
+    Deeplink  string    `json:"deeplink,omitempty"`
+    ExpiresAt time.Time `json:"expires_at"`
 }
@@ -1,6 +1,9 @@
 package entities
 
-import "github.com/google/uuid"
+import (
+    "time"
+    "github.com/google/uuid"
+)
 
 type TransactionItem struct {
     ID            uuid.UUID `json:"id"`
@@ -8,8 +11,15 @@ type TransactionItem struct {
     TransactionID uuid.UUID `json:"transaction_id"`
     SeatID        uuid.UUID `json:"seat_id"`
     SeatTypeID    uuid.UUID `json:"seat_type_id"`
+    ReplacedAt    *time.Time `json:"replaced_at,omitempty"`
+    // HolderID is who the seat's ticket was transferred to; nil while it is
+    // with the booking owner.
+    HolderID      *uuid.UUID `json:"holder_id,omitempty"`
+    CheckedInAt   *time.Time `json:"checked_in_at,omitempty"`
+    CheckedInBy   *uuid.UUID `json:"checked_in_by,omitempty"`
 
     Transaction *Transaction `json:"transaction,omitempty"`
     Seat        *Seat        `json:"seat,omitempty"`
     SeatType    *SeatType    `json:"seat_type,omitempty"`
+    Holder      *User        `json:"holder,omitempty"`
 }
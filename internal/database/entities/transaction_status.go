@@ -0,0 +1,92 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransactionStatus string
+
+const (
+	TransactionStatusPending       TransactionStatus = "pending"
+	TransactionStatusPaid          TransactionStatus = "paid"
+	TransactionStatusUsed          TransactionStatus = "used"
+	TransactionStatusExpired       TransactionStatus = "expired"
+	TransactionStatusCancelled     TransactionStatus = "cancelled"
+	TransactionStatusRefundPending TransactionStatus = "refund_pending"
+	TransactionStatusRefunded      TransactionStatus = "refunded"
+)
+
+// transactionTransitions lists, per status, the statuses a transaction may
+// move to next. Statuses without an entry are final.
+var transactionTransitions = map[TransactionStatus][]TransactionStatus{
+	TransactionStatusPending: {
+		TransactionStatusPaid,
+		TransactionStatusExpired,
+		TransactionStatusCancelled,
+	},
+	// A payment that lands after the booking stopped being payable is refunded.
+	TransactionStatusExpired: {
+		TransactionStatusRefundPending,
+	},
+	TransactionStatusCancelled: {
+		TransactionStatusRefundPending,
+	},
+	TransactionStatusPaid: {
+		TransactionStatusUsed,
+		TransactionStatusRefundPending,
+		TransactionStatusRefunded,
+	},
+	TransactionStatusRefundPending: {
+		TransactionStatusRefunded,
+	},
+}
+
+// CanTransitionTo reports whether a transaction in status s may move to next.
+func (s TransactionStatus) CanTransitionTo(next TransactionStatus) bool {
+	for _, allowed := range transactionTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid reports whether s is one of the known statuses.
+func (s TransactionStatus) Valid() bool {
+	switch s {
+	case TransactionStatusPending, TransactionStatusPaid, TransactionStatusUsed, TransactionStatusExpired,
+		TransactionStatusCancelled, TransactionStatusRefundPending, TransactionStatusRefunded:
+		return true
+	}
+	return false
+}
+
+const ActorSystem = "system"
+
+// ActorUser identifies a change made by a customer or staff member.
+func ActorUser(id uuid.UUID) string {
+	return "user:" + id.String()
+}
+
+// ActorProvider identifies a change triggered by a payment provider webhook.
+func ActorProvider(code string) string {
+	return "provider:" + code
+}
+
+// StatusChange describes who moved a transaction to a new status and why.
+type StatusChange struct {
+	Actor  string
+	Reason string
+}
+
+type TransactionStatusHistory struct {
+	ID            uuid.UUID          `json:"id"`
+	TransactionID uuid.UUID          `json:"transaction_id"`
+	FromStatus    *TransactionStatus `json:"from_status,omitempty"`
+	ToStatus      TransactionStatus  `json:"to_status"`
+	Actor         string             `json:"actor"`
+	Reason        string             `json:"reason"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lifecycle of a waitlist entry: waiting in line, offered a priority hold,
+// then fulfilled by a booking, lapsed or cancelled.
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusOffered   = "offered"
+	WaitlistStatusFulfilled = "fulfilled"
+	WaitlistStatusLapsed    = "lapsed"
+	WaitlistStatusCancelled = "cancelled"
+)
+
+type WaitlistEntry struct {
+	ID             uuid.UUID  `json:"id"`
+	ShowtimeID     uuid.UUID  `json:"showtime_id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	SeatCount      int        `json:"seat_count"`
+	Status         string     `json:"status"`
+	OfferedAt      *time.Time `json:"offered_at,omitempty"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	// Position is how many entries are ahead in line, counting from 1. Only
+	// set while waiting.
+	Position int `json:"position,omitempty"`
+
+	Showtime *Showtime  `json:"showtime,omitempty"`
+	Holds    []SeatHold `json:"holds,omitempty"`
+}
+
+// SeatHold keeps a seat for one user until it expires or is released, e.g.
+// because the user booked it.
+type SeatHold struct {
+	ID              uuid.UUID  `json:"id"`
+	ShowtimeID      uuid.UUID  `json:"showtime_id"`
+	SeatID          uuid.UUID  `json:"seat_id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	WaitlistEntryID uuid.UUID  `json:"waitlist_entry_id"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	ReleasedAt      *time.Time `json:"released_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+
+	Seat *Seat `json:"seat,omitempty"`
+}
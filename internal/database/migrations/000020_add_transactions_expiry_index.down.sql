@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_transactions_pending_expired_at;
\ No newline at end of file
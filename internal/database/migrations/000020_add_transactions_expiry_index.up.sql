@@ -0,0 +1 @@
+CREATE INDEX idx_transactions_pending_expired_at ON transactions (expired_at) WHERE status = 'pending';
\ No newline at end of file
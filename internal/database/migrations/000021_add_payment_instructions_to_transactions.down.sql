@@ -0,0 +1 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS payment_instructions;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE transactions ADD COLUMN payment_instructions JSONB;
\ No newline at end of file
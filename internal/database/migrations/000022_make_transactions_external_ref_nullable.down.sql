@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transactions_external_ref_not_empty;
+UPDATE transactions SET external_ref = 'unset-' || id WHERE external_ref IS NULL;
+ALTER TABLE transactions ALTER COLUMN external_ref SET NOT NULL;
\ No newline at end of file
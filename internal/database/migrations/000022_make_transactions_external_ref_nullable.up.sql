@@ -0,0 +1,8 @@
+ALTER TABLE transactions ALTER COLUMN external_ref DROP NOT NULL;
+
+-- Bookings created before providers were wired up all share an empty reference.
+UPDATE transactions SET external_ref = NULL WHERE external_ref = '';
+
+-- The UNIQUE constraint ignores NULLs, so unset references no longer collide;
+-- forbid empty strings so they cannot sneak back in.
+ALTER TABLE transactions ADD CONSTRAINT chk_transactions_external_ref_not_empty CHECK (external_ref <> '');
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_seat_pricing_overrides_lookup;
+DROP INDEX IF EXISTS idx_seat_pricings_theater_day_type;
+
+ALTER TABLE seat_pricing_overrides
+    DROP COLUMN IF EXISTS ends_at,
+    DROP COLUMN IF EXISTS starts_at,
+    DROP COLUMN IF EXISTS active;
\ No newline at end of file
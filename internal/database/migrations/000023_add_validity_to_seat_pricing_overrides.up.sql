@@ -0,0 +1,7 @@
+ALTER TABLE seat_pricing_overrides
+    ADD COLUMN active BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN starts_at TIMESTAMPTZ,
+    ADD COLUMN ends_at TIMESTAMPTZ;
+
+CREATE INDEX idx_seat_pricings_theater_day_type ON seat_pricings (theater_id, day_type);
+CREATE INDEX idx_seat_pricing_overrides_lookup ON seat_pricing_overrides (theater_id, movie_id) WHERE active;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS refunded_at,
+    DROP COLUMN IF EXISTS refund_ref,
+    DROP COLUMN IF EXISTS refund_amount,
+    DROP COLUMN IF EXISTS cancelled_at;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    ADD COLUMN cancelled_at TIMESTAMPTZ,
+    ADD COLUMN refund_amount BIGINT,
+    ADD COLUMN refund_ref VARCHAR(255),
+    ADD COLUMN refunded_at TIMESTAMPTZ;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transactions_status;
+
+DROP TABLE IF EXISTS transaction_status_history;
\ No newline at end of file
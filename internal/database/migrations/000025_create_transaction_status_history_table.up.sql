@@ -0,0 +1,21 @@
+CREATE TABLE transaction_status_history (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL,
+    from_status VARCHAR(255),
+    to_status VARCHAR(255) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    CONSTRAINT fk_status_history_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_transaction_status_history_transaction ON transaction_status_history (transaction_id, created_at);
+
+INSERT INTO transaction_status_history (transaction_id, from_status, to_status, actor, reason)
+SELECT id, NULL, status, 'system', 'backfilled from existing status'
+FROM transactions;
+
+ALTER TABLE transactions ADD CONSTRAINT chk_transactions_status
+    CHECK (status IN ('pending', 'paid', 'used', 'expired', 'cancelled', 'refund_pending', 'refunded'));
\ No newline at end of file
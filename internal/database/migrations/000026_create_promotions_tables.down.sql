@@ -0,0 +1,7 @@
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS fk_transactions_promotion,
+    DROP COLUMN IF EXISTS promotion_id,
+    DROP COLUMN IF EXISTS discount_amount;
+
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotions;
\ No newline at end of file
@@ -0,0 +1,54 @@
+CREATE TABLE promotions (
+    id UUID NOT NULL UNIQUE,
+    code VARCHAR(64) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    discount_type VARCHAR(16) NOT NULL,
+    discount_value BIGINT NOT NULL,
+    max_discount BIGINT,
+    min_spend BIGINT NOT NULL DEFAULT 0,
+    usage_limit INT,
+    per_user_limit INT,
+    active BOOLEAN NOT NULL DEFAULT true,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    movie_id UUID,
+    theater_id UUID,
+    seat_type_id UUID,
+    payment_method_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    CONSTRAINT chk_promotions_discount_type CHECK (discount_type IN ('percent', 'fixed')),
+    CONSTRAINT chk_promotions_discount_value CHECK (discount_value > 0 AND (discount_type <> 'percent' OR discount_value <= 100)),
+    CONSTRAINT fk_promotions_movie FOREIGN KEY (movie_id) REFERENCES movies(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_promotions_theater FOREIGN KEY (theater_id) REFERENCES theaters(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_promotions_seat_type FOREIGN KEY (seat_type_id) REFERENCES seat_type(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_promotions_payment_method FOREIGN KEY (payment_method_id) REFERENCES payment_methods(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE TABLE promotion_redemptions (
+    id UUID NOT NULL UNIQUE,
+    promotion_id UUID NOT NULL,
+    transaction_id UUID NOT NULL UNIQUE,
+    user_id UUID NOT NULL,
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    CONSTRAINT fk_redemptions_promotion FOREIGN KEY (promotion_id) REFERENCES promotions(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_redemptions_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_redemptions_user FOREIGN KEY (user_id) REFERENCES users(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_promotion_redemptions_promotion_user ON promotion_redemptions (promotion_id, user_id);
+
+ALTER TABLE transactions
+    ADD COLUMN discount_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN promotion_id UUID,
+    ADD CONSTRAINT fk_transactions_promotion FOREIGN KEY (promotion_id) REFERENCES promotions(id)
+        ON UPDATE CASCADE ON DELETE SET NULL;
\ No newline at end of file
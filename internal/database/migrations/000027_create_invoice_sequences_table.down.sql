@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS uq_transactions_invoice_number;
+
+DROP TABLE IF EXISTS invoice_sequences;
+
+ALTER TABLE theaters
+    DROP CONSTRAINT IF EXISTS uq_theaters_code,
+    DROP COLUMN IF EXISTS code;
\ No newline at end of file
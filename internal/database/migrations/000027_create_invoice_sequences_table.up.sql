@@ -0,0 +1,20 @@
+ALTER TABLE theaters ADD COLUMN code VARCHAR(16);
+
+UPDATE theaters t SET code = 'TH' || LPAD(n.rn::TEXT, 3, '0')
+FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY name, id) AS rn FROM theaters) n
+WHERE t.id = n.id;
+
+ALTER TABLE theaters
+    ALTER COLUMN code SET NOT NULL,
+    ADD CONSTRAINT uq_theaters_code UNIQUE (code);
+
+CREATE TABLE invoice_sequences (
+    theater_id UUID NOT NULL,
+    period CHAR(6) NOT NULL,
+    last_value BIGINT NOT NULL,
+    PRIMARY KEY(theater_id, period),
+    CONSTRAINT fk_invoice_sequences_theater FOREIGN KEY (theater_id) REFERENCES theaters(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX uq_transactions_invoice_number ON transactions (invoice_number) WHERE invoice_number IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS fk_transactions_checked_in_by,
+    DROP COLUMN IF EXISTS checked_in_by,
+    DROP COLUMN IF EXISTS checked_in_at;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    ADD COLUMN checked_in_at TIMESTAMPTZ,
+    ADD COLUMN checked_in_by UUID,
+    ADD CONSTRAINT fk_transactions_checked_in_by FOREIGN KEY (checked_in_by) REFERENCES users(id)
+        ON UPDATE CASCADE ON DELETE SET NULL;
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS seat_holds;
+
+DROP TABLE IF EXISTS booking_amendments;
+
+DROP INDEX IF EXISTS idx_transaction_items_active;
+
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS replaced_at;
\ No newline at end of file
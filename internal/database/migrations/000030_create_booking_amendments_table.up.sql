@@ -0,0 +1,59 @@
+ALTER TABLE transaction_items ADD COLUMN replaced_at TIMESTAMPTZ;
+
+CREATE INDEX idx_transaction_items_active ON transaction_items (transaction_id) WHERE replaced_at IS NULL;
+
+CREATE TABLE booking_amendments (
+    id UUID NOT NULL UNIQUE,
+    transaction_id UUID NOT NULL,
+    from_showtime_id UUID NOT NULL,
+    to_showtime_id UUID NOT NULL,
+    previous_amount BIGINT NOT NULL,
+    new_amount BIGINT NOT NULL,
+    difference BIGINT NOT NULL,
+    settlement VARCHAR(16) NOT NULL,
+    settlement_ref VARCHAR(255),
+    payment_instructions JSONB,
+    changes JSONB,
+    settled_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    expired_at TIMESTAMPTZ,
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    CONSTRAINT chk_booking_amendments_settlement CHECK (settlement IN ('none', 'charge', 'credit')),
+    CONSTRAINT fk_booking_amendments_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_booking_amendments_from_showtime FOREIGN KEY (from_showtime_id) REFERENCES showtimes(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_booking_amendments_to_showtime FOREIGN KEY (to_showtime_id) REFERENCES showtimes(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_booking_amendments_transaction ON booking_amendments (transaction_id, created_at);
+CREATE UNIQUE INDEX uq_booking_amendments_settlement_ref ON booking_amendments (settlement_ref) WHERE settlement_ref IS NOT NULL;
+CREATE INDEX idx_booking_amendments_outstanding ON booking_amendments (expires_at) WHERE settled_at IS NULL AND expired_at IS NULL;
+
+-- A change that costs extra only moves the booking once its difference is
+-- paid. Until then the new seats are held for the booking.
+CREATE TABLE seat_holds (
+    id UUID NOT NULL UNIQUE,
+    showtime_id UUID NOT NULL,
+    seat_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    amendment_id UUID NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    released_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    CONSTRAINT fk_seat_holds_showtime FOREIGN KEY (showtime_id) REFERENCES showtimes(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_seat_holds_seat FOREIGN KEY (seat_id) REFERENCES seats(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_seat_holds_user FOREIGN KEY (user_id) REFERENCES users(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_seat_holds_amendment FOREIGN KEY (amendment_id) REFERENCES booking_amendments(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_seat_holds_active ON seat_holds (showtime_id, seat_id) WHERE released_at IS NULL;
+CREATE INDEX idx_seat_holds_amendment ON seat_holds (amendment_id);
\ No newline at end of file
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_seat_holds_entry;
+
+DELETE FROM seat_holds WHERE waitlist_entry_id IS NOT NULL;
+
+ALTER TABLE seat_holds
+    DROP CONSTRAINT IF EXISTS chk_seat_holds_owner,
+    DROP CONSTRAINT IF EXISTS fk_seat_holds_waitlist_entry,
+    DROP COLUMN IF EXISTS waitlist_entry_id,
+    ALTER COLUMN amendment_id SET NOT NULL;
+
+DROP TABLE IF EXISTS waitlist_entries;
\ No newline at end of file
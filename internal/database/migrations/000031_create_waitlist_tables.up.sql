@@ -0,0 +1,32 @@
+CREATE TABLE waitlist_entries (
+    id UUID NOT NULL UNIQUE,
+    showtime_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    seat_count INT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'waiting',
+    offered_at TIMESTAMPTZ,
+    offer_expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    CONSTRAINT chk_waitlist_entries_seat_count CHECK (seat_count > 0),
+    CONSTRAINT chk_waitlist_entries_status CHECK (status IN ('waiting', 'offered', 'fulfilled', 'lapsed', 'cancelled')),
+    CONSTRAINT fk_waitlist_entries_showtime FOREIGN KEY (showtime_id) REFERENCES showtimes(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_waitlist_entries_user FOREIGN KEY (user_id) REFERENCES users(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- A user waits at most once per showtime.
+CREATE UNIQUE INDEX uq_waitlist_entries_open ON waitlist_entries (showtime_id, user_id) WHERE status IN ('waiting', 'offered');
+CREATE INDEX idx_waitlist_entries_queue ON waitlist_entries (showtime_id, created_at) WHERE status = 'waiting';
+
+-- Seats offered to a waitlisted user are held for them like those of an
+-- unpaid booking change; each hold belongs to exactly one of the two.
+ALTER TABLE seat_holds
+    ALTER COLUMN amendment_id DROP NOT NULL,
+    ADD COLUMN waitlist_entry_id UUID,
+    ADD CONSTRAINT fk_seat_holds_waitlist_entry FOREIGN KEY (waitlist_entry_id) REFERENCES waitlist_entries(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    ADD CONSTRAINT chk_seat_holds_owner CHECK ((waitlist_entry_id IS NULL) <> (amendment_id IS NULL));
+
+CREATE INDEX idx_seat_holds_entry ON seat_holds (waitlist_entry_id);
\ No newline at end of file
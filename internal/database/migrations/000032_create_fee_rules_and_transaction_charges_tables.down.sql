@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS transaction_charges;
+
+DROP TABLE IF EXISTS fee_rules;
\ No newline at end of file
@@ -0,0 +1,38 @@
+CREATE TABLE fee_rules (
+    id UUID NOT NULL UNIQUE,
+    theater_id UUID NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    basis VARCHAR(16) NOT NULL,
+    value BIGINT NOT NULL,
+    payment_method_type_id UUID,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    CONSTRAINT chk_fee_rules_kind CHECK (kind IN ('convenience_fee', 'payment_fee', 'tax')),
+    CONSTRAINT chk_fee_rules_basis CHECK (basis IN ('per_ticket', 'per_order', 'percent')),
+    CONSTRAINT chk_fee_rules_value CHECK (value >= 0),
+    CONSTRAINT chk_fee_rules_tax_percent CHECK (kind <> 'tax' OR basis = 'percent'),
+    CONSTRAINT fk_fee_rules_theater FOREIGN KEY (theater_id) REFERENCES theaters(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_fee_rules_payment_method_type FOREIGN KEY (payment_method_type_id) REFERENCES payment_method_types(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_fee_rules_theater ON fee_rules (theater_id) WHERE active;
+
+CREATE TABLE transaction_charges (
+    id UUID NOT NULL UNIQUE,
+    transaction_id UUID NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL,
+    fee_rule_id UUID,
+    PRIMARY KEY(id),
+    CONSTRAINT fk_transaction_charges_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_transaction_charges_fee_rule FOREIGN KEY (fee_rule_id) REFERENCES fee_rules(id)
+        ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+CREATE INDEX idx_transaction_charges_transaction ON transaction_charges (transaction_id);
\ No newline at end of file
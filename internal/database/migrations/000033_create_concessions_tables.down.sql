@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS uq_transactions_pickup_code;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS pickup_code;
+
+DROP TABLE IF EXISTS transaction_addons;
+
+DROP TABLE IF EXISTS concession_variants;
+
+DROP TABLE IF EXISTS concession_items;
\ No newline at end of file
@@ -0,0 +1,56 @@
+CREATE TABLE concession_items (
+    id UUID NOT NULL UNIQUE,
+    theater_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    category VARCHAR(64) NOT NULL,
+    image_url VARCHAR(255),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    CONSTRAINT fk_concession_items_theater FOREIGN KEY (theater_id) REFERENCES theaters(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_concession_items_theater ON concession_items (theater_id) WHERE active;
+
+CREATE TABLE concession_variants (
+    id UUID NOT NULL UNIQUE,
+    item_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    price BIGINT NOT NULL,
+    stock INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    CONSTRAINT chk_concession_variants_price CHECK (price >= 0),
+    CONSTRAINT fk_concession_variants_item FOREIGN KEY (item_id) REFERENCES concession_items(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_concession_variants_item ON concession_variants (item_id);
+
+-- Stock is only taken once the order is paid (stock_deducted_at) and given
+-- back when a paid order is cancelled (stock_restored_at).
+CREATE TABLE transaction_addons (
+    id UUID NOT NULL UNIQUE,
+    transaction_id UUID NOT NULL,
+    variant_id UUID NOT NULL,
+    quantity INT NOT NULL,
+    unit_price BIGINT NOT NULL,
+    stock_deducted_at TIMESTAMPTZ,
+    stock_restored_at TIMESTAMPTZ,
+    PRIMARY KEY(id),
+    CONSTRAINT chk_transaction_addons_quantity CHECK (quantity > 0),
+    CONSTRAINT fk_transaction_addons_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_transaction_addons_variant FOREIGN KEY (variant_id) REFERENCES concession_variants(id)
+        ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_transaction_addons_transaction ON transaction_addons (transaction_id);
+CREATE INDEX idx_transaction_addons_variant ON transaction_addons (variant_id) WHERE stock_deducted_at IS NULL;
+
+ALTER TABLE transactions ADD COLUMN pickup_code VARCHAR(16);
+
+CREATE UNIQUE INDEX uq_transactions_pickup_code ON transactions (pickup_code) WHERE pickup_code IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS ticket_transfers;
+
+ALTER TABLE transaction_items
+    DROP CONSTRAINT IF EXISTS fk_transaction_items_holder,
+    DROP COLUMN IF EXISTS checked_in_by,
+    DROP COLUMN IF EXISTS checked_in_at,
+    DROP COLUMN IF EXISTS holder_id;
\ No newline at end of file
@@ -0,0 +1,42 @@
+-- holder_id is who the seat's ticket belongs to when it is not the booking
+-- owner. Check-in is tracked per seat, as seats of one booking may be
+-- admitted separately once some are transferred.
+ALTER TABLE transaction_items
+    ADD COLUMN holder_id UUID,
+    ADD COLUMN checked_in_at TIMESTAMPTZ,
+    ADD COLUMN checked_in_by UUID,
+    ADD CONSTRAINT fk_transaction_items_holder FOREIGN KEY (holder_id) REFERENCES users(id)
+        ON UPDATE CASCADE ON DELETE SET NULL;
+
+UPDATE transaction_items ti
+SET checked_in_at = t.checked_in_at, checked_in_by = t.checked_in_by
+FROM transactions t
+WHERE ti.transaction_id = t.id AND t.checked_in_at IS NOT NULL;
+
+CREATE TABLE ticket_transfers (
+    id UUID NOT NULL UNIQUE,
+    transaction_id UUID NOT NULL,
+    transaction_item_id UUID NOT NULL,
+    from_user_id UUID NOT NULL,
+    to_user_id UUID NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    responded_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    CONSTRAINT chk_ticket_transfers_status CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled')),
+    CONSTRAINT chk_ticket_transfers_users CHECK (from_user_id <> to_user_id),
+    CONSTRAINT fk_ticket_transfers_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_ticket_transfers_item FOREIGN KEY (transaction_item_id) REFERENCES transaction_items(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_ticket_transfers_from_user FOREIGN KEY (from_user_id) REFERENCES users(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_ticket_transfers_to_user FOREIGN KEY (to_user_id) REFERENCES users(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- A seat is offered to one person at a time.
+CREATE UNIQUE INDEX uq_ticket_transfers_pending ON ticket_transfers (transaction_item_id) WHERE status = 'pending';
+CREATE INDEX idx_ticket_transfers_transaction ON ticket_transfers (transaction_id, created_at);
+CREATE INDEX idx_ticket_transfers_from_user ON ticket_transfers (from_user_id, created_at);
+CREATE INDEX idx_ticket_transfers_to_user ON ticket_transfers (to_user_id, created_at);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_transactions_user_status;
\ No newline at end of file
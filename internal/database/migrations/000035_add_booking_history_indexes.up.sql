@@ -0,0 +1 @@
+CREATE INDEX idx_transactions_user_status ON transactions (user_id, status) INCLUDE (showtime_id);
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- A calendar feed lets calendar apps subscribe to a user's bookings without
+-- logging in. Only the SHA-256 of the token in the feed URL is stored; a user
+-- has at most one active feed, and revoking it kills the URL.
+CREATE TABLE calendar_feeds (
+    id UUID NOT NULL UNIQUE,
+    user_id UUID NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ,
+    PRIMARY KEY(id),
+    CONSTRAINT fk_calendar_feeds_user FOREIGN KEY (user_id) REFERENCES users(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX uq_calendar_feeds_active_user ON calendar_feeds (user_id) WHERE revoked_at IS NULL;
\ No newline at end of file
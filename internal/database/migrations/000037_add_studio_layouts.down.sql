@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS studio_gaps;
+
+DROP INDEX IF EXISTS uq_seats_active_cell;
+
+ALTER TABLE seats
+    DROP CONSTRAINT IF EXISTS chk_seats_grid,
+    DROP CONSTRAINT IF EXISTS fk_seats_paired_with,
+    DROP CONSTRAINT IF EXISTS chk_seats_paired_with,
+    DROP COLUMN IF EXISTS paired_with_id,
+    DROP COLUMN IF EXISTS companion,
+    DROP COLUMN IF EXISTS wheelchair,
+    DROP COLUMN IF EXISTS grid_column,
+    DROP COLUMN IF EXISTS grid_row;
+
+ALTER TABLE studios
+    DROP CONSTRAINT IF EXISTS chk_studios_screen_position,
+    DROP COLUMN IF EXISTS screen_position;
\ No newline at end of file
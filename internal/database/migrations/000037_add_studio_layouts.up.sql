@@ -0,0 +1,50 @@
+-- Studios get a seat map: every seat sits in a grid cell (1-based, row 1
+-- nearest the screen when it is at the top), and cells without a seat can be
+-- marked as aisles or stairs. Wheelchair spaces and their companion seats are
+-- flagged, and couple seats point at their partner.
+ALTER TABLE studios
+    ADD COLUMN screen_position VARCHAR(16) NOT NULL DEFAULT 'top',
+    ADD CONSTRAINT chk_studios_screen_position CHECK (screen_position IN ('top', 'bottom'));
+
+ALTER TABLE seats
+    ADD COLUMN grid_row INTEGER,
+    ADD COLUMN grid_column INTEGER,
+    ADD COLUMN wheelchair BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN companion BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN paired_with_id UUID,
+    ADD CONSTRAINT chk_seats_paired_with CHECK (paired_with_id <> id),
+    ADD CONSTRAINT fk_seats_paired_with FOREIGN KEY (paired_with_id) REFERENCES seats(id)
+        ON UPDATE CASCADE ON DELETE SET NULL;
+
+-- Existing studios get one seat per cell: rows in label order (A..Z, then
+-- AA, AB, ...), columns by seat number.
+UPDATE seats s
+SET grid_row = r.grid_row, grid_column = s.number
+FROM (
+    SELECT id, DENSE_RANK() OVER (PARTITION BY studio_id ORDER BY LENGTH(row), row) AS grid_row
+    FROM seats
+) r
+WHERE s.id = r.id;
+
+ALTER TABLE seats
+    ALTER COLUMN grid_row SET NOT NULL,
+    ALTER COLUMN grid_column SET NOT NULL,
+    ADD CONSTRAINT chk_seats_grid CHECK (grid_row > 0 AND grid_column > 0);
+
+-- Seats taken out of sale keep their cell, so a new seat can replace them.
+CREATE UNIQUE INDEX uq_seats_active_cell ON seats (studio_id, grid_row, grid_column) WHERE active;
+
+CREATE TABLE studio_gaps (
+    id UUID NOT NULL UNIQUE,
+    studio_id UUID NOT NULL,
+    grid_row INTEGER NOT NULL,
+    grid_column INTEGER NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    PRIMARY KEY(id),
+    CONSTRAINT chk_studio_gaps_kind CHECK (kind IN ('aisle', 'stairs')),
+    CONSTRAINT chk_studio_gaps_grid CHECK (grid_row > 0 AND grid_column > 0),
+    CONSTRAINT fk_studio_gaps_studio FOREIGN KEY (studio_id) REFERENCES studios(id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX uq_studio_gaps_cell ON studio_gaps (studio_id, grid_row, grid_column);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS seat_blocks;
\ No newline at end of file
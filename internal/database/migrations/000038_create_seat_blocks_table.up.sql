@@ -0,0 +1,31 @@
+-- A seat block takes a seat off sale for one showtime, or for every showtime
+-- of its studio that starts in [starts_at, ends_at), e.g. for a broken chair
+-- or house seats. Staff release a block to put the seat back on sale.
+CREATE TABLE seat_blocks (
+    id UUID NOT NULL UNIQUE,
+    seat_id UUID NOT NULL,
+    showtime_id UUID,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    reason TEXT NOT NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    released_by UUID,
+    released_at TIMESTAMPTZ,
+    PRIMARY KEY(id),
+    CONSTRAINT chk_seat_blocks_scope CHECK (
+        (showtime_id IS NOT NULL AND starts_at IS NULL AND ends_at IS NULL)
+        OR (showtime_id IS NULL AND starts_at IS NOT NULL AND ends_at > starts_at)
+    ),
+    CONSTRAINT fk_seat_blocks_seat FOREIGN KEY (seat_id) REFERENCES seats(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_seat_blocks_showtime FOREIGN KEY (showtime_id) REFERENCES showtimes(id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_seat_blocks_created_by FOREIGN KEY (created_by) REFERENCES users(id)
+        ON UPDATE CASCADE ON DELETE RESTRICT,
+    CONSTRAINT fk_seat_blocks_released_by FOREIGN KEY (released_by) REFERENCES users(id)
+        ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_seat_blocks_showtime ON seat_blocks (showtime_id) WHERE released_at IS NULL AND showtime_id IS NOT NULL;
+CREATE INDEX idx_seat_blocks_range ON seat_blocks (seat_id, starts_at, ends_at) WHERE released_at IS NULL AND showtime_id IS NULL;
\ No newline at end of file
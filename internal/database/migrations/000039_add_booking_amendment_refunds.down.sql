@@ -0,0 +1,4 @@
+ALTER TABLE booking_amendments
+    DROP COLUMN IF EXISTS refunded_at,
+    DROP COLUMN IF EXISTS refund_ref,
+    DROP COLUMN IF EXISTS refund_amount;
\ No newline at end of file
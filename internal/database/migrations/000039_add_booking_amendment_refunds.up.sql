@@ -0,0 +1,7 @@
+-- A charged booking change whose payment can no longer be applied, because it
+-- came in after the change lapsed or the booking or seats moved on meanwhile,
+-- is given up and its payment refunded.
+ALTER TABLE booking_amendments
+    ADD COLUMN refund_amount BIGINT,
+    ADD COLUMN refund_ref VARCHAR(255),
+    ADD COLUMN refunded_at TIMESTAMPTZ;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE booking_amendments DROP COLUMN IF EXISTS settlement_pending_at;
\ No newline at end of file
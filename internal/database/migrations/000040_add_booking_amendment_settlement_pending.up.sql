@@ -0,0 +1,3 @@
+-- A lapsed change whose charge could not be cancelled because it was already
+-- paid waits for the payment webhook instead of being expired.
+ALTER TABLE booking_amendments ADD COLUMN settlement_pending_at TIMESTAMPTZ;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_transactions_showtime_status;
\ No newline at end of file
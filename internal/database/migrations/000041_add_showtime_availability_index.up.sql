@@ -0,0 +1 @@
+CREATE INDEX idx_transactions_showtime_status ON transactions (showtime_id, status);
\ No newline at end of file
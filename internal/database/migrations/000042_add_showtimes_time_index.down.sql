@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_showtimes_time;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- Booking history (FindByUserID) reaches a user's transactions through
+-- idx_transactions_user_status and sorts that small set by showtime time, so
+-- no index serves its ORDER BY. This one serves plans that start from the
+-- showtimes instead: the From/To and upcoming filters when they are narrower
+-- than the user's history, and other listings bounded by showtime time.
+CREATE INDEX idx_showtimes_time ON showtimes (time);
\ No newline at end of file
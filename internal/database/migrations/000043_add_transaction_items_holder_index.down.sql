@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_transaction_items_holder;
\ No newline at end of file
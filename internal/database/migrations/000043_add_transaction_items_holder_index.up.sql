@@ -0,0 +1 @@
+CREATE INDEX idx_transaction_items_holder ON transaction_items (holder_id) WHERE holder_id IS NOT NULL;
\ No newline at end of file
@@ -45,8 +45,8 @@ func (r *Repository) GetTheaterByName(ctx context.Context, cinemaID uuid.UUID, n
 }
 
 func (r *Repository) CreateTheater(ctx context.Context, theater *entities.Theater) error {
-	query := `INSERT INTO theaters (id, cinema_id, name, address, latitude, longitude) VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.db.ExecContext(ctx, query, theater.ID, theater.CinemaID, theater.Name, theater.Address, theater.Latitude, theater.Longitude)
+	query := `INSERT INTO theaters (id, cinema_id, code, name, address, latitude, longitude) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.ExecContext(ctx, query, theater.ID, theater.CinemaID, theater.Code, theater.Name, theater.Address, theater.Latitude, theater.Longitude)
 	return err
 }
 
@@ -67,8 +67,14 @@ func (r *Repository) CreateStudio(ctx context.Context, studio *entities.Studio)
 }
 
 func (r *Repository) CreateSeat(ctx context.Context, seat *entities.Seat) error {
-	query := `INSERT INTO seats (id, studio_id, row, number, seat_type_id, active) VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.db.ExecContext(ctx, query, seat.ID, seat.StudioID, seat.Row, seat.Number, seat.SeatTypeID, seat.Active)
+	query := `
+		INSERT INTO seats (id, studio_id, row, number, seat_type_id, active, grid_row, grid_column, wheelchair, companion, paired_with_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		seat.ID, seat.StudioID, seat.Row, seat.Number, seat.SeatTypeID, seat.Active,
+		seat.GridRow, seat.GridColumn, seat.Wheelchair, seat.Companion, seat.PairedWithID,
+	)
 	return err
 }
 
@@ -63,12 +63,19 @@ func (s *Seeder) TruncateAll(ctx context.Context) error {
 
 	query := `
 		TRUNCATE TABLE 
+			promotion_redemptions,
+			promotions,
+			transaction_addons,
+			transaction_charges,
 			transaction_items,
 			transactions,
 			showtimes,
 			seats,
 			seat_pricing_overrides,
 			seat_pricings,
+			fee_rules,
+			concession_variants,
+			concession_items,
 			studios,
 			theaters,
 			cinemas,
@@ -116,13 +123,24 @@ func (s *Seeder) SeedReference(ctx context.Context) error {
 	s.db.ExecContext(ctx, `INSERT INTO payment_method_types (id, name) VALUES ($1, $2) ON CONFLICT DO NOTHING`, vaTypeID, "Virtual Account")
 
 	// Payment Methods
+	gopayID := uuid.New()
 	s.db.ExecContext(ctx, `INSERT INTO payment_methods (id, code, name, logo_url, active, payment_method_type_id) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING`,
-		uuid.New(), "GOPAY", "GoPay", "https://example.com/gopay.png", true, ewalletTypeID)
+		gopayID, "GOPAY", "GoPay", "https://example.com/gopay.png", true, ewalletTypeID)
 	s.db.ExecContext(ctx, `INSERT INTO payment_methods (id, code, name, logo_url, active, payment_method_type_id) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING`,
 		uuid.New(), "OVO", "OVO", "https://example.com/ovo.png", true, ewalletTypeID)
 	s.db.ExecContext(ctx, `INSERT INTO payment_methods (id, code, name, logo_url, active, payment_method_type_id) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING`,
 		uuid.New(), "BCA_VA", "BCA Virtual Account", "https://example.com/bca.png", true, vaTypeID)
 
+	// Promotions
+	promoQuery := `
+		INSERT INTO promotions (id, code, description, discount_type, discount_value, max_discount, min_spend, usage_limit, per_user_limit, payment_method_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT DO NOTHING
+	`
+	s.db.ExecContext(ctx, promoQuery,
+		uuid.New(), "FILMIX10", "10% off, up to 25K", entities.DiscountTypePercent, 10, 25000, 50000, 1000, 1, nil)
+	s.db.ExecContext(ctx, promoQuery,
+		uuid.New(), "GOPAY15K", "15K off when paying with GoPay", entities.DiscountTypeFixed, 15000, nil, 75000, nil, 3, gopayID)
+
 	return nil
 }
 
@@ -134,14 +152,18 @@ func (s *Seeder) SeedUsers(ctx context.Context) error {
 	query := `INSERT INTO users (id, name, email, password, role_id) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`
 
 	roleAdminID := uuid.New()
+	roleStaffID := uuid.New()
 	roleUserID := uuid.New()
 
 	roleQuery := `INSERT INTO roles (id, name) VALUES ($1, $2) ON CONFLICT DO NOTHING`
 	s.db.ExecContext(ctx, roleQuery, roleAdminID, "admin")
+	s.db.ExecContext(ctx, roleQuery, roleStaffID, "staff")
 	s.db.ExecContext(ctx, roleQuery, roleUserID, "user")
 
 	// Admin
 	s.db.ExecContext(ctx, query, uuid.New(), "Admin User", "admin@filmix.com", string(hash), roleAdminID)
+	// Staff
+	s.db.ExecContext(ctx, query, uuid.New(), "Staff User", "staff@filmix.com", string(hash), roleStaffID)
 	// User
 	s.db.ExecContext(ctx, query, uuid.New(), "Normal User", "user@filmix.com", string(hash), roleUserID)
 
@@ -198,6 +220,7 @@ func (s *Seeder) SeedCinemas(ctx context.Context) error {
 		errCreate := s.repo.CreateTheater(ctx, &entities.Theater{
 			ID:        theaterID,
 			CinemaID:  cinema.ID,
+			Code:      "TH001",
 			Name:      theaterName,
 			Address:   "Jl. Letjen S. Parman No.28",
 			Latitude:  -6.175392,
@@ -233,6 +256,8 @@ func (s *Seeder) SeedCinemas(ctx context.Context) error {
 					StudioID:   studio.ID,
 					Row:        rowChar,
 					Number:     num,
+					GridRow:    row + 1,
+					GridColumn: num,
 					SeatTypeID: typeID,
 					Active:     true,
 				})
@@ -266,6 +291,70 @@ func (s *Seeder) SeedCinemas(ctx context.Context) error {
 			SeatTypeID: stdType.ID,
 			TheaterID:  theater.ID,
 		})
+
+		s.repo.CreateSeatPricing(ctx, &entities.SeatPricing{
+			ID:         uuid.New(),
+			Price:      100000,
+			DayType:    "weekday",
+			SeatTypeID: vipType.ID,
+			TheaterID:  theater.ID,
+		})
+
+		s.repo.CreateSeatPricing(ctx, &entities.SeatPricing{
+			ID:         uuid.New(),
+			Price:      125000,
+			DayType:    "weekend",
+			SeatTypeID: vipType.ID,
+			TheaterID:  theater.ID,
+		})
+	}
+
+	// Fees & Taxes
+	var feeCount int
+	s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM fee_rules WHERE theater_id = $1", theater.ID).Scan(&feeCount)
+
+	if feeCount == 0 {
+		log.Println("Creating fee rules...")
+		var ewalletTypeID, vaTypeID uuid.UUID
+		s.db.QueryRowContext(ctx, "SELECT id FROM payment_method_types WHERE name = $1", "E-Wallet").Scan(&ewalletTypeID)
+		s.db.QueryRowContext(ctx, "SELECT id FROM payment_method_types WHERE name = $1", "Virtual Account").Scan(&vaTypeID)
+
+		feeQuery := `
+			INSERT INTO fee_rules (id, theater_id, kind, name, basis, value, payment_method_type_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		s.db.ExecContext(ctx, feeQuery,
+			uuid.New(), theater.ID, entities.ChargeKindConvenienceFee, "Convenience Fee", entities.FeeBasisPerTicket, 3000, nil)
+		s.db.ExecContext(ctx, feeQuery,
+			uuid.New(), theater.ID, entities.ChargeKindPaymentFee, "Virtual Account Fee", entities.FeeBasisPerOrder, 4000, vaTypeID)
+		s.db.ExecContext(ctx, feeQuery,
+			uuid.New(), theater.ID, entities.ChargeKindPaymentFee, "E-Wallet Fee", entities.FeeBasisPercent, 150, ewalletTypeID)
+		s.db.ExecContext(ctx, feeQuery,
+			uuid.New(), theater.ID, entities.ChargeKindTax, "VAT 11%", entities.FeeBasisPercent, 1100, nil)
+	}
+
+	// Concessions
+	var concessionCount int
+	s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM concession_items WHERE theater_id = $1", theater.ID).Scan(&concessionCount)
+
+	if concessionCount == 0 {
+		log.Println("Creating concessions...")
+		itemQuery := `INSERT INTO concession_items (id, theater_id, name, description, category) VALUES ($1, $2, $3, $4, $5)`
+		variantQuery := `INSERT INTO concession_variants (id, item_id, name, price, stock) VALUES ($1, $2, $3, $4, $5)`
+
+		popcornID := uuid.New()
+		s.db.ExecContext(ctx, itemQuery, popcornID, theater.ID, "Popcorn", "Freshly popped", "snack")
+		s.db.ExecContext(ctx, variantQuery, uuid.New(), popcornID, "Salted, Regular", 35000, 200)
+		s.db.ExecContext(ctx, variantQuery, uuid.New(), popcornID, "Caramel, Large", 55000, 100)
+
+		drinkID := uuid.New()
+		s.db.ExecContext(ctx, itemQuery, drinkID, theater.ID, "Soft Drink", nil, "drink")
+		s.db.ExecContext(ctx, variantQuery, uuid.New(), drinkID, "Regular", 25000, 300)
+		s.db.ExecContext(ctx, variantQuery, uuid.New(), drinkID, "Large", 32000, 300)
+
+		comboID := uuid.New()
+		s.db.ExecContext(ctx, itemQuery, comboID, theater.ID, "Couple Combo", "Large popcorn and two regular drinks", "combo")
+		s.db.ExecContext(ctx, variantQuery, uuid.New(), comboID, "Salted", 95000, 50)
 	}
 
 	return nil
@@ -374,7 +463,7 @@ func (s *Seeder) SeedShowtimes(ctx context.Context) error {
 
 	// Seed 3 showtimes per movie for today
 	var pricingID uuid.UUID
-	err = s.db.QueryRowContext(ctx, "SELECT id FROM seat_pricings LIMIT 1").Scan(&pricingID)
+	err = s.db.QueryRowContext(ctx, "SELECT id FROM seat_pricings ORDER BY price LIMIT 1").Scan(&pricingID)
 	if err != nil {
 		return fmt.Errorf("no seat pricing found, seeding failed")
 	}
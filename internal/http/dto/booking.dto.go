@@ -7,21 +7,112 @@ import (
 )
 
 type CreateBookingRequest struct {
-	ShowtimeID      uuid.UUID   `json:"showtime_id" validate:"required"`
-	SeatIDs         []uuid.UUID `json:"seat_ids" validate:"required,min=1"`
-	PaymentMethodID uuid.UUID   `json:"payment_method_id" validate:"required"`
+	ShowtimeID      uuid.UUID      `json:"showtime_id" validate:"required"`
+	SeatIDs         []uuid.UUID    `json:"seat_ids" validate:"required,min=1"`
+	PaymentMethodID uuid.UUID      `json:"payment_method_id" validate:"required"`
+	PromoCode       string         `json:"promo_code,omitempty"`
+	Addons          []AddonRequest `json:"addons,omitempty" validate:"omitempty,dive"`
+}
+
+// AddonRequest pre-orders a concession variant with the tickets.
+type AddonRequest struct {
+	VariantID uuid.UUID `json:"variant_id" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"required,min=1"`
+}
+
+// AmendBookingRequest replaces the seats of a booking, optionally on another
+// showtime of the same movie.
+type AmendBookingRequest struct {
+	ShowtimeID *uuid.UUID  `json:"showtime_id,omitempty"`
+	SeatIDs    []uuid.UUID `json:"seat_ids" validate:"required,min=1"`
 }
 
 type BookingResponse struct {
-	ID            uuid.UUID         `json:"id"`
-	Status        string            `json:"status"`
-	InvoiceNumber *string           `json:"invoice_number,omitempty"`
-	Amount        int64             `json:"amount"`
-	ExpiredAt     time.Time         `json:"expired_at"`
-	PaidAt        *time.Time        `json:"paid_at,omitempty"`
-	Showtime      BookingShowtime   `json:"showtime"`
-	Theater       BookingTheater    `json:"theater"`
-	Seats         []BookingSeatItem `json:"seats,omitempty"`
+	ID            uuid.UUID          `json:"id"`
+	Status        string             `json:"status"`
+	InvoiceNumber *string            `json:"invoice_number,omitempty"`
+	Subtotal      int64              `json:"subtotal"`
+	Discount      *BookingDiscount   `json:"discount,omitempty"`
+	Charges       []BookingCharge    `json:"charges,omitempty"`
+	Amount        int64              `json:"amount"`
+	ExpiredAt     time.Time          `json:"expired_at"`
+	PaidAt        *time.Time         `json:"paid_at,omitempty"`
+	CancelledAt   *time.Time         `json:"cancelled_at,omitempty"`
+	Refund        *BookingRefund     `json:"refund,omitempty"`
+	Showtime      BookingShowtime    `json:"showtime"`
+	Theater       BookingTheater     `json:"theater"`
+	Seats         []BookingSeatItem  `json:"seats,omitempty"`
+	Addons        []BookingAddonItem `json:"addons,omitempty"`
+	PickupCode    *string            `json:"pickup_code,omitempty"`
+	Payment       *BookingPayment    `json:"payment,omitempty"`
+	Amendments    []BookingChange    `json:"amendments,omitempty"`
+	Timeline      []BookingStatus    `json:"timeline,omitempty"`
+}
+
+// BookingChange is an amendment of a booking. A positive difference is charged
+// to the customer, a negative one refunded; Payment tells how to pay a charge.
+// A charged change only takes effect once paid, and lapses at ExpiresAt; a
+// payment that comes too late is refunded (RefundAmount).
+type BookingChange struct {
+	ID             uuid.UUID             `json:"id"`
+	FromShowtimeID uuid.UUID             `json:"from_showtime_id"`
+	ToShowtimeID   uuid.UUID             `json:"to_showtime_id"`
+	PreviousAmount int64                 `json:"previous_amount"`
+	NewAmount      int64                 `json:"new_amount"`
+	Difference     int64                 `json:"difference"`
+	Settlement     string                `json:"settlement"`
+	Reference      *string               `json:"reference,omitempty"`
+	Payment        *BookingChangePayment `json:"payment,omitempty"`
+	SettledAt      *time.Time            `json:"settled_at,omitempty"`
+	ExpiresAt      *time.Time            `json:"expires_at,omitempty"`
+	ExpiredAt      *time.Time            `json:"expired_at,omitempty"`
+	RefundAmount   *int64                `json:"refund_amount,omitempty"`
+	RefundedAt     *time.Time            `json:"refunded_at,omitempty"`
+	At             time.Time             `json:"at"`
+}
+
+type BookingChangePayment struct {
+	Kind      string    `json:"kind"`
+	VANumber  string    `json:"va_number,omitempty"`
+	Deeplink  string    `json:"deeplink,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type BookingStatus struct {
+	From   *string   `json:"from,omitempty"`
+	To     string    `json:"to"`
+	Actor  string    `json:"actor"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+type BookingPayment struct {
+	MethodCode string    `json:"method_code"`
+	MethodName string    `json:"method_name"`
+	Reference  string    `json:"reference,omitempty"`
+	Kind       string    `json:"kind,omitempty"`
+	VANumber   string    `json:"va_number,omitempty"`
+	Deeplink   string    `json:"deeplink,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// BookingCharge is a fee or tax line on a booking. The booking amount is the
+// subtotal less the discount plus all charges.
+type BookingCharge struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Amount int64  `json:"amount"`
+}
+
+type BookingDiscount struct {
+	PromoCode string `json:"promo_code,omitempty"`
+	Amount    int64  `json:"amount"`
+}
+
+type BookingRefund struct {
+	Amount     int64      `json:"amount"`
+	Reference  *string    `json:"reference,omitempty"`
+	RefundedAt *time.Time `json:"refunded_at,omitempty"`
 }
 
 type BookingShowtime struct {
@@ -41,6 +132,18 @@ type BookingSeatItem struct {
 	Number   int       `json:"number"`
 	SeatType string    `json:"seat_type"`
 	Price    int64     `json:"price"`
+	// Holder is who the seat's ticket was transferred to, if anyone.
+	Holder      *TransferUser `json:"holder,omitempty"`
+	CheckedInAt *time.Time    `json:"checked_in_at,omitempty"`
+}
+
+type BookingAddonItem struct {
+	VariantID uuid.UUID `json:"variant_id"`
+	Item      string    `json:"item"`
+	Variant   string    `json:"variant"`
+	Quantity  int       `json:"quantity"`
+	UnitPrice int64     `json:"unit_price"`
+	Total     int64     `json:"total"`
 }
 
 type BookingListResponse struct {
@@ -0,0 +1,7 @@
+package dto
+
+// CalendarFeedResponse is a freshly issued calendar subscription. The URL
+// embeds the feed token and is only shown once.
+type CalendarFeedResponse struct {
+	URL string `json:"url"`
+}
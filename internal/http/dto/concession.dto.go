@@ -0,0 +1,19 @@
+package dto
+
+import "github.com/google/uuid"
+
+type ConcessionItemResponse struct {
+	ID          uuid.UUID                   `json:"id"`
+	Name        string                      `json:"name"`
+	Description *string                     `json:"description,omitempty"`
+	Category    string                      `json:"category"`
+	ImageURL    *string                     `json:"image_url,omitempty"`
+	Variants    []ConcessionVariantResponse `json:"variants"`
+}
+
+type ConcessionVariantResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Price     int64     `json:"price"`
+	Available int       `json:"available"`
+}
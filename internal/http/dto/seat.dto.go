@@ -1,16 +1,82 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type SeatResponse struct {
-	ID       uuid.UUID        `json:"id"`
-	Row      string           `json:"row"`
-	Number   int              `json:"number"`
-	SeatType SeatTypeResponse `json:"seat_type"`
-	IsBooked bool             `json:"is_booked"`
+	ID           uuid.UUID        `json:"id"`
+	Row          string           `json:"row"`
+	Number       int              `json:"number"`
+	GridRow      int              `json:"grid_row"`
+	GridColumn   int              `json:"grid_column"`
+	SeatType     SeatTypeResponse `json:"seat_type"`
+	Wheelchair   bool             `json:"wheelchair"`
+	Companion    bool             `json:"companion"`
+	PairedWithID *uuid.UUID       `json:"paired_with_id,omitempty"`
+	IsBooked     bool             `json:"is_booked"`
+	Price        int64            `json:"price"`
+}
+
+// SeatMapResponse is a showtime's studio as a grid of rows x columns cells,
+// listed from the top row. Screen says which edge of the grid faces the
+// screen.
+type SeatMapResponse struct {
+	StudioID uuid.UUID    `json:"studio_id"`
+	Studio   string       `json:"studio"`
+	Screen   string       `json:"screen"`
+	Rows     int          `json:"rows"`
+	Columns  int          `json:"columns"`
+	Grid     []SeatMapRow `json:"grid"`
+}
+
+// SeatMapRow is one grid row. Label is the row letter of its seats, if any.
+type SeatMapRow struct {
+	Row   int           `json:"row"`
+	Label string        `json:"label,omitempty"`
+	Cells []SeatMapCell `json:"cells"`
+}
+
+// SeatMapCell is a seat, an aisle, stairs or an empty cell.
+type SeatMapCell struct {
+	Column int           `json:"column"`
+	Kind   string        `json:"kind"`
+	Seat   *SeatResponse `json:"seat,omitempty"`
+}
+
+// SeatSuggestionResponse is the best free seats for a group. Contiguous is
+// false when the group had to be split over several blocks.
+type SeatSuggestionResponse struct {
+	Contiguous bool                `json:"contiguous"`
+	TotalPrice int64               `json:"total_price"`
+	Blocks     []SeatBlockResponse `json:"blocks"`
+}
+
+type SeatBlockResponse struct {
+	Row   string         `json:"row"`
+	Seats []SeatResponse `json:"seats"`
 }
 
 type SeatTypeResponse struct {
 	ID   uuid.UUID `json:"id"`
 	Name string    `json:"name"`
 }
+
+// SeatSnapshotEvent opens a seat stream with the seats taken at that moment;
+// every other seat is free.
+type SeatSnapshotEvent struct {
+	Held    []uuid.UUID `json:"held"`
+	Booked  []uuid.UUID `json:"booked"`
+	Blocked []uuid.UUID `json:"blocked"`
+}
+
+// SeatChangeEvent tells a seat stream that seats were held, booked, blocked
+// or released. Held seats come free at ExpiresAt unless booked first.
+type SeatChangeEvent struct {
+	State     string      `json:"state"`
+	SeatIDs   []uuid.UUID `json:"seat_ids"`
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"`
+	At        time.Time   `json:"at"`
+}
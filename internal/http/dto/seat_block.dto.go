@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BlockSeatsRequest takes seats off sale for showtime_id, or for every
+// showtime of their studio starting from starts_at until ends_at.
+type BlockSeatsRequest struct {
+	SeatIDs    []uuid.UUID `json:"seat_ids" validate:"required,min=1"`
+	ShowtimeID *uuid.UUID  `json:"showtime_id,omitempty"`
+	StartsAt   *time.Time  `json:"starts_at,omitempty"`
+	EndsAt     *time.Time  `json:"ends_at,omitempty"`
+	Reason     string      `json:"reason" validate:"required,max=255"`
+}
+
+type BlockedSeatResponse struct {
+	ID         uuid.UUID   `json:"id"`
+	Seat       BlockedSeat `json:"seat"`
+	ShowtimeID *uuid.UUID  `json:"showtime_id,omitempty"`
+	StartsAt   *time.Time  `json:"starts_at,omitempty"`
+	EndsAt     *time.Time  `json:"ends_at,omitempty"`
+	Reason     string      `json:"reason"`
+	CreatedBy  uuid.UUID   `json:"created_by"`
+	CreatedAt  time.Time   `json:"created_at"`
+	ReleasedBy *uuid.UUID  `json:"released_by,omitempty"`
+	ReleasedAt *time.Time  `json:"released_at,omitempty"`
+}
+
+type BlockedSeat struct {
+	ID       uuid.UUID `json:"id"`
+	Row      string    `json:"row"`
+	Number   int       `json:"number"`
+	StudioID uuid.UUID `json:"studio_id"`
+}
@@ -14,6 +14,26 @@ type ShowtimeResponse struct {
 	Theater   TheaterResponse `json:"theater"`
 	Price     int64           `json:"price"`
 	Movie     *MovieBrief     `json:"movie,omitempty"`
+	Seats     *SeatCounts     `json:"seats,omitempty"`
+}
+
+type SeatCounts struct {
+	Total     int                 `json:"total"`
+	Available int                 `json:"available"`
+	Held      int                 `json:"held"`
+	Booked    int                 `json:"booked"`
+	Blocked   int                 `json:"blocked"`
+	SeatTypes []SeatTypeSeatCount `json:"seat_types,omitempty"`
+}
+
+type SeatTypeSeatCount struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Total     int       `json:"total"`
+	Available int       `json:"available"`
+	Held      int       `json:"held"`
+	Booked    int       `json:"booked"`
+	Blocked   int       `json:"blocked"`
 }
 
 type StudioResponse struct {
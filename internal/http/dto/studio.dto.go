@@ -0,0 +1,13 @@
+package dto
+
+// LayoutChangesResponse lists, by seat label, what importing a layout changes
+// or changed. Applied is false for a dry run.
+type LayoutChangesResponse struct {
+	Added       []string `json:"added"`
+	Reactivated []string `json:"reactivated"`
+	Deactivated []string `json:"deactivated"`
+	Retyped     []string `json:"retyped"`
+	Updated     []string `json:"updated"`
+	Gaps        int      `json:"gaps"`
+	Applied     bool     `json:"applied"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CheckInRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type CheckInResponse struct {
+	BookingID   uuid.UUID          `json:"booking_id"`
+	Status      string             `json:"status"`
+	CheckedInAt *time.Time         `json:"checked_in_at,omitempty"`
+	Showtime    BookingShowtime    `json:"showtime"`
+	Theater     BookingTheater     `json:"theater"`
+	Seats       []BookingSeatItem  `json:"seats"`
+	Addons      []BookingAddonItem `json:"addons,omitempty"`
+	PickupCode  *string            `json:"pickup_code,omitempty"`
+}
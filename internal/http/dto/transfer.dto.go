@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateTransferRequest struct {
+	SeatID uuid.UUID `json:"seat_id" validate:"required"`
+	Email  string    `json:"email" validate:"required,email"`
+}
+
+// TransferResponse is a ticket transfer of one seat. Accepted transfers of a
+// booking, oldest first, show who held each seat.
+type TransferResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	BookingID   uuid.UUID       `json:"booking_id"`
+	Status      string          `json:"status"`
+	Seat        TransferSeat    `json:"seat"`
+	Showtime    BookingShowtime `json:"showtime"`
+	From        TransferUser    `json:"from"`
+	To          TransferUser    `json:"to"`
+	RespondedAt *time.Time      `json:"responded_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type TransferSeat struct {
+	ID     uuid.UUID `json:"id"`
+	Row    string    `json:"row"`
+	Number int       `json:"number"`
+}
+
+type TransferUser struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+}
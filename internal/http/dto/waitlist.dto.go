@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JoinWaitlistRequest struct {
+	SeatCount int `json:"seat_count" validate:"required,min=1"`
+}
+
+type WaitlistEntryResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	Status    string          `json:"status"`
+	SeatCount int             `json:"seat_count"`
+	Position  int             `json:"position,omitempty"`
+	Showtime  BookingShowtime `json:"showtime"`
+	Hold      *WaitlistHold   `json:"hold,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// WaitlistHold lists the seats held for a waitlisted user. They book them
+// with the regular booking endpoint before ExpiresAt.
+type WaitlistHold struct {
+	ExpiresAt time.Time          `json:"expires_at"`
+	Seats     []WaitlistHoldSeat `json:"seats"`
+}
+
+type WaitlistHoldSeat struct {
+	ID     uuid.UUID `json:"id"`
+	Row    string    `json:"row"`
+	Number int       `json:"number"`
+}
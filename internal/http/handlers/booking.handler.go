@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
@@ -41,16 +44,47 @@ func (h *BookingHandler) CreateBooking(c *fiber.Ctx) error {
 		ShowtimeID:      req.ShowtimeID,
 		SeatIDs:         req.SeatIDs,
 		PaymentMethodID: req.PaymentMethodID,
+		PromoCode:       req.PromoCode,
+	}
+	for _, addon := range req.Addons {
+		input.Addons = append(input.Addons, services.AddonInput{
+			VariantID: addon.VariantID,
+			Quantity:  addon.Quantity,
+		})
 	}
 
 	booking, err := h.bookingService.CreateBooking(c.Context(), input)
 	if err != nil {
+		if ruleErr := bookingRuleError(err); ruleErr != nil {
+			return ruleErr
+		}
 		if errors.Is(err, services.ErrSeatsNotAvailable) {
 			return fiber.NewError(fiber.StatusConflict, "One or more seats are already booked")
 		}
 		if errors.Is(err, services.ErrShowtimeNotFound) {
 			return fiber.NewError(fiber.StatusNotFound, "Showtime not found")
 		}
+		if errors.Is(err, services.ErrPaymentMethodNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid payment method")
+		}
+		if errors.Is(err, services.ErrPromoCodeNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid promo code")
+		}
+		if errors.Is(err, services.ErrPromoCodeNotApplicable) {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "Promo code does not apply to this booking")
+		}
+		if errors.Is(err, services.ErrPromoMinSpendNotMet) {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "Booking does not reach the promo minimum spend")
+		}
+		if errors.Is(err, services.ErrPromoUsageLimitReached) {
+			return fiber.NewError(fiber.StatusConflict, "Promo code usage limit reached")
+		}
+		if errors.Is(err, services.ErrInvalidAddon) {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid concession add-on")
+		}
+		if errors.Is(err, services.ErrAddonOutOfStock) {
+			return fiber.NewError(fiber.StatusConflict, "One or more concessions are out of stock")
+		}
 		// Log actual error for debugging
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
@@ -80,25 +114,176 @@ func (h *BookingHandler) GetBooking(c *fiber.Ctx) error {
 	}
 
 	response := h.mapBookingToResponse(booking)
+
+	// Support asks for ?include=timeline to see how the order got where it is.
+	if c.Query("include") == "timeline" {
+		history, err := h.bookingService.GetBookingTimeline(c.Context(), id, userID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get booking timeline")
+		}
+		for _, entry := range history {
+			status := dto.BookingStatus{
+				To:     string(entry.ToStatus),
+				Actor:  entry.Actor,
+				Reason: entry.Reason,
+				At:     entry.CreatedAt,
+			}
+			if entry.FromStatus != nil {
+				from := string(*entry.FromStatus)
+				status.From = &from
+			}
+			response.Timeline = append(response.Timeline, status)
+		}
+	}
+
 	return utilities.NewSuccessResponse(c, http.StatusOK, "Booking retrieved successfully", response)
 }
 
+func (h *BookingHandler) CancelBooking(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid booking ID")
+	}
+
+	booking, err := h.bookingService.CancelBooking(c.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrBookingNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Booking not found")
+		}
+		if errors.Is(err, services.ErrBookingNotCancellable) {
+			return fiber.NewError(fiber.StatusConflict, "Booking can no longer be cancelled")
+		}
+		if errors.Is(err, services.ErrRefundWindowClosed) {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "Refund window for this booking has closed")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to cancel booking")
+	}
+
+	response := h.mapBookingToResponse(booking)
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Booking cancelled successfully", response)
+}
+
+func (h *BookingHandler) AmendBooking(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid booking ID")
+	}
+
+	var req dto.AmendBookingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if len(req.SeatIDs) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "At least one seat is required")
+	}
+
+	booking, err := h.bookingService.AmendBooking(c.Context(), services.AmendBookingInput{
+		BookingID:  id,
+		UserID:     userID,
+		ShowtimeID: req.ShowtimeID,
+		SeatIDs:    req.SeatIDs,
+	})
+	if err != nil {
+		if ruleErr := bookingRuleError(err); ruleErr != nil {
+			return ruleErr
+		}
+		if errors.Is(err, services.ErrBookingNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Booking not found")
+		}
+		if errors.Is(err, services.ErrShowtimeNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Showtime not found")
+		}
+		if errors.Is(err, services.ErrSeatsNotAvailable) {
+			return fiber.NewError(fiber.StatusConflict, "One or more seats are already booked")
+		}
+		if errors.Is(err, services.ErrBookingNotAmendable) {
+			return fiber.NewError(fiber.StatusConflict, "Booking can no longer be changed")
+		}
+		if errors.Is(err, services.ErrAmendmentWindowClosed) {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "Change window for this booking has closed")
+		}
+		if errors.Is(err, services.ErrInvalidAmendment) {
+			return fiber.NewError(fiber.StatusBadRequest, "Booking can only move to other seats or another showtime of the same movie at the same theater")
+		}
+		if errors.Is(err, services.ErrPromoCodeNotFound) || errors.Is(err, services.ErrPromoCodeNotApplicable) {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "The booking's promo code does not apply to this change")
+		}
+		if errors.Is(err, services.ErrPromoMinSpendNotMet) {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "The new seats do not reach the promo minimum spend")
+		}
+		if errors.Is(err, services.ErrPaymentMethodNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid payment method")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to change booking")
+	}
+
+	response := h.mapBookingToResponse(booking)
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Booking changed successfully", response)
+}
+
 func (h *BookingHandler) GetUserBookings(c *fiber.Ctx) error {
 	userID, err := h.getUserID(c)
 	if err != nil {
 		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
 	}
 
-	bookings, err := h.bookingService.GetUserBookings(c.Context(), userID)
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	filter := services.BookingHistoryFilter{
+		When:  c.Query("when"),
+		Page:  page,
+		Limit: limit,
+	}
+	if status := c.Query("status"); status != "" {
+		filter.Statuses = strings.Split(status, ",")
+	}
+	if filter.From, err = parseDateQuery(c, "from"); err != nil {
+		return err
+	}
+	if filter.To, err = parseDateQuery(c, "to"); err != nil {
+		return err
+	}
+	switch c.Query("order") {
+	case "":
+	case "asc", "desc":
+		ascending := c.Query("order") == "asc"
+		filter.Ascending = &ascending
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order, expected asc or desc")
+	}
+
+	bookings, total, err := h.bookingService.GetUserBookings(c.Context(), userID, filter)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidBookingFilter) {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid booking filter")
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get bookings")
 	}
 
-	var response []dto.BookingListResponse
+	response := make([]dto.BookingListResponse, 0, len(bookings))
 	for _, b := range bookings {
 		resp := dto.BookingListResponse{
 			ID:        b.ID,
-			Status:    b.Status,
+			Status:    string(b.Status),
 			Amount:    b.Amount,
 			ExpiredAt: b.ExpiredAt,
 			PaidAt:    b.PaidAt,
@@ -125,7 +310,61 @@ func (h *BookingHandler) GetUserBookings(c *fiber.Ctx) error {
 		response = append(response, resp)
 	}
 
-	return utilities.NewSuccessResponse(c, http.StatusOK, "Bookings retrieved successfully", response)
+	return utilities.NewPaginatedResponse(c, http.StatusOK, "Bookings retrieved successfully", response, page, limit, total)
+}
+
+// parseDateQuery reads an optional YYYY-MM-DD query parameter.
+func parseDateQuery(c *fiber.Ctx, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Invalid %s date, expected YYYY-MM-DD", name))
+	}
+	return &parsed, nil
+}
+
+// bookingRuleError maps invalid seat selections and exceeded purchase limits
+// to 422 responses, 429 for the booking velocity limit. It returns nil for any
+// other error.
+func bookingRuleError(err error) error {
+	var selection *services.SeatSelectionError
+	if errors.As(err, &selection) {
+		ids := make([]string, len(selection.SeatIDs))
+		for i, id := range selection.SeatIDs {
+			ids[i] = id.String()
+		}
+
+		msg := "Invalid seats"
+		switch {
+		case errors.Is(err, services.ErrDuplicateSeats):
+			msg = "Seats requested more than once"
+		case errors.Is(err, services.ErrSeatNotInStudio):
+			msg = "Seats are not in this showtime's studio"
+		case errors.Is(err, services.ErrSeatInactive):
+			msg = "Seats are not for sale"
+		}
+		return fiber.NewError(fiber.StatusUnprocessableEntity, fmt.Sprintf("%s: %s", msg, strings.Join(ids, ", ")))
+	}
+
+	var limit *services.PurchaseLimitError
+	if errors.As(err, &limit) {
+		switch limit.Limit {
+		case services.LimitSeatsPerBooking:
+			return fiber.NewError(fiber.StatusUnprocessableEntity, fmt.Sprintf("A booking can have at most %d seats", limit.Max))
+		case services.LimitSeatsPerShowtime:
+			return fiber.NewError(fiber.StatusUnprocessableEntity, fmt.Sprintf("You can book at most %d seats for this showtime", limit.Max))
+		case services.LimitPendingBookings:
+			return fiber.NewError(fiber.StatusUnprocessableEntity, fmt.Sprintf("You can have at most %d unpaid bookings at a time", limit.Max))
+		case services.LimitBookingVelocity:
+			return fiber.NewError(fiber.StatusTooManyRequests, "Too many bookings, please try again later")
+		}
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "Purchase limit exceeded")
+	}
+
+	return nil
 }
 
 func (h *BookingHandler) getUserID(c *fiber.Ctx) (uuid.UUID, error) {
@@ -138,14 +377,50 @@ func (h *BookingHandler) getUserID(c *fiber.Ctx) (uuid.UUID, error) {
 	return uuid.Parse(userIDStr)
 }
 
+func mapAddonsToResponse(addons []entities.TransactionAddon) []dto.BookingAddonItem {
+	var items []dto.BookingAddonItem
+	for _, a := range addons {
+		item := dto.BookingAddonItem{
+			VariantID: a.VariantID,
+			Quantity:  a.Quantity,
+			UnitPrice: a.UnitPrice,
+			Total:     a.UnitPrice * int64(a.Quantity),
+		}
+		if a.Variant != nil {
+			item.Variant = a.Variant.Name
+			if a.Variant.Item != nil {
+				item.Item = a.Variant.Item.Name
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
 func (h *BookingHandler) mapBookingToResponse(b *entities.Transaction) dto.BookingResponse {
 	resp := dto.BookingResponse{
 		ID:            b.ID,
-		Status:        b.Status,
+		Status:        string(b.Status),
 		InvoiceNumber: b.InvoiceNumber,
 		Amount:        b.Amount,
 		ExpiredAt:     b.ExpiredAt,
 		PaidAt:        b.PaidAt,
+		CancelledAt:   b.CancelledAt,
+	}
+
+	if b.DiscountAmount > 0 {
+		resp.Discount = &dto.BookingDiscount{Amount: b.DiscountAmount}
+		if b.Promotion != nil {
+			resp.Discount.PromoCode = b.Promotion.Code
+		}
+	}
+
+	if b.RefundAmount != nil {
+		resp.Refund = &dto.BookingRefund{
+			Amount:     *b.RefundAmount,
+			Reference:  b.RefundRef,
+			RefundedAt: b.RefundedAt,
+		}
 	}
 
 	if b.Showtime != nil {
@@ -171,8 +446,12 @@ func (h *BookingHandler) mapBookingToResponse(b *entities.Transaction) dto.Booki
 
 	for _, item := range b.Items {
 		seatItem := dto.BookingSeatItem{
-			ID:    item.SeatID,
-			Price: item.Price,
+			ID:          item.SeatID,
+			Price:       item.Price,
+			CheckedInAt: item.CheckedInAt,
+		}
+		if item.Holder != nil {
+			seatItem.Holder = &dto.TransferUser{ID: item.Holder.ID, Name: item.Holder.Name, Email: item.Holder.Email}
 		}
 		if item.Seat != nil {
 			seatItem.Row = item.Seat.Row
@@ -182,6 +461,69 @@ func (h *BookingHandler) mapBookingToResponse(b *entities.Transaction) dto.Booki
 			}
 		}
 		resp.Seats = append(resp.Seats, seatItem)
+		resp.Subtotal += item.Price
+	}
+
+	resp.Addons = mapAddonsToResponse(b.Addons)
+	for _, addon := range resp.Addons {
+		resp.Subtotal += addon.Total
+	}
+	// The code is only good for collecting while the booking stands.
+	if b.Status == entities.TransactionStatusPaid || b.Status == entities.TransactionStatusUsed {
+		resp.PickupCode = b.PickupCode
+	}
+
+	for _, c := range b.Charges {
+		resp.Charges = append(resp.Charges, dto.BookingCharge{
+			Kind:   c.Kind,
+			Name:   c.Name,
+			Amount: c.Amount,
+		})
+	}
+
+	if b.PaymentMethod != nil {
+		resp.Payment = &dto.BookingPayment{
+			MethodCode: b.PaymentMethod.Code,
+			MethodName: b.PaymentMethod.Name,
+			ExpiresAt:  b.ExpiredAt,
+		}
+		if b.ExternalRef != nil {
+			resp.Payment.Reference = *b.ExternalRef
+		}
+		if b.PaymentInstructions != nil {
+			resp.Payment.Kind = b.PaymentInstructions.Kind
+			resp.Payment.VANumber = b.PaymentInstructions.VANumber
+			resp.Payment.Deeplink = b.PaymentInstructions.Deeplink
+			resp.Payment.ExpiresAt = b.PaymentInstructions.ExpiresAt
+		}
+	}
+
+	for _, a := range b.Amendments {
+		change := dto.BookingChange{
+			ID:             a.ID,
+			FromShowtimeID: a.FromShowtimeID,
+			ToShowtimeID:   a.ToShowtimeID,
+			PreviousAmount: a.PreviousAmount,
+			NewAmount:      a.NewAmount,
+			Difference:     a.Difference,
+			Settlement:     a.Settlement,
+			Reference:      a.SettlementRef,
+			SettledAt:      a.SettledAt,
+			ExpiresAt:      a.ExpiresAt,
+			ExpiredAt:      a.ExpiredAt,
+			RefundAmount:   a.RefundAmount,
+			RefundedAt:     a.RefundedAt,
+			At:             a.CreatedAt,
+		}
+		if a.PaymentInstructions != nil && a.SettledAt == nil && a.ExpiredAt == nil {
+			change.Payment = &dto.BookingChangePayment{
+				Kind:      a.PaymentInstructions.Kind,
+				VANumber:  a.PaymentInstructions.VANumber,
+				Deeplink:  a.PaymentInstructions.Deeplink,
+				ExpiresAt: a.PaymentInstructions.ExpiresAt,
+			}
+		}
+		resp.Amendments = append(resp.Amendments, change)
 	}
 
 	return resp
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/http/dto"
+	"github.com/senatroxx/filmix-backend/internal/services"
+	"github.com/senatroxx/filmix-backend/internal/utilities"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+type CalendarHandler struct {
+	calendarService services.ICalendarService
+}
+
+func NewCalendarHandler(calendarService services.ICalendarService) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+// GetBookingCalendar serves a booking as an .ics file to add to a calendar.
+func (h *CalendarHandler) GetBookingCalendar(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	bookingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid booking ID")
+	}
+
+	ics, err := h.calendarService.RenderBookingCalendar(c.Context(), bookingID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrBookingNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Booking not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get booking calendar")
+	}
+
+	c.Set(fiber.HeaderContentType, calendarContentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="booking-`+bookingID.String()+`.ics"`)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Send(ics)
+}
+
+// CreateFeed issues the user's calendar subscription URL. Any URL issued
+// before stops working.
+func (h *CalendarHandler) CreateFeed(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	token, err := h.calendarService.CreateFeed(c.Context(), userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to create calendar feed")
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusCreated, "Calendar feed created successfully", dto.CalendarFeedResponse{
+		URL: c.BaseURL() + "/api/v1/calendar/" + token + ".ics",
+	})
+}
+
+func (h *CalendarHandler) RevokeFeed(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	if err := h.calendarService.RevokeFeed(c.Context(), userID); err != nil {
+		if errors.Is(err, services.ErrCalendarFeedNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Calendar feed not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to revoke calendar feed")
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Calendar feed revoked successfully", nil)
+}
+
+// GetFeed serves a user's bookings to calendar apps. The token in the URL is
+// the only credential, as calendar apps can't log in.
+func (h *CalendarHandler) GetFeed(c *fiber.Ctx) error {
+	token := strings.TrimSpace(c.Params("token"))
+	if token == "" {
+		return fiber.NewError(fiber.StatusNotFound, "Calendar feed not found")
+	}
+
+	ics, err := h.calendarService.RenderFeed(c.Context(), token)
+	if err != nil {
+		if errors.Is(err, services.ErrCalendarFeedNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Calendar feed not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get calendar feed")
+	}
+
+	c.Set(fiber.HeaderContentType, calendarContentType)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Send(ics)
+}
+
+func (h *CalendarHandler) getUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	user := c.Locals("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userIDStr, ok := claims["user_id"].(string)
+	if !ok {
+		return uuid.Nil, errors.New("invalid user_id in token")
+	}
+	return uuid.Parse(userIDStr)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/http/dto"
+	"github.com/senatroxx/filmix-backend/internal/services"
+	"github.com/senatroxx/filmix-backend/internal/utilities"
+)
+
+type ConcessionHandler struct {
+	concessionService services.IConcessionService
+}
+
+func NewConcessionHandler(concessionService services.IConcessionService) *ConcessionHandler {
+	return &ConcessionHandler{concessionService: concessionService}
+}
+
+func (h *ConcessionHandler) GetCatalog(c *fiber.Ctx) error {
+	theaterID, err := uuid.Parse(c.Params("theaterId"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid theater ID")
+	}
+
+	items, err := h.concessionService.GetCatalog(c.Context(), theaterID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch concessions")
+	}
+
+	response := make([]dto.ConcessionItemResponse, 0, len(items))
+	for _, item := range items {
+		resp := dto.ConcessionItemResponse{
+			ID:          item.ID,
+			Name:        item.Name,
+			Description: item.Description,
+			Category:    item.Category,
+			ImageURL:    item.ImageURL,
+		}
+		for _, v := range item.Variants {
+			resp.Variants = append(resp.Variants, dto.ConcessionVariantResponse{
+				ID:        v.ID,
+				Name:      v.Name,
+				Price:     v.Price,
+				Available: v.Available,
+			})
+		}
+		response = append(response, resp)
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Concessions retrieved successfully", response)
+}
@@ -1,21 +1,46 @@
 package handlers
 
-import "github.com/senatroxx/filmix-backend/internal/services"
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/senatroxx/filmix-backend/internal/http/middleware"
+	"github.com/senatroxx/filmix-backend/internal/services"
+)
 
 type Handlers struct {
-	Auth     *AuthHandler
-	Movie    *MovieHandler
-	Showtime *ShowtimeHandler
-	Seat     *SeatHandler
-	Booking  *BookingHandler
+	Auth       *AuthHandler
+	Movie      *MovieHandler
+	Showtime   *ShowtimeHandler
+	Seat       *SeatHandler
+	Booking    *BookingHandler
+	Payment    *PaymentHandler
+	Ticket     *TicketHandler
+	Waitlist   *WaitlistHandler
+	Concession *ConcessionHandler
+	Transfer   *TransferHandler
+	Calendar   *CalendarHandler
+	SeatBlock  *SeatBlockHandler
+	Studio     *StudioHandler
+
+	// Idempotency deduplicates retried requests; see middleware.Idempotency.
+	Idempotency fiber.Handler
 }
 
 func RegisterHandlers(s *services.Services) *Handlers {
 	return &Handlers{
-		Auth:     NewAuthHandler(s.AuthService),
-		Movie:    NewMovieHandler(s.MovieService),
-		Showtime: NewShowtimeHandler(s.ShowtimeService),
-		Seat:     NewSeatHandler(s.SeatService),
-		Booking:  NewBookingHandler(s.BookingService),
+		Auth:       NewAuthHandler(s.AuthService),
+		Movie:      NewMovieHandler(s.MovieService),
+		Showtime:   NewShowtimeHandler(s.ShowtimeService),
+		Seat:       NewSeatHandler(s.SeatService),
+		Booking:    NewBookingHandler(s.BookingService),
+		Payment:    NewPaymentHandler(s.PaymentService),
+		Ticket:     NewTicketHandler(s.TicketService),
+		Waitlist:   NewWaitlistHandler(s.WaitlistService),
+		Concession: NewConcessionHandler(s.ConcessionService),
+		Transfer:   NewTransferHandler(s.TransferService),
+		Calendar:   NewCalendarHandler(s.CalendarService),
+		SeatBlock:  NewSeatBlockHandler(s.SeatBlockService),
+		Studio:     NewStudioHandler(s.StudioService),
+
+		Idempotency: middleware.Idempotency(s.IdempotencyService),
 	}
 }
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/services"
+	"github.com/senatroxx/filmix-backend/internal/utilities"
+)
+
+type PaymentHandler struct {
+	paymentService services.IPaymentService
+}
+
+func NewPaymentHandler(paymentService services.IPaymentService) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+// Webhook receives provider notifications. The raw body is signed by the
+// provider and the signature is sent in the X-Signature header.
+func (h *PaymentHandler) Webhook(c *fiber.Ctx) error {
+	provider := c.Params("provider")
+	signature := c.Get("X-Signature")
+	if signature == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "Missing signature")
+	}
+
+	err := h.paymentService.HandleWebhook(c.Context(), provider, c.Body(), signature)
+	if err != nil {
+		return h.mapPaymentError(err)
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Webhook processed successfully", nil)
+}
+
+func (h *PaymentHandler) SimulatePayment(c *fiber.Ctx) error {
+	user := c.Locals("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userIDStr, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	bookingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid booking ID")
+	}
+
+	if err := h.paymentService.SimulatePayment(c.Context(), bookingID, userID); err != nil {
+		return h.mapPaymentError(err)
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Payment simulated successfully", nil)
+}
+
+func (h *PaymentHandler) mapPaymentError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidWebhookSignature):
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid signature")
+	case errors.Is(err, services.ErrInvalidWebhookPayload):
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid payload")
+	case errors.Is(err, services.ErrPaymentMethodNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Unknown payment provider")
+	case errors.Is(err, services.ErrBookingNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Booking not found")
+	case errors.Is(err, services.ErrPaymentAmountMismatch):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "Payment amount does not match booking")
+	case errors.Is(err, services.ErrBookingNotPayable):
+		return fiber.NewError(fiber.StatusConflict, "Booking can no longer be paid")
+	case errors.Is(err, services.ErrNoRefundPending):
+		return fiber.NewError(fiber.StatusConflict, "Booking has no refund in progress")
+	case errors.Is(err, services.ErrPaymentSimulationUnavailable):
+		return fiber.NewError(fiber.StatusForbidden, "Payment simulation is not available")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, "Failed to process payment")
+}
@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -10,6 +16,15 @@ import (
 	"github.com/senatroxx/filmix-backend/internal/utilities"
 )
 
+const (
+	// seatStreamHeartbeat is how often an idle seat stream sends a comment,
+	// so proxies and clients don't give up on it.
+	seatStreamHeartbeat = 15 * time.Second
+	// seatStreamWriteTimeout bounds each write to a seat stream. It replaces
+	// the server's write timeout, which would otherwise end every stream.
+	seatStreamWriteTimeout = 10 * time.Second
+)
+
 type SeatHandler struct {
 	seatService services.ISeatService
 }
@@ -18,6 +33,8 @@ func NewSeatHandler(seatService services.ISeatService) *SeatHandler {
 	return &SeatHandler{seatService: seatService}
 }
 
+// GetSeatsForShowtime returns the showtime's seat map with the availability
+// and price of every seat.
 func (h *SeatHandler) GetSeatsForShowtime(c *fiber.Ctx) error {
 	showtimeIDParam := c.Params("showtimeId")
 	showtimeID, err := uuid.Parse(showtimeIDParam)
@@ -25,27 +42,183 @@ func (h *SeatHandler) GetSeatsForShowtime(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid showtime ID")
 	}
 
-	seats, err := h.seatService.GetSeatsForShowtime(c.Context(), showtimeID)
+	seatMap, err := h.seatService.GetSeatMap(c.Context(), showtimeID)
 	if err != nil {
-		return fiber.NewError(fiber.StatusNotFound, "Showtime not found")
+		if errors.Is(err, services.ErrShowtimeNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Showtime not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get seats")
 	}
 
-	var response []dto.SeatResponse
-	for _, seat := range seats {
-		resp := dto.SeatResponse{
-			ID:       seat.ID,
-			Row:      seat.Row,
-			Number:   seat.Number,
-			IsBooked: seat.IsBooked,
-		}
-		if seat.SeatType != nil {
-			resp.SeatType = dto.SeatTypeResponse{
-				ID:   seat.SeatType.ID,
-				Name: seat.SeatType.Name,
+	response := dto.SeatMapResponse{
+		StudioID: seatMap.Studio.ID,
+		Studio:   seatMap.Studio.Name,
+		Screen:   seatMap.Studio.ScreenPosition,
+		Rows:     seatMap.Rows,
+		Columns:  seatMap.Columns,
+		Grid:     make([]dto.SeatMapRow, 0, seatMap.Rows),
+	}
+	for r, cells := range seatMap.Cells {
+		row := dto.SeatMapRow{Row: r + 1, Cells: make([]dto.SeatMapCell, 0, len(cells))}
+		for c, cell := range cells {
+			resp := dto.SeatMapCell{Column: c + 1, Kind: cell.Kind}
+			if cell.Seat != nil {
+				seat := h.mapSeatToResponse(*cell.Seat)
+				resp.Seat = &seat
+				if row.Label == "" {
+					row.Label = seat.Row
+				}
 			}
+			row.Cells = append(row.Cells, resp)
 		}
-		response = append(response, resp)
+		response.Grid = append(response.Grid, row)
 	}
 
 	return utilities.NewSuccessResponse(c, http.StatusOK, "Seats retrieved successfully", response)
 }
+
+func (h *SeatHandler) SuggestSeats(c *fiber.Ctx) error {
+	showtimeID, err := uuid.Parse(c.Params("showtimeId"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid showtime ID")
+	}
+
+	suggestion, err := h.seatService.SuggestSeats(c.Context(), showtimeID, c.QueryInt("count", 0), c.Query("seat_type"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSeatCount) {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Count must be between 1 and %d", services.MaxSuggestedSeats))
+		}
+		if errors.Is(err, services.ErrShowtimeNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Showtime not found")
+		}
+		if errors.Is(err, services.ErrSeatTypeNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, "Unknown seat type")
+		}
+		if errors.Is(err, services.ErrNotEnoughSeats) {
+			return fiber.NewError(fiber.StatusConflict, "Not enough seats available")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to suggest seats")
+	}
+
+	response := dto.SeatSuggestionResponse{Contiguous: suggestion.Contiguous}
+	for _, block := range suggestion.Blocks {
+		resp := dto.SeatBlockResponse{Row: block[0].Row}
+		for _, seat := range block {
+			resp.Seats = append(resp.Seats, h.mapSeatToResponse(seat))
+			response.TotalPrice += seat.Price
+		}
+		response.Blocks = append(response.Blocks, resp)
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Seats suggested successfully", response)
+}
+
+// StreamSeats pushes the showtime's seat changes as server-sent events: a
+// snapshot event with the seats taken, then a seats event for every change.
+// The stream ends when the client falls behind or the server shuts down;
+// clients reconnect and start over from a new snapshot.
+func (h *SeatHandler) StreamSeats(c *fiber.Ctx) error {
+	showtimeID, err := uuid.Parse(c.Params("showtimeId"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid showtime ID")
+	}
+
+	watch, err := h.seatService.WatchSeats(c.Context(), showtimeID)
+	if err != nil {
+		if errors.Is(err, services.ErrShowtimeNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Showtime not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to watch seats")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// The writer runs after the handler returns, when c may no longer be used.
+	conn := c.Context().Conn()
+	snapshot := dto.SeatSnapshotEvent{
+		Held:    append([]uuid.UUID{}, watch.Held...),
+		Booked:  append([]uuid.UUID{}, watch.Booked...),
+		Blocked: append([]uuid.UUID{}, watch.Blocked...),
+	}
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer watch.Close()
+
+		if err := writeSeatStreamEvent(w, conn, "snapshot", snapshot); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(seatStreamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case event, ok := <-watch.Events:
+				if !ok {
+					return
+				}
+				err := writeSeatStreamEvent(w, conn, "seats", dto.SeatChangeEvent{
+					State:     event.State,
+					SeatIDs:   event.SeatIDs,
+					ExpiresAt: event.ExpiresAt,
+					At:        event.At,
+				})
+				if err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if err := writeSeatStream(w, conn, ": ping\n\n"); err != nil {
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+// writeSeatStreamEvent sends one server-sent event with a JSON payload.
+func writeSeatStreamEvent(w *bufio.Writer, conn net.Conn, name string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return writeSeatStream(w, conn, fmt.Sprintf("event: %s\ndata: %s\n\n", name, payload))
+}
+
+// writeSeatStream writes to a seat stream and flushes it, failing once the
+// client is gone.
+func writeSeatStream(w *bufio.Writer, conn net.Conn, s string) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(seatStreamWriteTimeout)); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(s); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func (h *SeatHandler) mapSeatToResponse(seat services.SeatWithAvailability) dto.SeatResponse {
+	resp := dto.SeatResponse{
+		ID:           seat.ID,
+		Row:          seat.Row,
+		Number:       seat.Number,
+		GridRow:      seat.GridRow,
+		GridColumn:   seat.GridColumn,
+		Wheelchair:   seat.Wheelchair,
+		Companion:    seat.Companion,
+		PairedWithID: seat.PairedWithID,
+		IsBooked:     seat.IsBooked,
+		Price:        seat.Price,
+	}
+	if seat.SeatType != nil {
+		resp.SeatType = dto.SeatTypeResponse{
+			ID:   seat.SeatType.ID,
+			Name: seat.SeatType.Name,
+		}
+	}
+	return resp
+}
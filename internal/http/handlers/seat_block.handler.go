@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/http/dto"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+	"github.com/senatroxx/filmix-backend/internal/services"
+	"github.com/senatroxx/filmix-backend/internal/utilities"
+)
+
+type SeatBlockHandler struct {
+	seatBlockService services.ISeatBlockService
+}
+
+func NewSeatBlockHandler(seatBlockService services.ISeatBlockService) *SeatBlockHandler {
+	return &SeatBlockHandler{seatBlockService: seatBlockService}
+}
+
+func (h *SeatBlockHandler) BlockSeats(c *fiber.Ctx) error {
+	staffID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	var req dto.BlockSeatsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if errMsg := utilities.ValidateStruct(req); errMsg != "" {
+		return fiber.NewError(fiber.StatusBadRequest, errMsg)
+	}
+
+	blocks, err := h.seatBlockService.BlockSeats(c.Context(), services.BlockSeatsInput{
+		SeatIDs:    req.SeatIDs,
+		ShowtimeID: req.ShowtimeID,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+		Reason:     req.Reason,
+		StaffID:    staffID,
+	})
+	if err != nil {
+		var selection *services.SeatSelectionError
+		if errors.As(err, &selection) {
+			ids := make([]string, len(selection.SeatIDs))
+			for i, id := range selection.SeatIDs {
+				ids[i] = id.String()
+			}
+
+			msg := "Invalid seats"
+			switch {
+			case errors.Is(err, services.ErrDuplicateSeats):
+				msg = "Seats requested more than once"
+			case errors.Is(err, services.ErrSeatNotFound):
+				msg = "Seats not found"
+			case errors.Is(err, services.ErrSeatNotInStudio):
+				msg = "Seats are not in this showtime's studio"
+			case errors.Is(err, services.ErrSeatsInDifferentStudios):
+				msg = "Seats are in different studios"
+			}
+			return fiber.NewError(fiber.StatusUnprocessableEntity, fmt.Sprintf("%s: %s", msg, strings.Join(ids, ", ")))
+		}
+
+		switch {
+		case errors.Is(err, services.ErrInvalidSeatCount):
+			return fiber.NewError(fiber.StatusBadRequest, "No seats to block")
+		case errors.Is(err, services.ErrInvalidSeatBlock):
+			return fiber.NewError(fiber.StatusBadRequest, "Give either a showtime or a time range that ends in the future")
+		case errors.Is(err, services.ErrShowtimeNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Showtime not found")
+		case errors.Is(err, services.ErrShowtimeStarted):
+			return fiber.NewError(fiber.StatusConflict, "Showtime has already started")
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to block seats")
+		}
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusCreated, "Seats blocked", h.mapBlocksToResponse(blocks))
+}
+
+func (h *SeatBlockHandler) GetBlocks(c *fiber.Ctx) error {
+	var filter repositories.SeatBlockFilter
+	if raw := c.Query("showtime_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid showtime ID")
+		}
+		filter.ShowtimeID = &id
+	}
+	if raw := c.Query("studio_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid studio ID")
+		}
+		filter.StudioID = &id
+	}
+
+	blocks, err := h.seatBlockService.GetBlocks(c.Context(), filter)
+	if err != nil {
+		if errors.Is(err, services.ErrSeatBlockFilter) {
+			return fiber.NewError(fiber.StatusBadRequest, "Filter by showtime_id or studio_id")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get seat blocks")
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Seat blocks retrieved successfully", h.mapBlocksToResponse(blocks))
+}
+
+func (h *SeatBlockHandler) ReleaseBlock(c *fiber.Ctx) error {
+	staffID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid seat block ID")
+	}
+
+	block, err := h.seatBlockService.ReleaseBlock(c.Context(), id, staffID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSeatBlockNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Seat block not found")
+		case errors.Is(err, services.ErrSeatBlockReleased):
+			return fiber.NewError(fiber.StatusConflict, "Seat block was already released")
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to release seat block")
+		}
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Seat released", h.mapBlockToResponse(block))
+}
+
+func (h *SeatBlockHandler) getUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	user := c.Locals("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userIDStr, ok := claims["user_id"].(string)
+	if !ok {
+		return uuid.Nil, errors.New("invalid user_id in token")
+	}
+	return uuid.Parse(userIDStr)
+}
+
+func (h *SeatBlockHandler) mapBlocksToResponse(blocks []entities.SeatBlock) []dto.BlockedSeatResponse {
+	response := make([]dto.BlockedSeatResponse, 0, len(blocks))
+	for i := range blocks {
+		response = append(response, h.mapBlockToResponse(&blocks[i]))
+	}
+	return response
+}
+
+func (h *SeatBlockHandler) mapBlockToResponse(b *entities.SeatBlock) dto.BlockedSeatResponse {
+	resp := dto.BlockedSeatResponse{
+		ID:         b.ID,
+		Seat:       dto.BlockedSeat{ID: b.SeatID},
+		ShowtimeID: b.ShowtimeID,
+		StartsAt:   b.StartsAt,
+		EndsAt:     b.EndsAt,
+		Reason:     b.Reason,
+		CreatedBy:  b.CreatedBy,
+		CreatedAt:  b.CreatedAt,
+		ReleasedBy: b.ReleasedBy,
+		ReleasedAt: b.ReleasedAt,
+	}
+
+	if b.Seat != nil {
+		resp.Seat.Row = b.Seat.Row
+		resp.Seat.Number = b.Seat.Number
+		resp.Seat.StudioID = b.Seat.StudioID
+	}
+
+	return resp
+}
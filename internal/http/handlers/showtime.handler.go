@@ -41,7 +41,7 @@ func (h *ShowtimeHandler) GetShowtimesByMovie(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch showtimes")
 	}
 
-	response := h.mapShowtimesToResponse(showtimes, false)
+	response := h.mapShowtimesToResponse(showtimes, false, c.QueryBool("seat_types"))
 	return utilities.NewSuccessResponse(c, http.StatusOK, "Showtimes retrieved successfully", response)
 }
 
@@ -66,7 +66,7 @@ func (h *ShowtimeHandler) GetShowtimesByTheater(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch showtimes")
 	}
 
-	response := h.mapShowtimesToResponse(showtimes, true)
+	response := h.mapShowtimesToResponse(showtimes, true, c.QueryBool("seat_types"))
 	return utilities.NewSuccessResponse(c, http.StatusOK, "Showtimes retrieved successfully", response)
 }
 
@@ -86,14 +86,45 @@ func (h *ShowtimeHandler) GetShowtimeByID(c *fiber.Ctx) error {
 	return utilities.NewSuccessResponse(c, http.StatusOK, "Showtime retrieved successfully", response)
 }
 
-func (h *ShowtimeHandler) mapShowtimesToResponse(showtimes []entities.Showtime, includeMovie bool) []dto.ShowtimeResponse {
+func (h *ShowtimeHandler) mapShowtimesToResponse(showtimes []entities.Showtime, includeMovie bool, includeSeatTypes bool) []dto.ShowtimeResponse {
 	var response []dto.ShowtimeResponse
 	for _, st := range showtimes {
-		response = append(response, h.mapShowtimeToResponse(&st, includeMovie))
+		resp := h.mapShowtimeToResponse(&st, includeMovie)
+		resp.Seats = h.mapSeatCounts(st.Availability, includeSeatTypes)
+		response = append(response, resp)
 	}
 	return response
 }
 
+// mapSeatCounts sums the seat counts of a showtime over its seat types.
+func (h *ShowtimeHandler) mapSeatCounts(availability []entities.SeatAvailability, includeSeatTypes bool) *dto.SeatCounts {
+	if availability == nil {
+		return nil
+	}
+
+	counts := &dto.SeatCounts{}
+	for _, a := range availability {
+		counts.Total += a.Total
+		counts.Available += a.Available
+		counts.Held += a.Held
+		counts.Booked += a.Booked
+		counts.Blocked += a.Blocked
+
+		if includeSeatTypes {
+			counts.SeatTypes = append(counts.SeatTypes, dto.SeatTypeSeatCount{
+				ID:        a.SeatTypeID,
+				Name:      a.SeatType,
+				Total:     a.Total,
+				Available: a.Available,
+				Held:      a.Held,
+				Booked:    a.Booked,
+				Blocked:   a.Blocked,
+			})
+		}
+	}
+	return counts
+}
+
 func (h *ShowtimeHandler) mapShowtimeToResponse(st *entities.Showtime, includeMovie bool) dto.ShowtimeResponse {
 	resp := dto.ShowtimeResponse{
 		ID:        st.ID,
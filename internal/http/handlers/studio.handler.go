@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/http/dto"
+	"github.com/senatroxx/filmix-backend/internal/services"
+	"github.com/senatroxx/filmix-backend/internal/utilities"
+)
+
+const layoutCSVContentType = "text/csv; charset=utf-8"
+
+type StudioHandler struct {
+	studioService services.IStudioService
+}
+
+func NewStudioHandler(studioService services.IStudioService) *StudioHandler {
+	return &StudioHandler{studioService: studioService}
+}
+
+// ExportLayout serves a studio's seat map as a layout file, CSV unless
+// format=json is asked for.
+func (h *StudioHandler) ExportLayout(c *fiber.Ctx) error {
+	studioID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid studio ID")
+	}
+
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "json" {
+		return fiber.NewError(fiber.StatusBadRequest, "Format must be csv or json")
+	}
+
+	layout, err := h.studioService.ExportLayout(c.Context(), studioID)
+	if err != nil {
+		if errors.Is(err, services.ErrStudioNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Studio not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to export layout")
+	}
+
+	var body bytes.Buffer
+	contentType := layoutCSVContentType
+	if format == "json" {
+		contentType = fiber.MIMEApplicationJSONCharsetUTF8
+		err = json.NewEncoder(&body).Encode(layout)
+	} else {
+		err = layout.WriteCSV(&body)
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to export layout")
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="studio-`+studioID.String()+`.`+format+`"`)
+	return c.Send(body.Bytes())
+}
+
+// ImportLayout replaces a studio's seat map with the layout in the body, CSV
+// or JSON by its content type. With dry_run=true it only reports what would
+// change.
+func (h *StudioHandler) ImportLayout(c *fiber.Ctx) error {
+	studioID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid studio ID")
+	}
+
+	var layout *services.StudioLayout
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		layout, err = services.ParseLayoutJSON(bytes.NewReader(c.Body()))
+	} else {
+		layout, err = services.ParseLayoutCSV(bytes.NewReader(c.Body()))
+	}
+	if err != nil {
+		return layoutImportError(err)
+	}
+
+	changes, err := h.studioService.ImportLayout(c.Context(), studioID, layout, c.QueryBool("dry_run"))
+	if err != nil {
+		return layoutImportError(err)
+	}
+
+	message := "Layout imported"
+	if !changes.Applied {
+		message = "Layout checked"
+	}
+	return utilities.NewSuccessResponse(c, http.StatusOK, message, dto.LayoutChangesResponse{
+		Added:       changes.Added,
+		Reactivated: changes.Reactivated,
+		Deactivated: changes.Deactivated,
+		Retyped:     changes.Retyped,
+		Updated:     changes.Updated,
+		Gaps:        changes.Gaps,
+		Applied:     changes.Applied,
+	})
+}
+
+// layoutImportError maps a failed layout import to a response: 422 listing
+// what is wrong with the layout, or 409 naming the seats it would take off
+// sale that are still booked.
+func layoutImportError(err error) error {
+	var invalid *services.LayoutError
+	if errors.As(err, &invalid) {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "Invalid layout: "+strings.Join(invalid.Problems, "; "))
+	}
+
+	var selection *services.SeatSelectionError
+	if errors.As(err, &selection) && errors.Is(err, services.ErrSeatsInUse) {
+		ids := make([]string, len(selection.SeatIDs))
+		for i, id := range selection.SeatIDs {
+			ids[i] = id.String()
+		}
+		return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Seats to remove are booked for upcoming showtimes: %s", strings.Join(ids, ", ")))
+	}
+
+	if errors.Is(err, services.ErrStudioNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "Studio not found")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, "Failed to import layout")
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/http/dto"
+	"github.com/senatroxx/filmix-backend/internal/services"
+	"github.com/senatroxx/filmix-backend/internal/utilities"
+)
+
+type TicketHandler struct {
+	ticketService services.ITicketService
+}
+
+func NewTicketHandler(ticketService services.ITicketService) *TicketHandler {
+	return &TicketHandler{ticketService: ticketService}
+}
+
+// GetTicket serves the booking's e-ticket as a QR code PNG.
+func (h *TicketHandler) GetTicket(c *fiber.Ctx) error {
+	user := c.Locals("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userIDStr, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	bookingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid booking ID")
+	}
+
+	png, err := h.ticketService.RenderTicketQR(c.Context(), bookingID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrBookingNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Booking not found")
+		}
+		if errors.Is(err, services.ErrTicketNotAvailable) {
+			return fiber.NewError(fiber.StatusConflict, "Ticket is only available for paid bookings")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get ticket")
+	}
+
+	c.Set(fiber.HeaderContentType, "image/png")
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Send(png)
+}
+
+func (h *TicketHandler) CheckIn(c *fiber.Ctx) error {
+	user := c.Locals("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	staffIDStr, _ := claims["user_id"].(string)
+	staffID, err := uuid.Parse(staffIDStr)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	var req dto.CheckInRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if errMsg := utilities.ValidateStruct(req); errMsg != "" {
+		return fiber.NewError(fiber.StatusBadRequest, errMsg)
+	}
+
+	booking, err := h.ticketService.CheckIn(c.Context(), req.Token, staffID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTicket):
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid ticket")
+		case errors.Is(err, services.ErrTicketAlreadyUsed):
+			return fiber.NewError(fiber.StatusConflict, "Ticket has already been used")
+		case errors.Is(err, services.ErrTicketNotValid):
+			return fiber.NewError(fiber.StatusConflict, "Booking is no longer valid for entry")
+		case errors.Is(err, services.ErrCheckInNotOpen):
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "Check-in is not open yet")
+		case errors.Is(err, services.ErrCheckInClosed):
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "Check-in has closed")
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to check in")
+		}
+	}
+
+	resp := dto.CheckInResponse{
+		BookingID:   booking.ID,
+		Status:      string(booking.Status),
+		CheckedInAt: booking.CheckedInAt,
+	}
+	if booking.Showtime != nil {
+		resp.Showtime = dto.BookingShowtime{ID: booking.Showtime.ID, Time: booking.Showtime.Time}
+		if booking.Showtime.Movie != nil {
+			resp.Showtime.Movie = dto.MovieBrief{
+				ID:        booking.Showtime.Movie.ID,
+				Title:     booking.Showtime.Movie.Title,
+				PosterURL: booking.Showtime.Movie.PosterURL,
+			}
+		}
+	}
+	if booking.Theater != nil {
+		resp.Theater = dto.BookingTheater{ID: booking.Theater.ID, Name: booking.Theater.Name}
+	}
+	for _, item := range booking.Items {
+		seat := dto.BookingSeatItem{ID: item.SeatID, Price: item.Price}
+		if item.Seat != nil {
+			seat.Row = item.Seat.Row
+			seat.Number = item.Seat.Number
+			if item.Seat.SeatType != nil {
+				seat.SeatType = item.Seat.SeatType.Name
+			}
+		}
+		resp.Seats = append(resp.Seats, seat)
+	}
+	resp.Addons = mapAddonsToResponse(booking.Addons)
+	resp.PickupCode = booking.PickupCode
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Checked in successfully", resp)
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/http/dto"
+	"github.com/senatroxx/filmix-backend/internal/services"
+	"github.com/senatroxx/filmix-backend/internal/utilities"
+)
+
+type TransferHandler struct {
+	transferService services.ITransferService
+}
+
+func NewTransferHandler(transferService services.ITransferService) *TransferHandler {
+	return &TransferHandler{transferService: transferService}
+}
+
+func (h *TransferHandler) CreateTransfer(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	bookingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid booking ID")
+	}
+
+	var req dto.CreateTransferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if errMsg := utilities.ValidateStruct(req); errMsg != "" {
+		return fiber.NewError(fiber.StatusBadRequest, errMsg)
+	}
+
+	transfer, err := h.transferService.Create(c.Context(), services.CreateTransferInput{
+		BookingID: bookingID,
+		SeatID:    req.SeatID,
+		UserID:    userID,
+		Email:     req.Email,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBookingNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Booking not found")
+		case errors.Is(err, services.ErrTransferSeatNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Seat is not part of this booking")
+		case errors.Is(err, services.ErrSeatNotHeld):
+			return fiber.NewError(fiber.StatusForbidden, "Seat's ticket is held by someone else")
+		case errors.Is(err, services.ErrTicketNotTransferable):
+			return fiber.NewError(fiber.StatusConflict, "Ticket can no longer be transferred")
+		case errors.Is(err, services.ErrRecipientNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Recipient is not a registered user")
+		case errors.Is(err, services.ErrInvalidTransfer):
+			return fiber.NewError(fiber.StatusBadRequest, "Cannot transfer a ticket to yourself")
+		case errors.Is(err, services.ErrTransferPending):
+			return fiber.NewError(fiber.StatusConflict, "Seat already has a pending transfer")
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to transfer ticket")
+		}
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusCreated, "Ticket transfer sent", h.mapTransferToResponse(transfer))
+}
+
+func (h *TransferHandler) GetBookingTransfers(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	bookingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid booking ID")
+	}
+
+	transfers, err := h.transferService.GetBookingTransfers(c.Context(), bookingID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrBookingNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Booking not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get transfers")
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Transfers retrieved successfully", h.mapTransfersToResponse(transfers))
+}
+
+func (h *TransferHandler) GetUserTransfers(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	transfers, err := h.transferService.GetUserTransfers(c.Context(), userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get transfers")
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Transfers retrieved successfully", h.mapTransfersToResponse(transfers))
+}
+
+func (h *TransferHandler) AcceptTransfer(c *fiber.Ctx) error {
+	return h.respond(c, h.transferService.Accept, "Ticket transfer accepted")
+}
+
+func (h *TransferHandler) DeclineTransfer(c *fiber.Ctx) error {
+	return h.respond(c, h.transferService.Decline, "Ticket transfer declined")
+}
+
+func (h *TransferHandler) CancelTransfer(c *fiber.Ctx) error {
+	return h.respond(c, h.transferService.Cancel, "Ticket transfer cancelled")
+}
+
+// respond runs one of the answers to a pending transfer.
+func (h *TransferHandler) respond(c *fiber.Ctx, answer func(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.TicketTransfer, error), message string) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid transfer ID")
+	}
+
+	transfer, err := answer(c.Context(), id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTransferNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Transfer not found")
+		case errors.Is(err, services.ErrTransferNotOpen):
+			return fiber.NewError(fiber.StatusConflict, "Transfer is no longer open")
+		case errors.Is(err, services.ErrTicketNotTransferable):
+			return fiber.NewError(fiber.StatusConflict, "Ticket can no longer be transferred")
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to update transfer")
+		}
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, message, h.mapTransferToResponse(transfer))
+}
+
+func (h *TransferHandler) getUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	user := c.Locals("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userIDStr, ok := claims["user_id"].(string)
+	if !ok {
+		return uuid.Nil, errors.New("invalid user_id in token")
+	}
+	return uuid.Parse(userIDStr)
+}
+
+func (h *TransferHandler) mapTransfersToResponse(transfers []entities.TicketTransfer) []dto.TransferResponse {
+	response := make([]dto.TransferResponse, 0, len(transfers))
+	for i := range transfers {
+		response = append(response, h.mapTransferToResponse(&transfers[i]))
+	}
+	return response
+}
+
+func (h *TransferHandler) mapTransferToResponse(t *entities.TicketTransfer) dto.TransferResponse {
+	resp := dto.TransferResponse{
+		ID:          t.ID,
+		BookingID:   t.TransactionID,
+		Status:      t.Status,
+		From:        dto.TransferUser{ID: t.FromUserID},
+		To:          dto.TransferUser{ID: t.ToUserID},
+		RespondedAt: t.RespondedAt,
+		CreatedAt:   t.CreatedAt,
+	}
+
+	if t.FromUser != nil {
+		resp.From.Name = t.FromUser.Name
+		resp.From.Email = t.FromUser.Email
+	}
+	if t.ToUser != nil {
+		resp.To.Name = t.ToUser.Name
+		resp.To.Email = t.ToUser.Email
+	}
+	if t.Seat != nil {
+		resp.Seat = dto.TransferSeat{ID: t.Seat.ID, Row: t.Seat.Row, Number: t.Seat.Number}
+	}
+	if t.Showtime != nil {
+		resp.Showtime = dto.BookingShowtime{ID: t.Showtime.ID, Time: t.Showtime.Time}
+		if t.Showtime.Movie != nil {
+			resp.Showtime.Movie = dto.MovieBrief{
+				ID:        t.Showtime.Movie.ID,
+				Title:     t.Showtime.Movie.Title,
+				PosterURL: t.Showtime.Movie.PosterURL,
+			}
+		}
+	}
+
+	return resp
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/http/dto"
+	"github.com/senatroxx/filmix-backend/internal/services"
+	"github.com/senatroxx/filmix-backend/internal/utilities"
+)
+
+type WaitlistHandler struct {
+	waitlistService services.IWaitlistService
+}
+
+func NewWaitlistHandler(waitlistService services.IWaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{waitlistService: waitlistService}
+}
+
+func (h *WaitlistHandler) JoinWaitlist(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	showtimeID, err := uuid.Parse(c.Params("showtimeId"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid showtime ID")
+	}
+
+	var req dto.JoinWaitlistRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	entry, err := h.waitlistService.Join(c.Context(), services.JoinWaitlistInput{
+		UserID:     userID,
+		ShowtimeID: showtimeID,
+		SeatCount:  req.SeatCount,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSeatCount) {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Seat count must be between 1 and %d", services.MaxSuggestedSeats))
+		}
+		if errors.Is(err, services.ErrShowtimeNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Showtime not found")
+		}
+		if errors.Is(err, services.ErrShowtimeStarted) {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "Showtime has already started")
+		}
+		if errors.Is(err, services.ErrSeatsStillAvailable) {
+			return fiber.NewError(fiber.StatusConflict, "Showtime still has enough seats available")
+		}
+		if errors.Is(err, services.ErrAlreadyWaitlisted) {
+			return fiber.NewError(fiber.StatusConflict, "Already on the waitlist for this showtime")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to join waitlist")
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusCreated, "Joined waitlist successfully", h.mapEntryToResponse(entry))
+}
+
+func (h *WaitlistHandler) GetUserEntries(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	entries, err := h.waitlistService.GetUserEntries(c.Context(), userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get waitlist")
+	}
+
+	var response []dto.WaitlistEntryResponse
+	for i := range entries {
+		response = append(response, h.mapEntryToResponse(&entries[i]))
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Waitlist retrieved successfully", response)
+}
+
+func (h *WaitlistHandler) LeaveWaitlist(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid waitlist entry ID")
+	}
+
+	entry, err := h.waitlistService.Leave(c.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrWaitlistEntryNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Waitlist entry not found")
+		}
+		if errors.Is(err, services.ErrWaitlistEntryNotOpen) {
+			return fiber.NewError(fiber.StatusConflict, "Waitlist entry is no longer open")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to leave waitlist")
+	}
+
+	return utilities.NewSuccessResponse(c, http.StatusOK, "Left waitlist successfully", h.mapEntryToResponse(entry))
+}
+
+func (h *WaitlistHandler) getUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	user := c.Locals("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userIDStr, ok := claims["user_id"].(string)
+	if !ok {
+		return uuid.Nil, errors.New("invalid user_id in token")
+	}
+	return uuid.Parse(userIDStr)
+}
+
+func (h *WaitlistHandler) mapEntryToResponse(e *entities.WaitlistEntry) dto.WaitlistEntryResponse {
+	resp := dto.WaitlistEntryResponse{
+		ID:        e.ID,
+		Status:    e.Status,
+		SeatCount: e.SeatCount,
+		Position:  e.Position,
+		CreatedAt: e.CreatedAt,
+	}
+
+	if e.Showtime != nil {
+		resp.Showtime = dto.BookingShowtime{
+			ID:   e.Showtime.ID,
+			Time: e.Showtime.Time,
+		}
+		if e.Showtime.Movie != nil {
+			resp.Showtime.Movie = dto.MovieBrief{
+				ID:        e.Showtime.Movie.ID,
+				Title:     e.Showtime.Movie.Title,
+				PosterURL: e.Showtime.Movie.PosterURL,
+			}
+		}
+	}
+
+	if e.Status == entities.WaitlistStatusOffered && e.OfferExpiresAt != nil {
+		resp.Hold = &dto.WaitlistHold{ExpiresAt: *e.OfferExpiresAt}
+		for _, hold := range e.Holds {
+			seat := dto.WaitlistHoldSeat{ID: hold.SeatID}
+			if hold.Seat != nil {
+				seat.Row = hold.Seat.Row
+				seat.Number = hold.Seat.Number
+			}
+			resp.Hold.Seats = append(resp.Hold.Seats, seat)
+		}
+	}
+
+	return resp
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"database/sql"
 	"os"
 	"os/signal"
@@ -17,11 +18,20 @@ import (
 	"github.com/senatroxx/filmix-backend/internal/http/routes"
 )
 
+// Worker is a background task whose lifetime is bound to the API: it is
+// started by Run and its context is cancelled on shutdown.
+type Worker interface {
+	Run(ctx context.Context)
+}
+
 type API struct {
 	App    *fiber.App
 	Config *config.Config
 	Logger zerolog.Logger
 	Wg     *sync.WaitGroup
+
+	workers        []Worker
+	beforeShutdown []func()
 }
 
 func InitializeAPI(cfg *config.Config, h *handlers.Handlers, db *sql.DB, log zerolog.Logger) *API {
@@ -62,10 +72,31 @@ func InitializeAPI(cfg *config.Config, h *handlers.Handlers, db *sql.DB, log zer
 		App:    app,
 		Config: cfg,
 		Logger: log,
+		Wg:     &sync.WaitGroup{},
 	}
 }
 
+// AddWorker registers a background worker to be run alongside the server.
+func (a *API) AddWorker(w Worker) {
+	a.workers = append(a.workers, w)
+}
+
+// BeforeShutdown registers fn to run before the server stops, e.g. to end
+// long-lived streams that would otherwise keep it waiting.
+func (a *API) BeforeShutdown(fn func()) {
+	a.beforeShutdown = append(a.beforeShutdown, fn)
+}
+
 func (a *API) Run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, w := range a.workers {
+		a.Wg.Add(1)
+		go func(w Worker) {
+			defer a.Wg.Done()
+			w.Run(ctx)
+		}(w)
+	}
+
 	go func() {
 		if err := a.App.Listen(":" + a.Config.Port); err != nil {
 			a.Logger.Fatal().Err(err).Msg("Failed to start server.")
@@ -77,9 +108,14 @@ func (a *API) Run() {
 
 	_ = <-c // This blocks the main thread until an interrupt is received
 	a.Logger.Info().Msg("Gracefully shutting down...")
+	for _, fn := range a.beforeShutdown {
+		fn()
+	}
 	_ = a.App.Shutdown()
 
 	a.Logger.Info().Msg("Running cleanup tasks...")
+	cancel()
+	a.Wg.Wait()
 
 	// Your cleanup tasks go here
 	// db.Close()
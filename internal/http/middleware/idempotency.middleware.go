@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/services"
+	"github.com/senatroxx/filmix-backend/internal/utilities"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotency deduplicates requests that carry an Idempotency-Key header, per
+// user. The first response (status code and body) is stored and replayed to
+// retries with the same key; reusing a key for a different payload is a 409.
+// Server errors are not stored so the request can be retried. Requests without
+// the header pass through untouched. It must run after Protected.
+func Idempotency(svc services.IIdempotencyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key is too long")
+		}
+
+		user, ok := c.Locals("user").(*jwt.Token)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired token")
+		}
+		claims, _ := user.Claims.(jwt.MapClaims)
+		userIDStr, _ := claims["user_id"].(string)
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid user")
+		}
+
+		req := services.IdempotencyRequest{
+			UserID:      userID,
+			Key:         key,
+			Fingerprint: requestFingerprint(c),
+		}
+
+		stored, err := svc.Begin(c.Context(), req)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrIdempotencyKeyReused):
+				return fiber.NewError(fiber.StatusConflict, "Idempotency-Key was already used for a different request")
+			case errors.Is(err, services.ErrIdempotencyKeyInFlight):
+				return fiber.NewError(fiber.StatusConflict, "A request with this Idempotency-Key is still being processed")
+			default:
+				return err
+			}
+		}
+
+		if stored != nil {
+			c.Set(HeaderIdempotentReplayed, "true")
+			if stored.ContentType != "" {
+				c.Set(fiber.HeaderContentType, stored.ContentType)
+			}
+			return c.Status(*stored.StatusCode).Send(stored.ResponseBody)
+		}
+
+		// Render handler errors now so the response we store is exactly the
+		// one the client receives.
+		if err := c.Next(); err != nil {
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = svc.Release(c.Context(), req)
+				return herr
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			if err := svc.Release(c.Context(), req); err != nil {
+				utilities.Logger.Error().Err(err).Msg("Failed to release idempotency key")
+			}
+			return nil
+		}
+
+		body := append([]byte(nil), c.Response().Body()...)
+		contentType := string(c.Response().Header.ContentType())
+		if err := svc.Complete(c.Context(), req, status, contentType, body); err != nil {
+			utilities.Logger.Error().Err(err).Msg("Failed to store idempotent response")
+		}
+
+		return nil
+	}
+}
+
+func requestFingerprint(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Path()))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RequireRole only lets through users whose token carries one of the given
+// roles. It must run after Protected.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(*jwt.Token)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired token")
+		}
+
+		claims, _ := user.Claims.(jwt.MapClaims)
+		role, _ := claims["role"].(string)
+		if !slices.Contains(roles, role) {
+			return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+		}
+
+		return c.Next()
+	}
+}
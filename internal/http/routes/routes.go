@@ -14,4 +14,12 @@ func SetupRoutes(r fiber.Router, h *handlers.Handlers) {
 	v1.ShowtimeRoutes(v1api, h)
 	v1.SeatRoutes(v1api, h)
 	v1.BookingRoutes(v1api, h)
+	v1.PaymentRoutes(v1api, h)
+	v1.CheckInRoutes(v1api, h)
+	v1.WaitlistRoutes(v1api, h)
+	v1.ConcessionRoutes(v1api, h)
+	v1.TransferRoutes(v1api, h)
+	v1.CalendarRoutes(v1api, h)
+	v1.SeatBlockRoutes(v1api, h)
+	v1.StudioRoutes(v1api, h)
 }
@@ -9,7 +9,10 @@ import (
 func BookingRoutes(r fiber.Router, h *handlers.Handlers) {
 	bookings := r.Group("/bookings", middleware.Protected())
 
-	bookings.Post("/", h.Booking.CreateBooking)
+	bookings.Post("/", h.Idempotency, h.Booking.CreateBooking)
 	bookings.Get("/", h.Booking.GetUserBookings)
 	bookings.Get("/:id", h.Booking.GetBooking)
+	bookings.Patch("/:id", h.Idempotency, h.Booking.AmendBooking)
+	bookings.Post("/:id/cancel", h.Booking.CancelBooking)
+	bookings.Get("/:id/ticket", h.Ticket.GetTicket)
 }
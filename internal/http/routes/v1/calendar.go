@@ -0,0 +1,17 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/senatroxx/filmix-backend/internal/http/handlers"
+	"github.com/senatroxx/filmix-backend/internal/http/middleware"
+)
+
+func CalendarRoutes(r fiber.Router, h *handlers.Handlers) {
+	r.Get("/bookings/:id/calendar.ics", middleware.Protected(), h.Calendar.GetBookingCalendar)
+
+	calendar := r.Group("/calendar")
+
+	calendar.Post("/feed", middleware.Protected(), h.Calendar.CreateFeed)
+	calendar.Delete("/feed", middleware.Protected(), h.Calendar.RevokeFeed)
+	calendar.Get("/:token.ics", h.Calendar.GetFeed)
+}
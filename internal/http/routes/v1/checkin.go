@@ -0,0 +1,11 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/senatroxx/filmix-backend/internal/http/handlers"
+	"github.com/senatroxx/filmix-backend/internal/http/middleware"
+)
+
+func CheckInRoutes(r fiber.Router, h *handlers.Handlers) {
+	r.Post("/checkin", middleware.Protected(), middleware.RequireRole("staff", "admin"), h.Ticket.CheckIn)
+}
@@ -0,0 +1,11 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/senatroxx/filmix-backend/internal/http/handlers"
+	"github.com/senatroxx/filmix-backend/internal/http/middleware"
+)
+
+func ConcessionRoutes(r fiber.Router, h *handlers.Handlers) {
+	r.Get("/theaters/:theaterId/concessions", middleware.Protected(), h.Concession.GetCatalog)
+}
@@ -0,0 +1,15 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/senatroxx/filmix-backend/internal/http/handlers"
+	"github.com/senatroxx/filmix-backend/internal/http/middleware"
+)
+
+func PaymentRoutes(r fiber.Router, h *handlers.Handlers) {
+	payments := r.Group("/payments")
+
+	// Provider callbacks are authenticated by their signature, not a user token.
+	payments.Post("/webhooks/:provider", h.Payment.Webhook)
+	payments.Post("/simulate/:id", middleware.Protected(), h.Payment.SimulatePayment)
+}
@@ -8,4 +8,6 @@ import (
 
 func SeatRoutes(r fiber.Router, h *handlers.Handlers) {
 	r.Get("/showtimes/:showtimeId/seats", middleware.Protected(), h.Seat.GetSeatsForShowtime)
+	r.Post("/showtimes/:showtimeId/seats/suggest", middleware.Protected(), h.Seat.SuggestSeats)
+	r.Get("/showtimes/:showtimeId/seats/stream", middleware.Protected(), h.Seat.StreamSeats)
 }
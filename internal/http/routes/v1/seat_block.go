@@ -0,0 +1,15 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/senatroxx/filmix-backend/internal/http/handlers"
+	"github.com/senatroxx/filmix-backend/internal/http/middleware"
+)
+
+func SeatBlockRoutes(r fiber.Router, h *handlers.Handlers) {
+	blocks := r.Group("/seat-blocks", middleware.Protected(), middleware.RequireRole("staff", "admin"))
+
+	blocks.Get("/", h.SeatBlock.GetBlocks)
+	blocks.Post("/", h.SeatBlock.BlockSeats)
+	blocks.Delete("/:id", h.SeatBlock.ReleaseBlock)
+}
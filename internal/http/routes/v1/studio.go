@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/senatroxx/filmix-backend/internal/http/handlers"
+	"github.com/senatroxx/filmix-backend/internal/http/middleware"
+)
+
+func StudioRoutes(r fiber.Router, h *handlers.Handlers) {
+	studios := r.Group("/studios", middleware.Protected(), middleware.RequireRole("admin"))
+
+	studios.Get("/:id/layout", h.Studio.ExportLayout)
+	studios.Put("/:id/layout", h.Studio.ImportLayout)
+}
@@ -0,0 +1,19 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/senatroxx/filmix-backend/internal/http/handlers"
+	"github.com/senatroxx/filmix-backend/internal/http/middleware"
+)
+
+func TransferRoutes(r fiber.Router, h *handlers.Handlers) {
+	r.Post("/bookings/:id/transfers", middleware.Protected(), h.Transfer.CreateTransfer)
+	r.Get("/bookings/:id/transfers", middleware.Protected(), h.Transfer.GetBookingTransfers)
+
+	transfers := r.Group("/transfers", middleware.Protected())
+
+	transfers.Get("/", h.Transfer.GetUserTransfers)
+	transfers.Post("/:id/accept", h.Transfer.AcceptTransfer)
+	transfers.Post("/:id/decline", h.Transfer.DeclineTransfer)
+	transfers.Delete("/:id", h.Transfer.CancelTransfer)
+}
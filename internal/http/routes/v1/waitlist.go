@@ -0,0 +1,16 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/senatroxx/filmix-backend/internal/http/handlers"
+	"github.com/senatroxx/filmix-backend/internal/http/middleware"
+)
+
+func WaitlistRoutes(r fiber.Router, h *handlers.Handlers) {
+	r.Post("/showtimes/:showtimeId/waitlist", middleware.Protected(), h.Waitlist.JoinWaitlist)
+
+	waitlist := r.Group("/waitlist", middleware.Protected())
+
+	waitlist.Get("/", h.Waitlist.GetUserEntries)
+	waitlist.Delete("/:id", h.Waitlist.LeaveWaitlist)
+}
@@ -0,0 +1,39 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Message is a notice for one user. Channels decide how to deliver it, e.g.
+// as an email or a push notification.
+type Message struct {
+	UserID  uuid.UUID
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to users.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// LogNotifier is a local stand-in for a real delivery channel that writes
+// every message to the log.
+type LogNotifier struct {
+	logger zerolog.Logger
+}
+
+func NewLogNotifier(logger zerolog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger.With().Str("notifier", "log").Logger()}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, msg Message) error {
+	n.logger.Info().
+		Str("user_id", msg.UserID.String()).
+		Str("subject", msg.Subject).
+		Msg(msg.Body)
+	return nil
+}
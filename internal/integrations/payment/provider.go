@@ -0,0 +1,113 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrProviderNotFound = errors.New("no payment provider registered for method")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrChargeNotCancellable is returned by CancelCharge when the charge has
+	// already been paid.
+	ErrChargeNotCancellable = errors.New("charge can no longer be cancelled")
+)
+
+const (
+	KindVirtualAccount = "virtual_account"
+	KindDeeplink       = "deeplink"
+)
+
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventRefundSucceeded  = "refund.succeeded"
+)
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+)
+
+type ChargeRequest struct {
+	TransactionID uuid.UUID
+	Amount        int64
+	ExpiresAt     time.Time
+}
+
+// Instructions tell the customer how to complete a charge, e.g. which virtual
+// account to transfer to or which app deeplink to open.
+type Instructions struct {
+	Kind      string    `json:"kind"`
+	VANumber  string    `json:"va_number,omitempty"`
+	Deeplink  string    `json:"deeplink,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type Charge struct {
+	Reference    string
+	Instructions Instructions
+}
+
+type RefundRequest struct {
+	// ChargeReference is the reference of the charge being refunded.
+	ChargeReference string
+	Amount          int64
+}
+
+// Refund is the provider's answer to a refund request. Gateways that settle
+// asynchronously report it as pending and follow up with a webhook.
+type Refund struct {
+	Reference string
+	Status    string
+}
+
+// Event is a provider notification normalized from its webhook payload.
+// Reference is always the charge reference; refund events also carry the
+// provider's refund reference.
+type Event struct {
+	Type            string    `json:"type"`
+	Reference       string    `json:"reference"`
+	RefundReference string    `json:"refund_reference,omitempty"`
+	Amount          int64     `json:"amount"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
+// Provider is a payment gateway able to serve one or more payment methods.
+type Provider interface {
+	CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	Refund(ctx context.Context, req RefundRequest) (*Refund, error)
+	// CancelCharge voids an unpaid charge so the customer can no longer pay it.
+	CancelCharge(ctx context.Context, reference string) error
+	// ParseWebhook verifies the signature of a webhook body and decodes it.
+	ParseWebhook(body []byte, signature string) (*Event, error)
+}
+
+// Simulator is implemented by providers that can fake a customer completing a
+// payment, used to exercise the pay flow locally.
+type Simulator interface {
+	SimulateEvent(event Event) (body []byte, signature string, err error)
+}
+
+// Registry maps payment method codes (e.g. GOPAY, BCA_VA) to providers.
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+func (r *Registry) Register(code string, p Provider) {
+	r.providers[code] = p
+}
+
+func (r *Registry) Get(code string) (Provider, error) {
+	p, ok := r.providers[code]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+	return p, nil
+}
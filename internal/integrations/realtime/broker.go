@@ -0,0 +1,144 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// States a seat can move to, as announced by a SeatEvent.
+const (
+	SeatHeld     = "held"
+	SeatBooked   = "booked"
+	SeatReleased = "released"
+	SeatBlocked  = "blocked"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before it
+// is dropped.
+const subscriberBuffer = 64
+
+// SeatEvent announces that seats of a showtime changed state. Held seats come
+// free on their own at ExpiresAt unless they are booked first.
+type SeatEvent struct {
+	ShowtimeID uuid.UUID   `json:"showtime_id"`
+	State      string      `json:"state"`
+	SeatIDs    []uuid.UUID `json:"seat_ids"`
+	ExpiresAt  *time.Time  `json:"expires_at,omitempty"`
+	At         time.Time   `json:"at"`
+}
+
+// Publisher announces seat changes.
+type Publisher interface {
+	Publish(ctx context.Context, event SeatEvent) error
+}
+
+// Broker announces seat changes and lets listeners follow a showtime.
+type Broker interface {
+	Publisher
+	Subscribe(showtimeID uuid.UUID) *Subscription
+	// Close ends every subscription.
+	Close()
+}
+
+// Subscription receives the events of one showtime until it is closed. The
+// channel is closed when the subscriber falls too far behind or the broker
+// shuts down; it should then start over from a fresh seat map.
+type Subscription struct {
+	Events <-chan SeatEvent
+
+	close func()
+}
+
+// Close stops the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.close()
+}
+
+// LocalBroker delivers events to subscribers of this process only.
+type LocalBroker struct {
+	mu     sync.Mutex
+	subs   map[uuid.UUID]map[chan SeatEvent]struct{}
+	closed bool
+}
+
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{subs: make(map[uuid.UUID]map[chan SeatEvent]struct{})}
+}
+
+func (b *LocalBroker) Publish(ctx context.Context, event SeatEvent) error {
+	b.deliver(event)
+	return nil
+}
+
+func (b *LocalBroker) Subscribe(showtimeID uuid.UUID) *Subscription {
+	ch := make(chan SeatEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		close(ch)
+		return &Subscription{Events: ch, close: func() {}}
+	}
+	if b.subs[showtimeID] == nil {
+		b.subs[showtimeID] = make(map[chan SeatEvent]struct{})
+	}
+	b.subs[showtimeID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return &Subscription{Events: ch, close: func() { b.unsubscribe(showtimeID, ch) }}
+}
+
+func (b *LocalBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for showtimeID, subs := range b.subs {
+		for ch := range subs {
+			close(ch)
+		}
+		delete(b.subs, showtimeID)
+	}
+}
+
+// deliver hands an event to every subscriber of its showtime without waiting
+// on any of them; subscribers whose buffer is full are dropped.
+func (b *LocalBroker) deliver(event SeatEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[event.ShowtimeID] {
+		select {
+		case ch <- event:
+		default:
+			b.remove(event.ShowtimeID, ch)
+		}
+	}
+}
+
+func (b *LocalBroker) unsubscribe(showtimeID uuid.UUID, ch chan SeatEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(showtimeID, ch)
+}
+
+// remove drops a subscriber; b.mu must be held.
+func (b *LocalBroker) remove(showtimeID uuid.UUID, ch chan SeatEvent) {
+	subs, ok := b.subs[showtimeID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+
+	delete(subs, ch)
+	close(ch)
+	if len(subs) == 0 {
+		delete(b.subs, showtimeID)
+	}
+}
@@ -0,0 +1,89 @@
+package realtime
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+// notifyChannel is the Postgres channel seat events travel on.
+const notifyChannel = "seat_events"
+
+// PostgresBroker fans seat events out to every instance of the API through
+// Postgres LISTEN/NOTIFY. Publishing only sends the notification; each
+// instance, this one included, delivers it to its own subscribers once it
+// comes back. Run must be running for subscribers to receive anything.
+type PostgresBroker struct {
+	*LocalBroker
+
+	db     *sql.DB
+	dsn    string
+	logger zerolog.Logger
+}
+
+func NewPostgresBroker(db *sql.DB, dsn string, logger zerolog.Logger) *PostgresBroker {
+	return &PostgresBroker{
+		LocalBroker: NewLocalBroker(),
+		db:          db,
+		dsn:         dsn,
+		logger:      logger.With().Str("worker", "seat_events").Logger(),
+	}
+}
+
+func (b *PostgresBroker) Publish(ctx context.Context, event SeatEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, notifyChannel, string(payload))
+	return err
+}
+
+// Run listens for seat events until ctx is cancelled. The listener reconnects
+// on its own; events sent while it was disconnected are lost, so clients
+// should refresh their seat map after a reconnect.
+func (b *PostgresBroker) Run(ctx context.Context) {
+	listener := pq.NewListener(b.dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventConnectionAttemptFailed, pq.ListenerEventDisconnected:
+			b.logger.Warn().Err(err).Msg("Seat event listener lost its connection")
+		case pq.ListenerEventReconnected:
+			b.logger.Info().Msg("Seat event listener reconnected")
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(notifyChannel); err != nil {
+		b.logger.Error().Err(err).Msg("Failed to listen for seat events")
+		return
+	}
+	b.logger.Info().Msg("Seat event listener started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.logger.Info().Msg("Seat event listener stopped")
+			return
+		case n := <-listener.Notify:
+			// A nil notification only says the connection was re-established.
+			if n == nil {
+				continue
+			}
+
+			var event SeatEvent
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				b.logger.Warn().Err(err).Msg("Dropped malformed seat event")
+				continue
+			}
+			b.deliver(event)
+		case <-time.After(90 * time.Second):
+			// Make sure a silently dropped connection gets noticed.
+			go listener.Ping()
+		}
+	}
+}
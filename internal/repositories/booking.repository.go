@@ -3,7 +3,10 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,11 +14,77 @@ import (
 	"github.com/senatroxx/filmix-backend/internal/database/entities"
 )
 
+var (
+	// ErrSeatsTaken is returned by Create, Amend and SettleAmendment when
+	// another booking already holds one of the requested seats for the showtime.
+	ErrSeatsTaken = errors.New("seats already taken")
+	// ErrInvalidTransition is returned when asked to move a transaction between
+	// two statuses the state machine does not connect.
+	ErrInvalidTransition = errors.New("invalid transaction status transition")
+	// ErrTransactionChanged is returned by Amend and SettleAmendment when the
+	// transaction left the status or showtime the amendment was priced against.
+	ErrTransactionChanged = errors.New("transaction changed concurrently")
+	// ErrAddonsOutOfStock is returned by Create when a concession variant has
+	// fewer left than ordered.
+	ErrAddonsOutOfStock = errors.New("concession out of stock")
+)
+
+// UserBookingActivity sums up what a user has booked, for purchase limits.
+type UserBookingActivity struct {
+	// ShowtimeSeats is how many seats the user holds for the showtime.
+	ShowtimeSeats int
+	// PendingBookings is how many of the user's bookings await payment.
+	PendingBookings int
+	// RecentBookings is how many bookings the user created in the window.
+	RecentBookings int
+}
+
+// BookingFilter narrows down and pages a user's booking history. Zero values
+// don't filter.
+type BookingFilter struct {
+	Statuses []entities.TransactionStatus
+	// Upcoming keeps bookings whose showtime is still ahead when true, or has
+	// already started when false.
+	Upcoming *bool
+	// From and To bound the showtime, From inclusive and To exclusive.
+	From *time.Time
+	To   *time.Time
+	// Ascending sorts by showtime earliest first instead of latest first.
+	Ascending bool
+	Page      int
+	Limit     int
+}
+
 type IBookingRepository interface {
-	Create(ctx context.Context, tx *entities.Transaction, items []entities.TransactionItem) error
+	Create(ctx context.Context, tx *entities.Transaction, items []entities.TransactionItem, addons []entities.TransactionAddon, charges []entities.TransactionCharge, check *PurchaseCheck) error
 	FindByID(ctx context.Context, id uuid.UUID) (*entities.Transaction, error)
-	FindByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Transaction, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID, filter BookingFilter) ([]entities.Transaction, int, error)
 	CheckSeatsAvailable(ctx context.Context, showtimeID uuid.UUID, seatIDs []uuid.UUID) (bool, error)
+	ExpirePending(ctx context.Context, limit int) ([]entities.Transaction, error)
+	FindByExternalRef(ctx context.Context, externalRef string) (*entities.Transaction, error)
+	MarkPaid(ctx context.Context, id uuid.UUID, paidAt time.Time, invoicePeriod string, change entities.StatusChange) (bool, error)
+	AttachCharge(ctx context.Context, id uuid.UUID, externalRef string, instructions *entities.PaymentInstructions) error
+	Expire(ctx context.Context, id uuid.UUID, change entities.StatusChange) error
+	Cancel(ctx context.Context, id uuid.UUID, change entities.StatusChange) (bool, error)
+	RequestRefund(ctx context.Context, id uuid.UUID, refundAmount int64, change entities.StatusChange) (bool, error)
+	RequestLateRefund(ctx context.Context, id uuid.UUID, from entities.TransactionStatus, change entities.StatusChange) (bool, error)
+	AttachRefundRef(ctx context.Context, id uuid.UUID, refundRef string) error
+	CompleteRefund(ctx context.Context, id uuid.UUID, refundRef string, refundedAt time.Time, change entities.StatusChange) (bool, error)
+	CheckIn(ctx context.Context, id uuid.UUID, holderID uuid.UUID, staffID uuid.UUID, change entities.StatusChange) (bool, error)
+	FindStatusHistory(ctx context.Context, id uuid.UUID) ([]entities.TransactionStatusHistory, error)
+	Amend(ctx context.Context, amendment *entities.BookingAmendment, items []entities.TransactionItem, charges []entities.TransactionCharge, status entities.TransactionStatus, discount int64, check *PurchaseCheck) error
+	FindAddons(ctx context.Context, id uuid.UUID) ([]entities.TransactionAddon, error)
+	FindCharges(ctx context.Context, id uuid.UUID) ([]entities.TransactionCharge, error)
+	FindAmendments(ctx context.Context, id uuid.UUID) ([]entities.BookingAmendment, error)
+	FindAmendmentBySettlementRef(ctx context.Context, settlementRef string) (*entities.BookingAmendment, error)
+	AttachAmendmentRef(ctx context.Context, id uuid.UUID, settlementRef string, instructions *entities.PaymentInstructions) error
+	SettleAmendment(ctx context.Context, id uuid.UUID, settlementRef string, settledAt time.Time) (bool, error)
+	FindLapsedAmendments(ctx context.Context, limit int) ([]entities.BookingAmendment, error)
+	ExpireAmendment(ctx context.Context, id uuid.UUID) (bool, error)
+	MarkAmendmentSettlementPending(ctx context.Context, id uuid.UUID) error
+	RequestAmendmentRefund(ctx context.Context, id uuid.UUID, refundAmount int64) ([]uuid.UUID, bool, error)
+	AttachAmendmentRefundRef(ctx context.Context, id uuid.UUID, refundRef string) error
+	CompleteAmendmentRefund(ctx context.Context, id uuid.UUID, refundRef string, refundedAt time.Time) (bool, error)
 }
 
 type BookingRepository struct {
@@ -26,19 +95,55 @@ func NewBookingRepository(db *sql.DB) IBookingRepository {
 	return &BookingRepository{db: db}
 }
 
-func (r *BookingRepository) Create(ctx context.Context, tx *entities.Transaction, items []entities.TransactionItem) error {
+func (r *BookingRepository) Create(ctx context.Context, tx *entities.Transaction, items []entities.TransactionItem, addons []entities.TransactionAddon, charges []entities.TransactionCharge, check *PurchaseCheck) error {
 	dbTx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer dbTx.Rollback()
 
+	if err := checkPurchase(ctx, dbTx, check, tx.UserID, tx.ShowtimeID, uuid.Nil); err != nil {
+		return err
+	}
+
+	seatIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		seatIDs[i] = item.SeatID
+	}
+
+	// Serialize claims per (showtime, seat) so concurrent bookings for the same
+	// seat queue up here and the availability check below sees the winner.
+	if err := lockShowtimeSeats(ctx, dbTx, tx.ShowtimeID, seatIDs); err != nil {
+		return fmt.Errorf("failed to lock seats: %w", err)
+	}
+
+	taken, err := countTakenSeats(ctx, dbTx, tx.ShowtimeID, seatIDs, uuid.Nil)
+	if err != nil {
+		return fmt.Errorf("failed to check seat availability: %w", err)
+	}
+	// Seats held for a waitlisted user are only theirs to book.
+	held, err := countHeldSeats(ctx, dbTx, tx.ShowtimeID, seatIDs, tx.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to check seat holds: %w", err)
+	}
+	blocked, err := countBlockedSeats(ctx, dbTx, tx.ShowtimeID, seatIDs, uuid.Nil)
+	if err != nil {
+		return fmt.Errorf("failed to check seat blocks: %w", err)
+	}
+	if taken > 0 || held > 0 || blocked > 0 {
+		return ErrSeatsTaken
+	}
+
+	if err := reserveAddons(ctx, dbTx, addons); err != nil {
+		return err
+	}
+
 	txQuery := `
-		INSERT INTO transactions (id, status, external_ref, invoice_number, amount, expired_at, payment_method_id, showtime_id, theater_id, user_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO transactions (id, status, external_ref, invoice_number, amount, discount_amount, promotion_id, expired_at, payment_method_id, showtime_id, theater_id, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 	_, err = dbTx.ExecContext(ctx, txQuery,
-		tx.ID, tx.Status, tx.ExternalRef, tx.InvoiceNumber, tx.Amount, tx.ExpiredAt,
+		tx.ID, tx.Status, tx.ExternalRef, tx.InvoiceNumber, tx.Amount, tx.DiscountAmount, tx.PromotionID, tx.ExpiredAt,
 		tx.PaymentMethodID, tx.ShowtimeID, tx.TheaterID, tx.UserID,
 	)
 	if err != nil {
@@ -58,52 +163,110 @@ func (r *BookingRepository) Create(ctx context.Context, tx *entities.Transaction
 		}
 	}
 
+	addonQuery := `
+		INSERT INTO transaction_addons (id, transaction_id, variant_id, quantity, unit_price)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, addon := range addons {
+		_, err = dbTx.ExecContext(ctx, addonQuery,
+			addon.ID, addon.TransactionID, addon.VariantID, addon.Quantity, addon.UnitPrice,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert transaction addon: %w", err)
+		}
+	}
+
+	if err := insertCharges(ctx, dbTx, charges); err != nil {
+		return err
+	}
+
+	if tx.PromotionID != nil {
+		if err := redeemPromotion(ctx, dbTx, tx); err != nil {
+			return err
+		}
+	}
+
+	if err := consumeHolds(ctx, dbTx, tx.ShowtimeID, tx.UserID); err != nil {
+		return fmt.Errorf("failed to release seat holds: %w", err)
+	}
+
+	historyQuery := `
+		INSERT INTO transaction_status_history (transaction_id, from_status, to_status, actor, reason)
+		VALUES ($1, NULL, $2, $3, $4)
+	`
+	_, err = dbTx.ExecContext(ctx, historyQuery, tx.ID, tx.Status, entities.ActorUser(tx.UserID), "booking created")
+	if err != nil {
+		return fmt.Errorf("failed to insert status history: %w", err)
+	}
+
 	return dbTx.Commit()
 }
 
 func (r *BookingRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.Transaction, error) {
 	query := `
 		SELECT 
-			t.id, t.status, t.external_ref, t.invoice_number, t.amount, t.expired_at, t.paid_at,
-			t.payment_method_id, t.showtime_id, t.theater_id, t.user_id,
+			t.id, t.status, t.external_ref, t.invoice_number, t.pickup_code, t.amount, t.discount_amount, t.promotion_id, t.expired_at, t.paid_at,
+			t.cancelled_at, t.refund_amount, t.refund_ref, t.refunded_at, t.checked_in_at, t.checked_in_by,
+			t.payment_method_id, t.showtime_id, t.theater_id, t.user_id, t.payment_instructions,
+			pm.id, pm.code, pm.name, pm.logo_url, pm.payment_method_type_id,
 			s.id, s.time, s.movie_id,
-			m.id, m.title, m.poster_url,
-			th.id, th.name
+			m.id, m.title, m.poster_url, m.duration,
+			th.id, th.name, th.address, th.latitude, th.longitude,
+			p.code
 		FROM transactions t
+		JOIN payment_methods pm ON t.payment_method_id = pm.id
 		JOIN showtimes s ON t.showtime_id = s.id
 		JOIN movies m ON s.movie_id = m.id
 		JOIN theaters th ON t.theater_id = th.id
+		LEFT JOIN promotions p ON t.promotion_id = p.id
 		WHERE t.id = $1
 	`
 
 	var tx entities.Transaction
+	var instructions []byte
+	var method entities.PaymentMethod
 	var showtime entities.Showtime
 	var movie entities.Movie
 	var theater entities.Theater
+	var promoCode sql.NullString
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&tx.ID, &tx.Status, &tx.ExternalRef, &tx.InvoiceNumber, &tx.Amount, &tx.ExpiredAt, &tx.PaidAt,
-		&tx.PaymentMethodID, &tx.ShowtimeID, &tx.TheaterID, &tx.UserID,
+		&tx.ID, &tx.Status, &tx.ExternalRef, &tx.InvoiceNumber, &tx.PickupCode, &tx.Amount, &tx.DiscountAmount, &tx.PromotionID, &tx.ExpiredAt, &tx.PaidAt,
+		&tx.CancelledAt, &tx.RefundAmount, &tx.RefundRef, &tx.RefundedAt, &tx.CheckedInAt, &tx.CheckedInBy,
+		&tx.PaymentMethodID, &tx.ShowtimeID, &tx.TheaterID, &tx.UserID, &instructions,
+		&method.ID, &method.Code, &method.Name, &method.LogoURL, &method.PaymentMethodTypeID,
 		&showtime.ID, &showtime.Time, &showtime.MovieID,
-		&movie.ID, &movie.Title, &movie.PosterURL,
-		&theater.ID, &theater.Name,
+		&movie.ID, &movie.Title, &movie.PosterURL, &movie.Duration,
+		&theater.ID, &theater.Name, &theater.Address, &theater.Latitude, &theater.Longitude,
+		&promoCode,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	if tx.PromotionID != nil && promoCode.Valid {
+		tx.Promotion = &entities.Promotion{ID: *tx.PromotionID, Code: promoCode.String}
+	}
+
+	if tx.PaymentInstructions, err = unmarshalPaymentInstructions(instructions); err != nil {
+		return nil, err
+	}
+
+	tx.PaymentMethod = &method
 	showtime.Movie = &movie
 	tx.Showtime = &showtime
 	tx.Theater = &theater
 
 	itemQuery := `
-		SELECT ti.id, ti.price, ti.seat_id, ti.seat_type_id,
+		SELECT ti.id, ti.price, ti.seat_id, ti.seat_type_id, ti.holder_id, ti.checked_in_at, ti.checked_in_by,
 		       s.id, s.row, s.number,
-		       st.id, st.name
+		       st.id, st.name,
+		       hu.name, hu.email
 		FROM transaction_items ti
 		JOIN seats s ON ti.seat_id = s.id
 		JOIN seat_type st ON ti.seat_type_id = st.id
-		WHERE ti.transaction_id = $1
+		LEFT JOIN users hu ON ti.holder_id = hu.id
+		WHERE ti.transaction_id = $1 AND ti.replaced_at IS NULL
 	`
 
 	rows, err := r.db.QueryContext(ctx, itemQuery, id)
@@ -116,11 +279,13 @@ func (r *BookingRepository) FindByID(ctx context.Context, id uuid.UUID) (*entiti
 		var item entities.TransactionItem
 		var seat entities.Seat
 		var seatType entities.SeatType
+		var holderName, holderEmail sql.NullString
 
 		err := rows.Scan(
-			&item.ID, &item.Price, &item.SeatID, &item.SeatTypeID,
+			&item.ID, &item.Price, &item.SeatID, &item.SeatTypeID, &item.HolderID, &item.CheckedInAt, &item.CheckedInBy,
 			&seat.ID, &seat.Row, &seat.Number,
 			&seatType.ID, &seatType.Name,
+			&holderName, &holderEmail,
 		)
 		if err != nil {
 			return nil, err
@@ -128,13 +293,69 @@ func (r *BookingRepository) FindByID(ctx context.Context, id uuid.UUID) (*entiti
 
 		seat.SeatType = &seatType
 		item.Seat = &seat
+		if item.HolderID != nil {
+			item.Holder = &entities.User{ID: *item.HolderID, Name: holderName.String, Email: holderEmail.String}
+		}
 		tx.Items = append(tx.Items, item)
 	}
 
+	if tx.Addons, err = r.FindAddons(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if tx.Charges, err = r.FindCharges(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if tx.Amendments, err = r.FindAmendments(ctx, id); err != nil {
+		return nil, err
+	}
+
 	return &tx, nil
 }
 
-func (r *BookingRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Transaction, error) {
+// FindByUserID returns a page of a user's bookings matching the filter, sorted
+// by showtime, along with how many match in total.
+func (r *BookingRepository) FindByUserID(ctx context.Context, userID uuid.UUID, filter BookingFilter) ([]entities.Transaction, int, error) {
+	where := ` WHERE t.user_id = $1`
+	args := []interface{}{userID}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		args = append(args, pq.Array(statuses))
+		where += fmt.Sprintf(` AND t.status = ANY($%d)`, len(args))
+	}
+	if filter.Upcoming != nil {
+		if *filter.Upcoming {
+			where += ` AND s.time > NOW()`
+		} else {
+			where += ` AND s.time <= NOW()`
+		}
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where += fmt.Sprintf(` AND s.time >= $%d`, len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where += fmt.Sprintf(` AND s.time < $%d`, len(args))
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM transactions t JOIN showtimes s ON t.showtime_id = s.id` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	order := "DESC"
+	if filter.Ascending {
+		order = "ASC"
+	}
+
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
 	query := `
 		SELECT 
 			t.id, t.status, t.amount, t.expired_at, t.paid_at,
@@ -144,14 +365,13 @@ func (r *BookingRepository) FindByUserID(ctx context.Context, userID uuid.UUID)
 		FROM transactions t
 		JOIN showtimes s ON t.showtime_id = s.id
 		JOIN movies m ON s.movie_id = m.id
-		JOIN theaters th ON t.theater_id = th.id
-		WHERE t.user_id = $1
-		ORDER BY t.expired_at DESC
-	`
+		JOIN theaters th ON t.theater_id = th.id` + where + `
+		ORDER BY s.time ` + order + `, t.id
+		LIMIT $` + fmt.Sprint(len(args)-1) + ` OFFSET $` + fmt.Sprint(len(args))
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -169,7 +389,7 @@ func (r *BookingRepository) FindByUserID(ctx context.Context, userID uuid.UUID)
 			&theater.ID, &theater.Name,
 		)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		showtime.Movie = &movie
@@ -178,7 +398,7 @@ func (r *BookingRepository) FindByUserID(ctx context.Context, userID uuid.UUID)
 		transactions = append(transactions, tx)
 	}
 
-	return transactions, nil
+	return transactions, total, rows.Err()
 }
 
 func (r *BookingRepository) CheckSeatsAvailable(ctx context.Context, showtimeID uuid.UUID, seatIDs []uuid.UUID) (bool, error) {
@@ -186,27 +406,1104 @@ func (r *BookingRepository) CheckSeatsAvailable(ctx context.Context, showtimeID
 		return false, nil
 	}
 
-	// Check if any of the seats are already booked for this showtime
+	count, err := countTakenSeats(ctx, r.db, showtimeID, seatIDs, uuid.Nil)
+	if err != nil {
+		return false, err
+	}
+
+	held, err := countHeldSeats(ctx, r.db, showtimeID, seatIDs, uuid.Nil)
+	if err != nil {
+		return false, err
+	}
+
+	blocked, err := countBlockedSeats(ctx, r.db, showtimeID, seatIDs, uuid.Nil)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 0 && held == 0 && blocked == 0, nil
+}
+
+// ExpirePending moves up to limit pending transactions whose payment window has
+// lapsed to the expired status and returns them with the seats they held.
+// Rows locked by a concurrent sweep or payment are skipped and picked up on the
+// next pass.
+func (r *BookingRepository) ExpirePending(ctx context.Context, limit int) ([]entities.Transaction, error) {
 	query := `
-		SELECT COUNT(*) FROM transaction_items ti
-		JOIN transactions t ON ti.transaction_id = t.id
-		WHERE t.showtime_id = $1 
-		AND t.status IN ('pending', 'paid')
-		AND t.expired_at > $2
-		AND ti.seat_id = ANY($3)
+		WITH expired AS (
+			UPDATE transactions SET status = $3
+			WHERE id IN (
+				SELECT id FROM transactions
+				WHERE status = $2 AND expired_at <= NOW()
+				ORDER BY expired_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, status, expired_at, showtime_id, user_id
+		), history AS (
+			INSERT INTO transaction_status_history (transaction_id, from_status, to_status, actor, reason)
+			SELECT id, $2, $3, $4, 'payment window lapsed' FROM expired
+		)
+		SELECT e.id, e.status, e.expired_at, e.showtime_id, e.user_id,
+		       ARRAY(SELECT ti.seat_id FROM transaction_items ti WHERE ti.transaction_id = e.id AND ti.replaced_at IS NULL)
+		FROM expired e
 	`
 
-	// Convert UUID slice to string slice for pq.Array
-	seatIDStrings := make([]string, len(seatIDs))
-	for i, id := range seatIDs {
-		seatIDStrings[i] = id.String()
+	rows, err := r.db.QueryContext(ctx, query, limit,
+		entities.TransactionStatusPending, entities.TransactionStatusExpired, entities.ActorSystem,
+	)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	var count int
-	err := r.db.QueryRowContext(ctx, query, showtimeID, time.Now(), pq.Array(seatIDStrings)).Scan(&count)
+	var expired []entities.Transaction
+	for rows.Next() {
+		var tx entities.Transaction
+		var seatIDs []string
+		if err := rows.Scan(&tx.ID, &tx.Status, &tx.ExpiredAt, &tx.ShowtimeID, &tx.UserID, pq.Array(&seatIDs)); err != nil {
+			return nil, err
+		}
+		for _, id := range seatIDs {
+			seatID, err := uuid.Parse(id)
+			if err != nil {
+				return nil, err
+			}
+			tx.Items = append(tx.Items, entities.TransactionItem{TransactionID: tx.ID, SeatID: seatID})
+		}
+		expired = append(expired, tx)
+	}
+
+	return expired, rows.Err()
+}
+
+func (r *BookingRepository) FindByExternalRef(ctx context.Context, externalRef string) (*entities.Transaction, error) {
+	query := `
+		SELECT id, status, external_ref, amount, expired_at, paid_at,
+		       payment_method_id, showtime_id, theater_id, user_id
+		FROM transactions
+		WHERE external_ref = $1
+	`
+
+	var tx entities.Transaction
+	err := r.db.QueryRowContext(ctx, query, externalRef).Scan(
+		&tx.ID, &tx.Status, &tx.ExternalRef, &tx.Amount, &tx.ExpiredAt, &tx.PaidAt,
+		&tx.PaymentMethodID, &tx.ShowtimeID, &tx.TheaterID, &tx.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tx, nil
+}
+
+// MarkPaid settles a pending transaction and issues its invoice number for the
+// given period (yyyyMM). Its concessions are taken out of stock and get a
+// pickup code. It reports false when the transaction is no longer payable, e.g.
+// it expired before the payment landed; no number is used then.
+func (r *BookingRepository) MarkPaid(ctx context.Context, id uuid.UUID, paidAt time.Time, invoicePeriod string, change entities.StatusChange) (bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	ok, err := transition(ctx, dbTx, statusTransition{
+		id:     id,
+		from:   entities.TransactionStatusPending,
+		to:     entities.TransactionStatusPaid,
+		change: change,
+		set:    "paid_at = $6",
+		where:  "expired_at > NOW()",
+		args:   []any{paidAt},
+	})
+	if err != nil || !ok {
+		return false, err
+	}
+
+	var theaterID uuid.UUID
+	if err := dbTx.QueryRowContext(ctx, `SELECT theater_id FROM transactions WHERE id = $1`, id).Scan(&theaterID); err != nil {
+		return false, err
+	}
+
+	invoiceNumber, err := nextInvoiceNumber(ctx, dbTx, theaterID, invoicePeriod)
+	if err != nil {
+		return false, fmt.Errorf("failed to issue invoice number: %w", err)
+	}
+
+	_, err = dbTx.ExecContext(ctx, `UPDATE transactions SET invoice_number = $2 WHERE id = $1`, id, invoiceNumber)
+	if err != nil {
+		return false, err
+	}
+
+	if err := deductAddonStock(ctx, dbTx, id); err != nil {
+		return false, fmt.Errorf("failed to deduct concession stock: %w", err)
+	}
+
+	pickupCode, err := newPickupCode()
+	if err != nil {
+		return false, err
+	}
+	_, err = dbTx.ExecContext(ctx,
+		`UPDATE transactions SET pickup_code = $2 WHERE id = $1 AND EXISTS (SELECT 1 FROM transaction_addons WHERE transaction_id = $1)`,
+		id, pickupCode,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to issue pickup code: %w", err)
+	}
+
+	return true, dbTx.Commit()
+}
+
+// AttachCharge records the provider reference and payment instructions once a
+// charge has been opened for the transaction.
+func (r *BookingRepository) AttachCharge(ctx context.Context, id uuid.UUID, externalRef string, instructions *entities.PaymentInstructions) error {
+	raw, err := marshalPaymentInstructions(instructions)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE transactions SET external_ref = $2, payment_instructions = $3 WHERE id = $1`
+	_, err = r.db.ExecContext(ctx, query, id, externalRef, raw)
+	return err
+}
+
+// Expire releases a single pending transaction right away.
+func (r *BookingRepository) Expire(ctx context.Context, id uuid.UUID, change entities.StatusChange) error {
+	_, err := transition(ctx, r.db, statusTransition{
+		id:     id,
+		from:   entities.TransactionStatusPending,
+		to:     entities.TransactionStatusExpired,
+		change: change,
+	})
+	return err
+}
+
+// Cancel releases a pending transaction at the customer's request. It reports
+// false when the transaction is no longer pending.
+func (r *BookingRepository) Cancel(ctx context.Context, id uuid.UUID, change entities.StatusChange) (bool, error) {
+	return transition(ctx, r.db, statusTransition{
+		id:     id,
+		from:   entities.TransactionStatusPending,
+		to:     entities.TransactionStatusCancelled,
+		change: change,
+		set:    "cancelled_at = NOW()",
+	})
+}
+
+// RequestRefund cancels a paid transaction and records the amount owed back to
+// the customer, unless one of its seats was transferred or admitted. The seats
+// are released as soon as the status leaves paid, and its concessions go back
+// into stock.
+func (r *BookingRepository) RequestRefund(ctx context.Context, id uuid.UUID, refundAmount int64, change entities.StatusChange) (bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	// Transfers and check-ins lock the transaction too, so once the lock is held
+	// the seat check below sees every hand-over committed before it.
+	_, err = dbTx.ExecContext(ctx, `SELECT 1 FROM transactions WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := transition(ctx, dbTx, statusTransition{
+		id:     id,
+		from:   entities.TransactionStatusPaid,
+		to:     entities.TransactionStatusRefundPending,
+		change: change,
+		set:    "cancelled_at = NOW(), refund_amount = $6",
+		where: `NOT EXISTS (
+			SELECT 1 FROM transaction_items ti
+			WHERE ti.transaction_id = transactions.id AND ti.replaced_at IS NULL
+			AND (ti.holder_id IS NOT NULL OR ti.checked_in_at IS NOT NULL)
+		)`,
+		args: []any{refundAmount},
+	})
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if err := restoreAddonStock(ctx, dbTx, id); err != nil {
+		return false, fmt.Errorf("failed to restore concession stock: %w", err)
+	}
+
+	return true, dbTx.Commit()
+}
+
+// RequestLateRefund records that an expired or cancelled transaction was paid
+// after all, and owes its whole amount back. It reports false when the
+// transaction is no longer in status from.
+func (r *BookingRepository) RequestLateRefund(ctx context.Context, id uuid.UUID, from entities.TransactionStatus, change entities.StatusChange) (bool, error) {
+	return transition(ctx, r.db, statusTransition{
+		id:     id,
+		from:   from,
+		to:     entities.TransactionStatusRefundPending,
+		change: change,
+		set:    "refund_amount = amount",
+	})
+}
+
+// AttachRefundRef records the provider reference of a refund that is still
+// being processed.
+func (r *BookingRepository) AttachRefundRef(ctx context.Context, id uuid.UUID, refundRef string) error {
+	query := `UPDATE transactions SET refund_ref = $2 WHERE id = $1 AND status = $3`
+	_, err := r.db.ExecContext(ctx, query, id, refundRef, entities.TransactionStatusRefundPending)
+	return err
+}
+
+// CompleteRefund marks a refund as settled by the provider. It reports false
+// when the transaction has no refund in flight.
+func (r *BookingRepository) CompleteRefund(ctx context.Context, id uuid.UUID, refundRef string, refundedAt time.Time, change entities.StatusChange) (bool, error) {
+	return transition(ctx, r.db, statusTransition{
+		id:     id,
+		from:   entities.TransactionStatusRefundPending,
+		to:     entities.TransactionStatusRefunded,
+		change: change,
+		set:    "refund_ref = COALESCE(NULLIF($6, ''), refund_ref), refunded_at = $7",
+		args:   []any{refundRef, refundedAt},
+	})
+}
+
+// CheckIn admits the seats of a paid transaction held by holderID, i.e. the
+// seats never transferred away when holderID is the owner. Once every seat is
+// in, the transaction is marked used. It reports false when the holder has no
+// seat left to admit or the transaction is not paid anymore.
+func (r *BookingRepository) CheckIn(ctx context.Context, id uuid.UUID, holderID uuid.UUID, staffID uuid.UUID, change entities.StatusChange) (bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	var status entities.TransactionStatus
+	var ownerID uuid.UUID
+	err = dbTx.QueryRowContext(ctx,
+		`SELECT status, user_id FROM transactions WHERE id = $1 FOR UPDATE`, id,
+	).Scan(&status, &ownerID)
+	if err != nil {
+		return false, err
+	}
+	if status != entities.TransactionStatusPaid {
+		return false, nil
+	}
+
+	res, err := dbTx.ExecContext(ctx, `
+		UPDATE transaction_items SET checked_in_at = NOW(), checked_in_by = $3
+		WHERE transaction_id = $1 AND replaced_at IS NULL AND checked_in_at IS NULL
+		AND COALESCE(holder_id, $4) = $2
+	`, id, holderID, staffID, ownerID)
+	if err != nil {
+		return false, err
+	}
+	admitted, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if admitted == 0 {
+		return false, nil
+	}
+
+	var waiting int
+	err = dbTx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM transaction_items WHERE transaction_id = $1 AND replaced_at IS NULL AND checked_in_at IS NULL`, id,
+	).Scan(&waiting)
+	if err != nil {
+		return false, err
+	}
+
+	if waiting == 0 {
+		_, err := transition(ctx, dbTx, statusTransition{
+			id:     id,
+			from:   entities.TransactionStatusPaid,
+			to:     entities.TransactionStatusUsed,
+			change: change,
+			set:    "checked_in_at = NOW(), checked_in_by = $6",
+			args:   []any{staffID},
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, dbTx.Commit()
+}
+
+// FindStatusHistory returns every status change of a transaction, oldest
+// first.
+func (r *BookingRepository) FindStatusHistory(ctx context.Context, id uuid.UUID) ([]entities.TransactionStatusHistory, error) {
+	query := `
+		SELECT id, transaction_id, from_status, to_status, actor, reason, created_at
+		FROM transaction_status_history
+		WHERE transaction_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []entities.TransactionStatusHistory
+	for rows.Next() {
+		var h entities.TransactionStatusHistory
+		err := rows.Scan(&h.ID, &h.TransactionID, &h.FromStatus, &h.ToStatus, &h.Actor, &h.Reason, &h.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
+
+// statusTransition is a single guarded status change. set and where extend
+// the UPDATE with extra assignments and conditions; their placeholders start
+// at $6 and are bound from args.
+type statusTransition struct {
+	id     uuid.UUID
+	from   entities.TransactionStatus
+	to     entities.TransactionStatus
+	change entities.StatusChange
+	set    string
+	where  string
+	args   []any
+}
+
+// transition moves a transaction from one status to another and records the
+// change in its history in the same statement. It reports false when the
+// transaction was not in the expected status, or failed the extra conditions.
+func transition(ctx context.Context, q queryer, t statusTransition) (bool, error) {
+	if !t.from.CanTransitionTo(t.to) {
+		return false, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, t.from, t.to)
+	}
+
+	set := "status = $2"
+	if t.set != "" {
+		set += ", " + t.set
+	}
+	where := "id = $1 AND status = $3"
+	if t.where != "" {
+		where += " AND " + t.where
+	}
+
+	query := `
+		WITH changed AS (
+			UPDATE transactions SET ` + set + `
+			WHERE ` + where + `
+			RETURNING id
+		)
+		INSERT INTO transaction_status_history (transaction_id, from_status, to_status, actor, reason)
+		SELECT id, $3, $2, $4, $5 FROM changed
+	`
+
+	args := append([]any{t.id, t.to, t.from, t.change.Actor, t.change.Reason}, t.args...)
+	res, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
 	if err != nil {
 		return false, err
 	}
 
-	return count == 0, nil
+	return affected == 1, nil
+}
+
+// PurchaseCheck vets a user's booking activity before a booking is written.
+type PurchaseCheck struct {
+	// Since is where the window of UserBookingActivity.RecentBookings starts.
+	Since time.Time
+	// Check returns the error to refuse the booking with, if any.
+	Check func(activity *UserBookingActivity) error
+}
+
+// checkPurchase runs the check against the user's booking activity, leaving
+// out the booking excludeID. A per-user advisory lock is taken first and held
+// until the transaction ends, so concurrent bookings by the same user are
+// counted one after another and can never overshoot a limit together.
+func checkPurchase(ctx context.Context, q queryer, check *PurchaseCheck, userID uuid.UUID, showtimeID uuid.UUID, excludeID uuid.UUID) error {
+	if check == nil {
+		return nil
+	}
+
+	if _, err := q.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, userID.String()); err != nil {
+		return fmt.Errorf("failed to lock user bookings: %w", err)
+	}
+
+	activity, err := findUserActivity(ctx, q, userID, showtimeID, excludeID, check.Since)
+	if err != nil {
+		return fmt.Errorf("failed to get booking activity: %w", err)
+	}
+
+	return check.Check(activity)
+}
+
+// findUserActivity counts a user's live seats for a showtime, open pending
+// bookings and bookings created since the given time. The booking excludeID is
+// left out of the first two; pass uuid.Nil to count them all.
+func findUserActivity(ctx context.Context, q queryer, userID uuid.UUID, showtimeID uuid.UUID, excludeID uuid.UUID, since time.Time) (*UserBookingActivity, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM transaction_items ti
+			 JOIN transactions t ON ti.transaction_id = t.id
+			 WHERE t.user_id = $1 AND t.showtime_id = $2 AND t.id <> $3
+			 AND ti.replaced_at IS NULL AND ` + seatHoldingCondition + `),
+			(SELECT COUNT(*) FROM transactions t
+			 WHERE t.user_id = $1 AND t.id <> $3 AND ` + openPendingCondition + `),
+			(SELECT COUNT(*) FROM transaction_status_history h
+			 JOIN transactions t ON h.transaction_id = t.id
+			 WHERE t.user_id = $1 AND h.from_status IS NULL AND h.created_at >= $4)
+	`
+
+	var activity UserBookingActivity
+	err := q.QueryRowContext(ctx, query, userID, showtimeID, excludeID, since).Scan(
+		&activity.ShowtimeSeats, &activity.PendingBookings, &activity.RecentBookings,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &activity, nil
+}
+
+// Amend swaps the seats of a transaction, possibly onto another showtime, as
+// long as it is still in the given status and showtime. The current items are
+// kept but marked replaced; its fees and taxes are replaced outright. When the
+// difference is still to be charged nothing moves yet: the new seats are held
+// for the transaction until the amendment expires, and SettleAmendment swaps
+// them in once it is paid. A non-nil check vets the user's bookings first.
+func (r *BookingRepository) Amend(ctx context.Context, amendment *entities.BookingAmendment, items []entities.TransactionItem, charges []entities.TransactionCharge, status entities.TransactionStatus, discount int64, check *PurchaseCheck) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	var current entities.TransactionStatus
+	var showtimeID, userID uuid.UUID
+	var expiredAt time.Time
+	err = dbTx.QueryRowContext(ctx,
+		`SELECT status, showtime_id, user_id, expired_at FROM transactions WHERE id = $1 FOR UPDATE`,
+		amendment.TransactionID,
+	).Scan(&current, &showtimeID, &userID, &expiredAt)
+	if err != nil {
+		return err
+	}
+	if current != status || showtimeID != amendment.FromShowtimeID {
+		return ErrTransactionChanged
+	}
+	if current == entities.TransactionStatusPending && !expiredAt.After(time.Now()) {
+		return ErrTransactionChanged
+	}
+
+	if err := checkPurchase(ctx, dbTx, check, userID, amendment.ToShowtimeID, amendment.TransactionID); err != nil {
+		return err
+	}
+
+	seatIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		seatIDs[i] = item.SeatID
+	}
+
+	if err := lockShowtimeSeats(ctx, dbTx, amendment.ToShowtimeID, seatIDs); err != nil {
+		return fmt.Errorf("failed to lock seats: %w", err)
+	}
+
+	// The transaction's own seats don't count, so seats can be kept or shuffled.
+	taken, err := countTakenSeats(ctx, dbTx, amendment.ToShowtimeID, seatIDs, amendment.TransactionID)
+	if err != nil {
+		return fmt.Errorf("failed to check seat availability: %w", err)
+	}
+	held, err := countHeldSeats(ctx, dbTx, amendment.ToShowtimeID, seatIDs, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check seat holds: %w", err)
+	}
+	blocked, err := countBlockedSeats(ctx, dbTx, amendment.ToShowtimeID, seatIDs, amendment.TransactionID)
+	if err != nil {
+		return fmt.Errorf("failed to check seat blocks: %w", err)
+	}
+	if taken > 0 || held > 0 || blocked > 0 {
+		return ErrSeatsTaken
+	}
+
+	if err := consumeHolds(ctx, dbTx, amendment.ToShowtimeID, userID); err != nil {
+		return fmt.Errorf("failed to release seat holds: %w", err)
+	}
+
+	changes := amendmentChanges{Items: items, Charges: charges, Discount: discount}
+	charged := amendment.Settlement == entities.AmendmentSettlementCharge
+
+	var raw sql.NullString
+	if charged {
+		encoded, err := json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+		raw = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	amendmentQuery := `
+		INSERT INTO booking_amendments (id, transaction_id, from_showtime_id, to_showtime_id, previous_amount, new_amount, difference, settlement, changes, settled_at, expires_at, actor)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at
+	`
+	err = dbTx.QueryRowContext(ctx, amendmentQuery,
+		amendment.ID, amendment.TransactionID, amendment.FromShowtimeID, amendment.ToShowtimeID,
+		amendment.PreviousAmount, amendment.NewAmount, amendment.Difference, amendment.Settlement,
+		raw, amendment.SettledAt, amendment.ExpiresAt, amendment.Actor,
+	).Scan(&amendment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert amendment: %w", err)
+	}
+
+	if charged {
+		if amendment.ExpiresAt == nil {
+			return errors.New("charged amendment has no expiry")
+		}
+		holdQuery := `
+			INSERT INTO seat_holds (id, showtime_id, seat_id, user_id, amendment_id, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		for _, seatID := range seatIDs {
+			_, err = dbTx.ExecContext(ctx, holdQuery,
+				uuid.New(), amendment.ToShowtimeID, seatID, userID, amendment.ID, *amendment.ExpiresAt,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to hold seats: %w", err)
+			}
+		}
+		amendment.Items = items
+	} else if err := applyAmendment(ctx, dbTx, amendment.TransactionID, amendment.ToShowtimeID, amendment.NewAmount, changes); err != nil {
+		return err
+	}
+
+	return dbTx.Commit()
+}
+
+// amendmentChanges is what an amendment does to its transaction, kept on the
+// amendment while its difference is outstanding.
+type amendmentChanges struct {
+	Items    []entities.TransactionItem   `json:"items"`
+	Charges  []entities.TransactionCharge `json:"charges"`
+	Discount int64                        `json:"discount"`
+}
+
+// applyAmendment moves a transaction onto the items and charges of an
+// amendment, marking its current items replaced.
+func applyAmendment(ctx context.Context, q queryer, transactionID uuid.UUID, showtimeID uuid.UUID, amount int64, changes amendmentChanges) error {
+	_, err := q.ExecContext(ctx,
+		`UPDATE transaction_items SET replaced_at = NOW() WHERE transaction_id = $1 AND replaced_at IS NULL`,
+		transactionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to replace transaction items: %w", err)
+	}
+
+	itemQuery := `
+		INSERT INTO transaction_items (id, price, transaction_id, seat_id, seat_type_id)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, item := range changes.Items {
+		_, err = q.ExecContext(ctx, itemQuery,
+			item.ID, item.Price, item.TransactionID, item.SeatID, item.SeatTypeID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert transaction item: %w", err)
+		}
+	}
+
+	_, err = q.ExecContext(ctx, `DELETE FROM transaction_charges WHERE transaction_id = $1`, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to replace transaction charges: %w", err)
+	}
+	if err := insertCharges(ctx, q, changes.Charges); err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(ctx,
+		`UPDATE transactions SET showtime_id = $2, amount = $3, discount_amount = $4 WHERE id = $1`,
+		transactionID, showtimeID, amount, changes.Discount,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	return nil
+}
+
+// FindAddons returns the concession lines of a transaction with their variant
+// and item names.
+func (r *BookingRepository) FindAddons(ctx context.Context, id uuid.UUID) ([]entities.TransactionAddon, error) {
+	query := `
+		SELECT a.id, a.transaction_id, a.variant_id, a.quantity, a.unit_price, a.stock_deducted_at, a.stock_restored_at,
+		       v.id, v.name, ci.id, ci.name, ci.category
+		FROM transaction_addons a
+		JOIN concession_variants v ON a.variant_id = v.id
+		JOIN concession_items ci ON v.item_id = ci.id
+		WHERE a.transaction_id = $1
+		ORDER BY ci.category, ci.name, v.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var addons []entities.TransactionAddon
+	for rows.Next() {
+		var a entities.TransactionAddon
+		var v entities.ConcessionVariant
+		var ci entities.ConcessionItem
+		err := rows.Scan(
+			&a.ID, &a.TransactionID, &a.VariantID, &a.Quantity, &a.UnitPrice, &a.StockDeductedAt, &a.StockRestoredAt,
+			&v.ID, &v.Name, &ci.ID, &ci.Name, &ci.Category,
+		)
+		if err != nil {
+			return nil, err
+		}
+		v.Item = &ci
+		a.Variant = &v
+		addons = append(addons, a)
+	}
+
+	return addons, rows.Err()
+}
+
+// FindCharges returns the fee and tax lines of a transaction, taxes last.
+func (r *BookingRepository) FindCharges(ctx context.Context, id uuid.UUID) ([]entities.TransactionCharge, error) {
+	query := `
+		SELECT id, transaction_id, kind, name, amount, fee_rule_id
+		FROM transaction_charges
+		WHERE transaction_id = $1
+		ORDER BY kind = 'tax', kind, name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var charges []entities.TransactionCharge
+	for rows.Next() {
+		var c entities.TransactionCharge
+		if err := rows.Scan(&c.ID, &c.TransactionID, &c.Kind, &c.Name, &c.Amount, &c.FeeRuleID); err != nil {
+			return nil, err
+		}
+		charges = append(charges, c)
+	}
+
+	return charges, rows.Err()
+}
+
+func insertCharges(ctx context.Context, q queryer, charges []entities.TransactionCharge) error {
+	query := `
+		INSERT INTO transaction_charges (id, transaction_id, kind, name, amount, fee_rule_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	for _, c := range charges {
+		_, err := q.ExecContext(ctx, query, c.ID, c.TransactionID, c.Kind, c.Name, c.Amount, c.FeeRuleID)
+		if err != nil {
+			return fmt.Errorf("failed to insert transaction charge: %w", err)
+		}
+	}
+	return nil
+}
+
+// FindAmendments returns the amendments of a transaction, oldest first.
+func (r *BookingRepository) FindAmendments(ctx context.Context, id uuid.UUID) ([]entities.BookingAmendment, error) {
+	query := `
+		SELECT ` + amendmentColumns + `
+		FROM booking_amendments
+		WHERE transaction_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var amendments []entities.BookingAmendment
+	for rows.Next() {
+		amendment, err := scanAmendment(rows)
+		if err != nil {
+			return nil, err
+		}
+		amendments = append(amendments, *amendment)
+	}
+
+	return amendments, rows.Err()
+}
+
+func (r *BookingRepository) FindAmendmentBySettlementRef(ctx context.Context, settlementRef string) (*entities.BookingAmendment, error) {
+	query := `
+		SELECT ` + amendmentColumns + `
+		FROM booking_amendments
+		WHERE settlement_ref = $1
+	`
+
+	return scanAmendment(r.db.QueryRowContext(ctx, query, settlementRef))
+}
+
+// AttachAmendmentRef records the provider reference of the charge or refund
+// settling an amendment, with payment instructions for charges.
+func (r *BookingRepository) AttachAmendmentRef(ctx context.Context, id uuid.UUID, settlementRef string, instructions *entities.PaymentInstructions) error {
+	raw, err := marshalPaymentInstructions(instructions)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE booking_amendments SET settlement_ref = $2, payment_instructions = $3 WHERE id = $1 AND settled_at IS NULL`
+	_, err = r.db.ExecContext(ctx, query, id, settlementRef, raw)
+	return err
+}
+
+// SettleAmendment marks the difference of an amendment as paid or refunded.
+// Once a charge is paid the transaction moves onto the seats held for it. It
+// reports false when the amendment was already settled or has expired.
+func (r *BookingRepository) SettleAmendment(ctx context.Context, id uuid.UUID, settlementRef string, settledAt time.Time) (bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	query := `
+		UPDATE booking_amendments
+		SET settled_at = $2, settlement_ref = COALESCE(NULLIF($3, ''), settlement_ref)
+		WHERE id = $1 AND settled_at IS NULL AND expired_at IS NULL
+		RETURNING transaction_id, from_showtime_id, to_showtime_id, new_amount, difference, settlement, changes
+	`
+
+	var transactionID, fromShowtimeID, toShowtimeID uuid.UUID
+	var newAmount, difference int64
+	var settlement string
+	var raw []byte
+	err = dbTx.QueryRowContext(ctx, query, id, settledAt, settlementRef).Scan(
+		&transactionID, &fromShowtimeID, &toShowtimeID, &newAmount, &difference, &settlement, &raw,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if settlement != entities.AmendmentSettlementCharge {
+		return true, dbTx.Commit()
+	}
+	var changes amendmentChanges
+	if err := json.Unmarshal(raw, &changes); err != nil {
+		return false, err
+	}
+
+	var status entities.TransactionStatus
+	var showtimeID, userID uuid.UUID
+	err = dbTx.QueryRowContext(ctx,
+		`SELECT status, showtime_id, user_id FROM transactions WHERE id = $1 FOR UPDATE`,
+		transactionID,
+	).Scan(&status, &showtimeID, &userID)
+	if err != nil {
+		return false, err
+	}
+	if status != entities.TransactionStatusPaid || showtimeID != fromShowtimeID {
+		return false, ErrTransactionChanged
+	}
+
+	_, err = dbTx.ExecContext(ctx, `UPDATE seat_holds SET released_at = NOW() WHERE amendment_id = $1 AND released_at IS NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to release seat holds: %w", err)
+	}
+
+	seatIDs := make([]uuid.UUID, len(changes.Items))
+	for i, item := range changes.Items {
+		seatIDs[i] = item.SeatID
+	}
+
+	// With the holds gone the seats are checked like any others; they can only
+	// be lost to a staff block or to a payment arriving after the hold lapsed.
+	if err := lockShowtimeSeats(ctx, dbTx, toShowtimeID, seatIDs); err != nil {
+		return false, fmt.Errorf("failed to lock seats: %w", err)
+	}
+	taken, err := countTakenSeats(ctx, dbTx, toShowtimeID, seatIDs, transactionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check seat availability: %w", err)
+	}
+	held, err := countHeldSeats(ctx, dbTx, toShowtimeID, seatIDs, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check seat holds: %w", err)
+	}
+	blocked, err := countBlockedSeats(ctx, dbTx, toShowtimeID, seatIDs, transactionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check seat blocks: %w", err)
+	}
+	if taken > 0 || held > 0 || blocked > 0 {
+		return false, ErrSeatsTaken
+	}
+
+	if err := applyAmendment(ctx, dbTx, transactionID, toShowtimeID, newAmount, changes); err != nil {
+		return false, err
+	}
+
+	return true, dbTx.Commit()
+}
+
+// FindLapsedAmendments returns up to limit amendments whose difference was not
+// paid before they expired and that still hold seats, oldest first. Those
+// waiting for a payment already made are left out.
+func (r *BookingRepository) FindLapsedAmendments(ctx context.Context, limit int) ([]entities.BookingAmendment, error) {
+	query := `
+		SELECT ` + amendmentColumns + `
+		FROM booking_amendments
+		WHERE settled_at IS NULL AND expired_at IS NULL AND settlement_pending_at IS NULL AND expires_at <= NOW()
+		ORDER BY expires_at
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var amendments []entities.BookingAmendment
+	for rows.Next() {
+		amendment, err := scanAmendment(rows)
+		if err != nil {
+			return nil, err
+		}
+		amendments = append(amendments, *amendment)
+	}
+
+	return amendments, rows.Err()
+}
+
+// ExpireAmendment gives up on an unpaid amendment and releases the seats held
+// for it; the transaction keeps the seats it had. It reports false when the
+// amendment was settled or expired in the meantime.
+func (r *BookingRepository) ExpireAmendment(ctx context.Context, id uuid.UUID) (bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	result, err := dbTx.ExecContext(ctx,
+		`UPDATE booking_amendments SET expired_at = NOW() WHERE id = $1 AND settled_at IS NULL AND expired_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	_, err = dbTx.ExecContext(ctx, `UPDATE seat_holds SET released_at = NOW() WHERE amendment_id = $1 AND released_at IS NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to release seat holds: %w", err)
+	}
+
+	return true, dbTx.Commit()
+}
+
+// MarkAmendmentSettlementPending records that a lapsed amendment's charge was
+// paid too late to be cancelled, so the payment webhook settles or refunds it
+// instead of the expiry sweep.
+func (r *BookingRepository) MarkAmendmentSettlementPending(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE booking_amendments SET settlement_pending_at = NOW() WHERE id = $1 AND settled_at IS NULL AND expired_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// RequestAmendmentRefund gives up on a charged amendment whose payment can't be
+// applied and records the refund owed for it. The seats held for it are
+// released; those whose hold had not lapsed yet are returned. It reports false
+// when the amendment was settled or its refund was already requested.
+func (r *BookingRepository) RequestAmendmentRefund(ctx context.Context, id uuid.UUID, refundAmount int64) ([]uuid.UUID, bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer dbTx.Rollback()
+
+	result, err := dbTx.ExecContext(ctx, `
+		UPDATE booking_amendments
+		SET refund_amount = $2, expired_at = COALESCE(expired_at, NOW())
+		WHERE id = $1 AND settlement = $3 AND settled_at IS NULL AND refund_amount IS NULL
+	`, id, refundAmount, entities.AmendmentSettlementCharge)
+	if err != nil {
+		return nil, false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return nil, false, err
+	}
+
+	rows, err := dbTx.QueryContext(ctx, `
+		UPDATE seat_holds h SET released_at = NOW()
+		WHERE h.amendment_id = $1 AND h.released_at IS NULL
+		RETURNING h.seat_id, h.expires_at > NOW()
+	`, id)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to release seat holds: %w", err)
+	}
+	defer rows.Close()
+
+	var released []uuid.UUID
+	for rows.Next() {
+		var seatID uuid.UUID
+		var active bool
+		if err := rows.Scan(&seatID, &active); err != nil {
+			return nil, false, err
+		}
+		if active {
+			released = append(released, seatID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	return released, true, dbTx.Commit()
+}
+
+// AttachAmendmentRefundRef records the provider reference of a refund that
+// settles later.
+func (r *BookingRepository) AttachAmendmentRefundRef(ctx context.Context, id uuid.UUID, refundRef string) error {
+	query := `UPDATE booking_amendments SET refund_ref = $2 WHERE id = $1 AND refund_amount IS NOT NULL AND refunded_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, id, refundRef)
+	return err
+}
+
+// CompleteAmendmentRefund marks the refund of an amendment's payment as done.
+// It reports false when no refund is pending.
+func (r *BookingRepository) CompleteAmendmentRefund(ctx context.Context, id uuid.UUID, refundRef string, refundedAt time.Time) (bool, error) {
+	query := `
+		UPDATE booking_amendments
+		SET refunded_at = $3, refund_ref = COALESCE(NULLIF($2, ''), refund_ref)
+		WHERE id = $1 AND refund_amount IS NOT NULL AND refunded_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, id, refundRef, refundedAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+const amendmentColumns = `id, transaction_id, from_showtime_id, to_showtime_id, previous_amount, new_amount, difference,
+		       settlement, settlement_ref, payment_instructions, changes, settled_at, expires_at, expired_at,
+		       settlement_pending_at, refund_amount, refund_ref, refunded_at, actor, created_at`
+
+// scanAmendment reads a row selected with amendmentColumns.
+func scanAmendment(row interface{ Scan(dest ...any) error }) (*entities.BookingAmendment, error) {
+	var a entities.BookingAmendment
+	var instructions, changes []byte
+
+	err := row.Scan(
+		&a.ID, &a.TransactionID, &a.FromShowtimeID, &a.ToShowtimeID, &a.PreviousAmount, &a.NewAmount, &a.Difference,
+		&a.Settlement, &a.SettlementRef, &instructions, &changes, &a.SettledAt, &a.ExpiresAt, &a.ExpiredAt,
+		&a.SettlementPendingAt, &a.RefundAmount, &a.RefundRef, &a.RefundedAt, &a.Actor, &a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.PaymentInstructions, err = unmarshalPaymentInstructions(instructions); err != nil {
+		return nil, err
+	}
+	if len(changes) > 0 {
+		var c amendmentChanges
+		if err := json.Unmarshal(changes, &c); err != nil {
+			return nil, err
+		}
+		a.Items = c.Items
+	}
+	return &a, nil
+}
+
+// lockShowtimeSeats takes a transaction-scoped advisory lock for every
+// (showtime, seat) pair. Seats are locked in a stable order so two bookings
+// sharing several seats cannot deadlock each other.
+func lockShowtimeSeats(ctx context.Context, q queryer, showtimeID uuid.UUID, seatIDs []uuid.UUID) error {
+	keys := make([]string, len(seatIDs))
+	for i, id := range seatIDs {
+		keys[i] = id.String()
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		_, err := q.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1), hashtext($2))`, showtimeID.String(), key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// countTakenSeats counts the given seats held for the showtime by bookings
+// other than excludeID; pass uuid.Nil to count every booking.
+func countTakenSeats(ctx context.Context, q queryer, showtimeID uuid.UUID, seatIDs []uuid.UUID, excludeID uuid.UUID) (int, error) {
+	// Check if any of the seats are already booked for this showtime
+	query := `
+		SELECT COUNT(*) FROM transaction_items ti
+		JOIN transactions t ON ti.transaction_id = t.id
+		WHERE t.showtime_id = $1
+		AND ti.replaced_at IS NULL
+		AND ` + seatHoldingCondition + `
+		AND ti.seat_id = ANY($2)
+		AND t.id <> $3
+	`
+
+	// Convert UUID slice to string slice for pq.Array
+	seatIDStrings := make([]string, len(seatIDs))
+	for i, id := range seatIDs {
+		seatIDStrings[i] = id.String()
+	}
+
+	var count int
+	err := q.QueryRowContext(ctx, query, showtimeID, pq.Array(seatIDStrings), excludeID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func marshalPaymentInstructions(instructions *entities.PaymentInstructions) (sql.NullString, error) {
+	if instructions == nil {
+		return sql.NullString{}, nil
+	}
+
+	raw, err := json.Marshal(instructions)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(raw), Valid: true}, nil
+}
+
+func unmarshalPaymentInstructions(raw []byte) (*entities.PaymentInstructions, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var instructions entities.PaymentInstructions
+	if err := json.Unmarshal(raw, &instructions); err != nil {
+		return nil, err
+	}
+	return &instructions, nil
 }
@@ -0,0 +1,224 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+type ICalendarRepository interface {
+	Rotate(ctx context.Context, feed *entities.CalendarFeed) error
+	Revoke(ctx context.Context, userID uuid.UUID) (bool, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entities.CalendarFeed, error)
+	FindFeedBookings(ctx context.Context, userID uuid.UUID, since time.Time) ([]entities.Transaction, error)
+}
+
+type CalendarRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarRepository(db *sql.DB) ICalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+// Rotate revokes the user's active feed, if any, and creates the given one in
+// its place.
+func (r *CalendarRepository) Rotate(ctx context.Context, feed *entities.CalendarFeed) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	if _, err := dbTx.ExecContext(ctx, `
+		UPDATE calendar_feeds SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, feed.UserID); err != nil {
+		return err
+	}
+
+	err = dbTx.QueryRowContext(ctx, `
+		INSERT INTO calendar_feeds (id, user_id, token_hash)
+		VALUES ($1, $2, $3)
+		RETURNING created_at
+	`, feed.ID, feed.UserID, feed.TokenHash).Scan(&feed.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return dbTx.Commit()
+}
+
+// Revoke disables the user's active feed. It reports false when there was
+// none.
+func (r *CalendarRepository) Revoke(ctx context.Context, userID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE calendar_feeds SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// FindByTokenHash returns the active feed unlocked by a token.
+func (r *CalendarRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entities.CalendarFeed, error) {
+	query := `
+		SELECT id, user_id, token_hash, created_at, revoked_at
+		FROM calendar_feeds
+		WHERE token_hash = $1 AND revoked_at IS NULL
+	`
+
+	var feed entities.CalendarFeed
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&feed.ID, &feed.UserID, &feed.TokenHash, &feed.CreatedAt, &feed.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &feed, nil
+}
+
+// FindFeedBookings returns the bookings the user holds a seat of, as owner or
+// through a transfer, that were ever paid for a showtime since the given time.
+// Each comes with its showtime, movie, theater and amendments, and only the
+// active seats the user holds. Refunded bookings stay in the feed so calendars
+// learn they were cancelled.
+func (r *CalendarRepository) FindFeedBookings(ctx context.Context, userID uuid.UUID, since time.Time) ([]entities.Transaction, error) {
+	query := `
+		SELECT
+			t.id, t.status, t.showtime_id, t.theater_id, t.user_id,
+			s.id, s.time, s.movie_id,
+			m.id, m.title, m.duration,
+			th.id, th.name, th.address, th.latitude, th.longitude
+		FROM transactions t
+		JOIN showtimes s ON t.showtime_id = s.id
+		JOIN movies m ON s.movie_id = m.id
+		JOIN theaters th ON t.theater_id = th.id
+		WHERE t.paid_at IS NOT NULL AND s.time >= $2
+		AND (t.user_id = $1 OR t.id IN (SELECT transaction_id FROM transaction_items WHERE holder_id = $1))
+		AND EXISTS (
+			SELECT 1 FROM transaction_items ti
+			WHERE ti.transaction_id = t.id AND ti.replaced_at IS NULL
+			AND COALESCE(ti.holder_id, t.user_id) = $1
+		)
+		ORDER BY s.time, t.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []entities.Transaction
+	index := make(map[uuid.UUID]int)
+	var ids []string
+	for rows.Next() {
+		var tx entities.Transaction
+		var showtime entities.Showtime
+		var movie entities.Movie
+		var theater entities.Theater
+
+		err := rows.Scan(
+			&tx.ID, &tx.Status, &tx.ShowtimeID, &tx.TheaterID, &tx.UserID,
+			&showtime.ID, &showtime.Time, &showtime.MovieID,
+			&movie.ID, &movie.Title, &movie.Duration,
+			&theater.ID, &theater.Name, &theater.Address, &theater.Latitude, &theater.Longitude,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		showtime.Movie = &movie
+		tx.Showtime = &showtime
+		tx.Theater = &theater
+		index[tx.ID] = len(transactions)
+		ids = append(ids, tx.ID.String())
+		transactions = append(transactions, tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(transactions) == 0 {
+		return transactions, nil
+	}
+
+	if err := r.attachFeedSeats(ctx, transactions, index, ids, userID); err != nil {
+		return nil, err
+	}
+	if err := r.attachFeedAmendments(ctx, transactions, index, ids); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// attachFeedSeats lists on each booking the active seats userID holds, so a
+// seat transferred away shows on the new holder's event only.
+func (r *CalendarRepository) attachFeedSeats(ctx context.Context, transactions []entities.Transaction, index map[uuid.UUID]int, ids []string, userID uuid.UUID) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ti.transaction_id, s.id, s.row, s.number
+		FROM transaction_items ti
+		JOIN transactions t ON ti.transaction_id = t.id
+		JOIN seats s ON ti.seat_id = s.id
+		WHERE ti.transaction_id = ANY($1) AND ti.replaced_at IS NULL
+		AND COALESCE(ti.holder_id, t.user_id) = $2
+		ORDER BY s.row, s.number
+	`, pq.Array(ids), userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transactionID uuid.UUID
+		var seat entities.Seat
+		if err := rows.Scan(&transactionID, &seat.ID, &seat.Row, &seat.Number); err != nil {
+			return err
+		}
+
+		tx := &transactions[index[transactionID]]
+		tx.Items = append(tx.Items, entities.TransactionItem{SeatID: seat.ID, Seat: &seat})
+	}
+
+	return rows.Err()
+}
+
+func (r *CalendarRepository) attachFeedAmendments(ctx context.Context, transactions []entities.Transaction, index map[uuid.UUID]int, ids []string) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+amendmentColumns+`
+		FROM booking_amendments
+		WHERE transaction_id = ANY($1)
+		ORDER BY created_at
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		amendment, err := scanAmendment(rows)
+		if err != nil {
+			return err
+		}
+
+		tx := &transactions[index[amendment.TransactionID]]
+		tx.Amendments = append(tx.Amendments, *amendment)
+	}
+
+	return rows.Err()
+}
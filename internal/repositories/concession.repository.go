@@ -0,0 +1,218 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+// reservedStockExpr sums what unpaid, unexpired orders have on a concession
+// variant (aliased v). Stock is only deducted on payment, so this is what
+// keeps two checkouts from selling the same last combo.
+const reservedStockExpr = `(
+	SELECT COALESCE(SUM(a.quantity), 0) FROM transaction_addons a
+	JOIN transactions t ON a.transaction_id = t.id
+	WHERE a.variant_id = v.id AND a.stock_deducted_at IS NULL
+	AND ` + openPendingCondition + `
+)`
+
+type IConcessionRepository interface {
+	FindCatalog(ctx context.Context, theaterID uuid.UUID) ([]entities.ConcessionItem, error)
+	FindVariantsByIDs(ctx context.Context, ids []uuid.UUID) ([]entities.ConcessionVariant, error)
+}
+
+type ConcessionRepository struct {
+	db *sql.DB
+}
+
+func NewConcessionRepository(db *sql.DB) IConcessionRepository {
+	return &ConcessionRepository{db: db}
+}
+
+// FindCatalog returns the active items of a theater with their active
+// variants and how many of each are still available.
+func (r *ConcessionRepository) FindCatalog(ctx context.Context, theaterID uuid.UUID) ([]entities.ConcessionItem, error) {
+	query := `
+		SELECT ci.id, ci.theater_id, ci.name, ci.description, ci.category, ci.image_url, ci.active, ci.created_at,
+		       v.id, v.item_id, v.name, v.price, v.stock, GREATEST(v.stock - ` + reservedStockExpr + `, 0), v.active, v.created_at
+		FROM concession_items ci
+		JOIN concession_variants v ON v.item_id = ci.id
+		WHERE ci.theater_id = $1 AND ci.active = true AND v.active = true
+		ORDER BY ci.category, ci.name, v.price, v.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, theaterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []entities.ConcessionItem
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var ci entities.ConcessionItem
+		var v entities.ConcessionVariant
+		err := rows.Scan(
+			&ci.ID, &ci.TheaterID, &ci.Name, &ci.Description, &ci.Category, &ci.ImageURL, &ci.Active, &ci.CreatedAt,
+			&v.ID, &v.ItemID, &v.Name, &v.Price, &v.Stock, &v.Available, &v.Active, &v.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		i, ok := index[ci.ID]
+		if !ok {
+			i = len(items)
+			index[ci.ID] = i
+			items = append(items, ci)
+		}
+		items[i].Variants = append(items[i].Variants, v)
+	}
+
+	return items, rows.Err()
+}
+
+// FindVariantsByIDs returns the given variants with their item, whether or
+// not they are still on sale.
+func (r *ConcessionRepository) FindVariantsByIDs(ctx context.Context, ids []uuid.UUID) ([]entities.ConcessionVariant, error) {
+	query := `
+		SELECT v.id, v.item_id, v.name, v.price, v.stock, GREATEST(v.stock - ` + reservedStockExpr + `, 0), v.active, v.created_at,
+		       ci.id, ci.theater_id, ci.name, ci.category, ci.active
+		FROM concession_variants v
+		JOIN concession_items ci ON v.item_id = ci.id
+		WHERE v.id = ANY($1)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(uuidStrings(ids)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []entities.ConcessionVariant
+	for rows.Next() {
+		var v entities.ConcessionVariant
+		var ci entities.ConcessionItem
+		err := rows.Scan(
+			&v.ID, &v.ItemID, &v.Name, &v.Price, &v.Stock, &v.Available, &v.Active, &v.CreatedAt,
+			&ci.ID, &ci.TheaterID, &ci.Name, &ci.Category, &ci.Active,
+		)
+		if err != nil {
+			return nil, err
+		}
+		v.Item = &ci
+		variants = append(variants, v)
+	}
+
+	return variants, rows.Err()
+}
+
+// reserveAddons checks, with the variants locked, that every addon is still
+// in stock. It returns ErrAddonsOutOfStock otherwise.
+func reserveAddons(ctx context.Context, q queryer, addons []entities.TransactionAddon) error {
+	if len(addons) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(addons))
+	for i, a := range addons {
+		ids[i] = a.VariantID
+	}
+
+	_, err := q.ExecContext(ctx,
+		`SELECT id FROM concession_variants WHERE id = ANY($1) ORDER BY id FOR UPDATE`,
+		pq.Array(uuidStrings(ids)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to lock concession stock: %w", err)
+	}
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT v.id, v.stock - `+reservedStockExpr+` FROM concession_variants v WHERE v.id = ANY($1)`,
+		pq.Array(uuidStrings(ids)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to check concession stock: %w", err)
+	}
+	defer rows.Close()
+
+	available := make(map[uuid.UUID]int, len(ids))
+	for rows.Next() {
+		var id uuid.UUID
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return err
+		}
+		available[id] = n
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, a := range addons {
+		if available[a.VariantID] < a.Quantity {
+			return ErrAddonsOutOfStock
+		}
+	}
+	return nil
+}
+
+// deductAddonStock takes a paid transaction's addons out of stock. Each
+// variant appears at most once per transaction.
+func deductAddonStock(ctx context.Context, q queryer, txID uuid.UUID) error {
+	query := `
+		WITH deducted AS (
+			UPDATE transaction_addons SET stock_deducted_at = NOW()
+			WHERE transaction_id = $1 AND stock_deducted_at IS NULL
+			RETURNING variant_id, quantity
+		)
+		UPDATE concession_variants v SET stock = v.stock - d.quantity
+		FROM deducted d WHERE v.id = d.variant_id
+	`
+	_, err := q.ExecContext(ctx, query, txID)
+	return err
+}
+
+// restoreAddonStock puts what deductAddonStock took back into stock.
+func restoreAddonStock(ctx context.Context, q queryer, txID uuid.UUID) error {
+	query := `
+		WITH restored AS (
+			UPDATE transaction_addons SET stock_restored_at = NOW()
+			WHERE transaction_id = $1 AND stock_deducted_at IS NOT NULL AND stock_restored_at IS NULL
+			RETURNING variant_id, quantity
+		)
+		UPDATE concession_variants v SET stock = v.stock + r.quantity
+		FROM restored r WHERE v.id = r.variant_id
+	`
+	_, err := q.ExecContext(ctx, query, txID)
+	return err
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = id.String()
+	}
+	return s
+}
+
+// pickupCodeAlphabet leaves out look-alikes such as 0/O and 1/I, since the
+// code is read out at the counter.
+const pickupCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// newPickupCode returns a random 8 character code for collecting concessions.
+func newPickupCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate pickup code: %w", err)
+	}
+	for i := range b {
+		b[i] = pickupCodeAlphabet[int(b[i])%len(pickupCodeAlphabet)]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+type IFeeRepository interface {
+	FindActiveRules(ctx context.Context, theaterID uuid.UUID) ([]entities.FeeRule, error)
+}
+
+type FeeRepository struct {
+	db *sql.DB
+}
+
+func NewFeeRepository(db *sql.DB) IFeeRepository {
+	return &FeeRepository{db: db}
+}
+
+// FindActiveRules returns the active fee and tax rules of a theater, oldest
+// first.
+func (r *FeeRepository) FindActiveRules(ctx context.Context, theaterID uuid.UUID) ([]entities.FeeRule, error) {
+	query := `
+		SELECT id, theater_id, kind, name, basis, value, payment_method_type_id, active, created_at
+		FROM fee_rules
+		WHERE theater_id = $1 AND active = true
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, theaterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []entities.FeeRule
+	for rows.Next() {
+		var fr entities.FeeRule
+		err := rows.Scan(
+			&fr.ID, &fr.TheaterID, &fr.Kind, &fr.Name, &fr.Basis, &fr.Value,
+			&fr.PaymentMethodTypeID, &fr.Active, &fr.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fr)
+	}
+
+	return rules, rows.Err()
+}
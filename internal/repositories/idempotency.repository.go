@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+type IIdempotencyRepository interface {
+	Reserve(ctx context.Context, key *entities.IdempotencyKey, staleAfter time.Duration) (bool, error)
+	FindByKey(ctx context.Context, userID uuid.UUID, key string) (*entities.IdempotencyKey, error)
+	Complete(ctx context.Context, userID uuid.UUID, key string, statusCode int, contentType string, body []byte) error
+	Delete(ctx context.Context, userID uuid.UUID, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) IIdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve claims a key for a new request. It reports false when the key is
+// already held, unless the holder expired or is an unfinished request older
+// than staleAfter (e.g. the server died mid-request); those are taken over.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, key *entities.IdempotencyKey, staleAfter time.Duration) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (user_id, key, fingerprint, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status_code = NULL,
+			content_type = '',
+			response_body = NULL,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= NOW() - make_interval(secs => $5))
+	`
+
+	res, err := r.db.ExecContext(ctx, query, key.UserID, key.Key, key.Fingerprint, key.ExpiresAt, staleAfter.Seconds())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *IdempotencyRepository) FindByKey(ctx context.Context, userID uuid.UUID, key string) (*entities.IdempotencyKey, error) {
+	query := `
+		SELECT user_id, key, fingerprint, status_code, content_type, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2
+	`
+
+	var k entities.IdempotencyKey
+	err := r.db.QueryRowContext(ctx, query, userID, key).Scan(
+		&k.UserID, &k.Key, &k.Fingerprint, &k.StatusCode, &k.ContentType, &k.ResponseBody, &k.CreatedAt, &k.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+// Complete stores the response of a finished request for replay.
+func (r *IdempotencyRepository) Complete(ctx context.Context, userID uuid.UUID, key string, statusCode int, contentType string, body []byte) error {
+	query := `
+		UPDATE idempotency_keys SET status_code = $3, content_type = $4, response_body = $5
+		WHERE user_id = $1 AND key = $2
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, key, statusCode, contentType, body)
+	return err
+}
+
+func (r *IdempotencyRepository) Delete(ctx context.Context, userID uuid.UUID, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2`, userID, key)
+	return err
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// nextInvoiceNumber issues the next invoice number of a theater for a period
+// (yyyyMM), formatted as INV/{theater code}/{period}/{seq}. The sequence row
+// stays locked until q's transaction ends, so concurrent payments at the same
+// theater take turns, and a rolled back payment hands its number back: the
+// sequence has no gaps.
+func nextInvoiceNumber(ctx context.Context, q queryer, theaterID uuid.UUID, period string) (string, error) {
+	query := `
+		INSERT INTO invoice_sequences (theater_id, period, last_value)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (theater_id, period) DO UPDATE SET last_value = invoice_sequences.last_value + 1
+		RETURNING last_value, (SELECT code FROM theaters WHERE id = $1)
+	`
+
+	var seq int64
+	var theaterCode string
+	if err := q.QueryRowContext(ctx, query, theaterID, period).Scan(&seq, &theaterCode); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("INV/%s/%s/%06d", theaterCode, period, seq), nil
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+type IPaymentMethodRepository interface {
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.PaymentMethod, error)
+}
+
+type PaymentMethodRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentMethodRepository(db *sql.DB) IPaymentMethodRepository {
+	return &PaymentMethodRepository{db: db}
+}
+
+func (r *PaymentMethodRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.PaymentMethod, error) {
+	query := `
+		SELECT pm.id, pm.code, pm.name, pm.logo_url, pm.active, pm.payment_method_type_id,
+		       pmt.id, pmt.name
+		FROM payment_methods pm
+		JOIN payment_method_types pmt ON pm.payment_method_type_id = pmt.id
+		WHERE pm.id = $1
+	`
+
+	var method entities.PaymentMethod
+	var methodType entities.PaymentMethodType
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&method.ID, &method.Code, &method.Name, &method.LogoURL, &method.Active, &method.PaymentMethodTypeID,
+		&methodType.ID, &methodType.Name,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	method.Type = &methodType
+	return &method, nil
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+type IPricingRepository interface {
+	FindSeatPricings(ctx context.Context, theaterID uuid.UUID, dayType string) ([]entities.SeatPricing, error)
+	FindActiveOverrides(ctx context.Context, movieID, theaterID uuid.UUID, at time.Time) ([]entities.SeatPricingOverride, error)
+	FindOverrideByID(ctx context.Context, id uuid.UUID) (*entities.SeatPricingOverride, error)
+}
+
+type PricingRepository struct {
+	db *sql.DB
+}
+
+func NewPricingRepository(db *sql.DB) IPricingRepository {
+	return &PricingRepository{db: db}
+}
+
+func (r *PricingRepository) FindSeatPricings(ctx context.Context, theaterID uuid.UUID, dayType string) ([]entities.SeatPricing, error) {
+	query := `
+		SELECT id, price, day_type, seat_type_id, theater_id
+		FROM seat_pricings
+		WHERE theater_id = $1 AND day_type = $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, theaterID, dayType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pricings []entities.SeatPricing
+	for rows.Next() {
+		var sp entities.SeatPricing
+		if err := rows.Scan(&sp.ID, &sp.Price, &sp.DayType, &sp.SeatTypeID, &sp.TheaterID); err != nil {
+			return nil, err
+		}
+		pricings = append(pricings, sp)
+	}
+
+	return pricings, nil
+}
+
+// FindActiveOverrides returns the overrides for a movie at a theater that are
+// in effect at the given time, most recently started first.
+func (r *PricingRepository) FindActiveOverrides(ctx context.Context, movieID, theaterID uuid.UUID, at time.Time) ([]entities.SeatPricingOverride, error) {
+	query := `
+		SELECT id, price, notes, active, starts_at, ends_at, movie_id, seat_type_id, theater_id
+		FROM seat_pricing_overrides
+		WHERE movie_id = $1 AND theater_id = $2 AND active = true
+		AND (starts_at IS NULL OR starts_at <= $3)
+		AND (ends_at IS NULL OR ends_at > $3)
+		ORDER BY starts_at DESC NULLS LAST
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, movieID, theaterID, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []entities.SeatPricingOverride
+	for rows.Next() {
+		var o entities.SeatPricingOverride
+		err := rows.Scan(
+			&o.ID, &o.Price, &o.Notes, &o.Active, &o.StartsAt, &o.EndsAt,
+			&o.MovieID, &o.SeatTypeID, &o.TheaterID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+
+	return overrides, nil
+}
+
+func (r *PricingRepository) FindOverrideByID(ctx context.Context, id uuid.UUID) (*entities.SeatPricingOverride, error) {
+	query := `
+		SELECT id, price, notes, active, starts_at, ends_at, movie_id, seat_type_id, theater_id
+		FROM seat_pricing_overrides
+		WHERE id = $1
+	`
+
+	var o entities.SeatPricingOverride
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&o.ID, &o.Price, &o.Notes, &o.Active, &o.StartsAt, &o.EndsAt,
+		&o.MovieID, &o.SeatTypeID, &o.TheaterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
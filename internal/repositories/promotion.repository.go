@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+// ErrPromotionExhausted is returned by Create when redeeming the promotion
+// would exceed its global or per-user usage limit.
+var ErrPromotionExhausted = errors.New("promotion usage limit reached")
+
+type IPromotionRepository interface {
+	FindByCode(ctx context.Context, code string) (*entities.Promotion, error)
+}
+
+type PromotionRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) IPromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+const promotionColumns = `
+	id, code, description, discount_type, discount_value, max_discount, min_spend,
+	usage_limit, per_user_limit, active, starts_at, ends_at,
+	movie_id, theater_id, seat_type_id, payment_method_id, created_at
+`
+
+func (r *PromotionRepository) FindByCode(ctx context.Context, code string) (*entities.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE code = $1`
+	return scanPromotion(r.db.QueryRowContext(ctx, query, code))
+}
+
+func scanPromotion(row *sql.Row) (*entities.Promotion, error) {
+	var p entities.Promotion
+	err := row.Scan(
+		&p.ID, &p.Code, &p.Description, &p.DiscountType, &p.DiscountValue, &p.MaxDiscount, &p.MinSpend,
+		&p.UsageLimit, &p.PerUserLimit, &p.Active, &p.StartsAt, &p.EndsAt,
+		&p.MovieID, &p.TheaterID, &p.SeatTypeID, &p.PaymentMethodID, &p.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// redeemPromotion records the transaction's use of its promotion. The
+// promotion row is locked first so concurrent redemptions of the same code
+// count one after another and can never overshoot a limit. Only redemptions of
+// bookings that still hold their seats count, so expired and cancelled
+// bookings give their use back.
+func redeemPromotion(ctx context.Context, q queryer, tx *entities.Transaction) error {
+	var usageLimit, perUserLimit sql.NullInt64
+	err := q.QueryRowContext(ctx,
+		`SELECT usage_limit, per_user_limit FROM promotions WHERE id = $1 FOR UPDATE`,
+		tx.PromotionID,
+	).Scan(&usageLimit, &perUserLimit)
+	if err != nil {
+		return fmt.Errorf("failed to lock promotion: %w", err)
+	}
+
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE r.user_id = $2)
+		FROM promotion_redemptions r
+		JOIN transactions t ON r.transaction_id = t.id
+		WHERE r.promotion_id = $1
+		AND ` + seatHoldingCondition + `
+	`
+
+	var used, usedByUser int64
+	if err := q.QueryRowContext(ctx, query, tx.PromotionID, tx.UserID).Scan(&used, &usedByUser); err != nil {
+		return fmt.Errorf("failed to count promotion redemptions: %w", err)
+	}
+
+	if usageLimit.Valid && used >= usageLimit.Int64 {
+		return ErrPromotionExhausted
+	}
+	if perUserLimit.Valid && usedByUser >= perUserLimit.Int64 {
+		return ErrPromotionExhausted
+	}
+
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO promotion_redemptions (id, promotion_id, transaction_id, user_id, amount)
+		VALUES ($1, $2, $3, $4, $5)
+	`, uuid.New(), tx.PromotionID, tx.ID, tx.UserID, tx.DiscountAmount)
+	if err != nil {
+		return fmt.Errorf("failed to insert promotion redemption: %w", err)
+	}
+
+	return nil
+}
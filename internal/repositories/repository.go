@@ -1,25 +1,81 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
 )
 
+// queryer is implemented by both *sql.DB and *sql.Tx, so query helpers can
+// run either standalone or inside an open transaction.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// seatBookedCondition matches transactions (aliased t) that are paid for,
+// checked in or not.
+const seatBookedCondition = `t.status IN ('` + string(entities.TransactionStatusPaid) + `', '` + string(entities.TransactionStatusUsed) + `')`
+
+// openPendingCondition matches pending transactions (aliased t) whose payment
+// window is still open.
+const openPendingCondition = `(t.status = '` + string(entities.TransactionStatusPending) + `' AND t.expired_at > NOW())`
+
+// seatHoldingCondition matches transactions (aliased t) whose seats are still
+// taken: paid orders (checked in or not), and pending orders whose payment
+// window is still open. Every availability query uses it so the seat map and
+// checkout agree, together with ti.replaced_at IS NULL to skip items an
+// amendment has replaced.
+const seatHoldingCondition = `(` + seatBookedCondition + ` OR ` + openPendingCondition + `)`
+
+// activeHoldCondition matches seat holds (aliased h) that still keep their
+// seat from everyone but the holder.
+const activeHoldCondition = `(h.released_at IS NULL AND h.expires_at > NOW())`
+
+// seatBlockedCondition matches seat blocks (aliased b) that keep their seat
+// off sale for the showtime aliased s.
+const seatBlockedCondition = `(b.released_at IS NULL AND (b.showtime_id = s.id OR (b.showtime_id IS NULL AND s.time >= b.starts_at AND s.time < b.ends_at)))`
+
 type Repositories struct {
-	UserRepository     IUserRepository
-	MovieRepository    IMovieRepository
-	CinemaRepository   ICinemaRepository
-	ShowtimeRepository IShowtimeRepository
-	SeatRepository     ISeatRepository
-	BookingRepository  IBookingRepository
+	UserRepository          IUserRepository
+	MovieRepository         IMovieRepository
+	CinemaRepository        ICinemaRepository
+	ShowtimeRepository      IShowtimeRepository
+	SeatRepository          ISeatRepository
+	BookingRepository       IBookingRepository
+	PaymentMethodRepository IPaymentMethodRepository
+	PricingRepository       IPricingRepository
+	PromotionRepository     IPromotionRepository
+	IdempotencyRepository   IIdempotencyRepository
+	WaitlistRepository      IWaitlistRepository
+	FeeRepository           IFeeRepository
+	ConcessionRepository    IConcessionRepository
+	TransferRepository      ITransferRepository
+	CalendarRepository      ICalendarRepository
+	SeatBlockRepository     ISeatBlockRepository
+	StudioRepository        IStudioRepository
 }
 
 func RegisterRepositories(db *sql.DB) *Repositories {
 	return &Repositories{
-		UserRepository:     NewUserRepository(db),
-		MovieRepository:    NewMovieRepository(db),
-		CinemaRepository:   NewCinemaRepository(db),
-		ShowtimeRepository: NewShowtimeRepository(db),
-		SeatRepository:     NewSeatRepository(db),
-		BookingRepository:  NewBookingRepository(db),
+		UserRepository:          NewUserRepository(db),
+		MovieRepository:         NewMovieRepository(db),
+		CinemaRepository:        NewCinemaRepository(db),
+		ShowtimeRepository:      NewShowtimeRepository(db),
+		SeatRepository:          NewSeatRepository(db),
+		BookingRepository:       NewBookingRepository(db),
+		PaymentMethodRepository: NewPaymentMethodRepository(db),
+		PricingRepository:       NewPricingRepository(db),
+		PromotionRepository:     NewPromotionRepository(db),
+		IdempotencyRepository:   NewIdempotencyRepository(db),
+		WaitlistRepository:      NewWaitlistRepository(db),
+		FeeRepository:           NewFeeRepository(db),
+		ConcessionRepository:    NewConcessionRepository(db),
+		TransferRepository:      NewTransferRepository(db),
+		CalendarRepository:      NewCalendarRepository(db),
+		SeatBlockRepository:     NewSeatBlockRepository(db),
+		StudioRepository:        NewStudioRepository(db),
 	}
 }
@@ -5,12 +5,17 @@ import (
 	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/senatroxx/filmix-backend/internal/database/entities"
 )
 
 type ISeatRepository interface {
 	FindByStudioID(ctx context.Context, studioID uuid.UUID) ([]entities.Seat, error)
 	FindBookedSeatIDs(ctx context.Context, showtimeID uuid.UUID) ([]uuid.UUID, error)
+	FindSeatStates(ctx context.Context, showtimeID uuid.UUID) (held []uuid.UUID, booked []uuid.UUID, err error)
+	FindBlockedSeatIDs(ctx context.Context, showtimeID uuid.UUID) ([]uuid.UUID, error)
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]entities.Seat, error)
+	FindGapsByStudioID(ctx context.Context, studioID uuid.UUID) ([]entities.StudioGap, error)
 }
 
 type SeatRepository struct {
@@ -21,14 +26,17 @@ func NewSeatRepository(db *sql.DB) ISeatRepository {
 	return &SeatRepository{db: db}
 }
 
+// FindByStudioID returns the seats of a studio that are for sale, in seat map
+// order.
 func (r *SeatRepository) FindByStudioID(ctx context.Context, studioID uuid.UUID) ([]entities.Seat, error) {
 	query := `
 		SELECT s.id, s.row, s.number, s.active, s.studio_id, s.seat_type_id,
+		       s.grid_row, s.grid_column, s.wheelchair, s.companion, s.paired_with_id,
 		       st.id, st.name
 		FROM seats s
 		JOIN seat_type st ON s.seat_type_id = st.id
 		WHERE s.studio_id = $1 AND s.active = true
-		ORDER BY s.row, s.number
+		ORDER BY s.grid_row, s.grid_column
 	`
 
 	rows, err := r.db.QueryContext(ctx, query, studioID)
@@ -44,6 +52,7 @@ func (r *SeatRepository) FindByStudioID(ctx context.Context, studioID uuid.UUID)
 
 		err := rows.Scan(
 			&seat.ID, &seat.Row, &seat.Number, &seat.Active, &seat.StudioID, &seat.SeatTypeID,
+			&seat.GridRow, &seat.GridColumn, &seat.Wheelchair, &seat.Companion, &seat.PairedWithID,
 			&seatType.ID, &seatType.Name,
 		)
 		if err != nil {
@@ -57,12 +66,24 @@ func (r *SeatRepository) FindByStudioID(ctx context.Context, studioID uuid.UUID)
 	return seats, nil
 }
 
+// FindBookedSeatIDs returns the seats of a showtime that can't be booked right
+// now: seats of live bookings, seats held for waitlisted users or for unpaid
+// booking changes, and blocked seats.
 func (r *SeatRepository) FindBookedSeatIDs(ctx context.Context, showtimeID uuid.UUID) ([]uuid.UUID, error) {
 	query := `
 		SELECT ti.seat_id
 		FROM transaction_items ti
 		JOIN transactions t ON ti.transaction_id = t.id
-		WHERE t.showtime_id = $1 AND t.status IN ('pending', 'paid')
+		WHERE t.showtime_id = $1 AND ti.replaced_at IS NULL AND ` + seatHoldingCondition + `
+		UNION
+		SELECT h.seat_id
+		FROM seat_holds h
+		WHERE h.showtime_id = $1 AND ` + activeHoldCondition + `
+		UNION
+		SELECT b.seat_id
+		FROM seat_blocks b
+		JOIN showtimes s ON s.id = $1
+		WHERE ` + seatBlockedCondition + `
 	`
 
 	rows, err := r.db.QueryContext(ctx, query, showtimeID)
@@ -82,3 +103,140 @@ func (r *SeatRepository) FindBookedSeatIDs(ctx context.Context, showtimeID uuid.
 
 	return bookedIDs, nil
 }
+
+// FindSeatStates splits the booked and held seats FindBookedSeatIDs returns
+// into those that are paid for and those only held: by pending bookings or
+// for waitlisted users. Blocked seats are left to FindBlockedSeatIDs.
+func (r *SeatRepository) FindSeatStates(ctx context.Context, showtimeID uuid.UUID) ([]uuid.UUID, []uuid.UUID, error) {
+	query := `
+		SELECT ti.seat_id, ` + seatBookedCondition + `
+		FROM transaction_items ti
+		JOIN transactions t ON ti.transaction_id = t.id
+		WHERE t.showtime_id = $1 AND ti.replaced_at IS NULL AND ` + seatHoldingCondition + `
+		UNION
+		SELECT h.seat_id, false
+		FROM seat_holds h
+		WHERE h.showtime_id = $1 AND ` + activeHoldCondition + `
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, showtimeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	paid := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var seatID uuid.UUID
+		var isPaid bool
+		if err := rows.Scan(&seatID, &isPaid); err != nil {
+			return nil, nil, err
+		}
+		paid[seatID] = paid[seatID] || isPaid
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var held, booked []uuid.UUID
+	for seatID, isPaid := range paid {
+		if isPaid {
+			booked = append(booked, seatID)
+		} else {
+			held = append(held, seatID)
+		}
+	}
+
+	return held, booked, nil
+}
+
+// FindBlockedSeatIDs returns the seats blocked for a showtime.
+func (r *SeatRepository) FindBlockedSeatIDs(ctx context.Context, showtimeID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT b.seat_id
+		FROM seat_blocks b
+		JOIN showtimes s ON s.id = $1
+		WHERE ` + seatBlockedCondition + `
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, showtimeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blockedIDs []uuid.UUID
+	for rows.Next() {
+		var seatID uuid.UUID
+		if err := rows.Scan(&seatID); err != nil {
+			return nil, err
+		}
+		blockedIDs = append(blockedIDs, seatID)
+	}
+
+	return blockedIDs, rows.Err()
+}
+
+// FindByIDs returns the given seats, inactive ones included. Unknown IDs are
+// left out.
+func (r *SeatRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]entities.Seat, error) {
+	query := `
+		SELECT id, row, number, active, studio_id, seat_type_id,
+		       grid_row, grid_column, wheelchair, companion, paired_with_id
+		FROM seats
+		WHERE id = ANY($1)
+	`
+
+	idStrings := make([]string, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(idStrings))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seats []entities.Seat
+	for rows.Next() {
+		var seat entities.Seat
+		err := rows.Scan(
+			&seat.ID, &seat.Row, &seat.Number, &seat.Active, &seat.StudioID, &seat.SeatTypeID,
+			&seat.GridRow, &seat.GridColumn, &seat.Wheelchair, &seat.Companion, &seat.PairedWithID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		seats = append(seats, seat)
+	}
+
+	return seats, rows.Err()
+}
+
+// FindGapsByStudioID returns the aisles and stairs of a studio's seat map.
+func (r *SeatRepository) FindGapsByStudioID(ctx context.Context, studioID uuid.UUID) ([]entities.StudioGap, error) {
+	query := `
+		SELECT id, studio_id, grid_row, grid_column, kind
+		FROM studio_gaps
+		WHERE studio_id = $1
+		ORDER BY grid_row, grid_column
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, studioID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gaps []entities.StudioGap
+	for rows.Next() {
+		var gap entities.StudioGap
+		if err := rows.Scan(&gap.ID, &gap.StudioID, &gap.GridRow, &gap.GridColumn, &gap.Kind); err != nil {
+			return nil, err
+		}
+		gaps = append(gaps, gap)
+	}
+
+	return gaps, rows.Err()
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+// SeatBlockFilter narrows the active seat blocks to list. ShowtimeID matches
+// the blocks in force for that showtime, whatever their scope; StudioID the
+// blocks of the studio's seats.
+type SeatBlockFilter struct {
+	ShowtimeID *uuid.UUID
+	StudioID   *uuid.UUID
+}
+
+type ISeatBlockRepository interface {
+	Create(ctx context.Context, blocks []entities.SeatBlock) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.SeatBlock, error)
+	FindActive(ctx context.Context, filter SeatBlockFilter) ([]entities.SeatBlock, error)
+	Release(ctx context.Context, id uuid.UUID, releasedBy uuid.UUID) (bool, error)
+	FindUpcomingShowtimeSeats(ctx context.Context, seatIDs []uuid.UUID, from time.Time, until time.Time) (map[uuid.UUID][]uuid.UUID, error)
+}
+
+type SeatBlockRepository struct {
+	db *sql.DB
+}
+
+func NewSeatBlockRepository(db *sql.DB) ISeatBlockRepository {
+	return &SeatBlockRepository{db: db}
+}
+
+// Create stores the blocks, all or none.
+func (r *SeatBlockRepository) Create(ctx context.Context, blocks []entities.SeatBlock) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	query := `
+		INSERT INTO seat_blocks (id, seat_id, showtime_id, starts_at, ends_at, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+	for i := range blocks {
+		b := &blocks[i]
+		err := dbTx.QueryRowContext(ctx, query,
+			b.ID, b.SeatID, b.ShowtimeID, b.StartsAt, b.EndsAt, b.Reason, b.CreatedBy,
+		).Scan(&b.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert seat block: %w", err)
+		}
+	}
+
+	return dbTx.Commit()
+}
+
+func (r *SeatBlockRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.SeatBlock, error) {
+	query := `
+		SELECT ` + seatBlockColumns + `
+		FROM seat_blocks b
+		JOIN seats se ON b.seat_id = se.id
+		WHERE b.id = $1
+	`
+
+	return scanSeatBlock(r.db.QueryRowContext(ctx, query, id))
+}
+
+// FindActive returns the unreleased blocks matching the filter, in seat map
+// order.
+func (r *SeatBlockRepository) FindActive(ctx context.Context, filter SeatBlockFilter) ([]entities.SeatBlock, error) {
+	query := `
+		SELECT ` + seatBlockColumns + `
+		FROM seat_blocks b
+		JOIN seats se ON b.seat_id = se.id
+		WHERE b.released_at IS NULL
+		AND ($1::uuid IS NULL OR EXISTS (
+			SELECT 1 FROM showtimes s
+			WHERE s.id = $1 AND s.studio_id = se.studio_id AND ` + seatBlockedCondition + `
+		))
+		AND ($2::uuid IS NULL OR se.studio_id = $2)
+		ORDER BY se.grid_row, se.grid_column, b.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.ShowtimeID, filter.StudioID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []entities.SeatBlock
+	for rows.Next() {
+		block, err := scanSeatBlock(rows)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, *block)
+	}
+
+	return blocks, rows.Err()
+}
+
+// Release puts a blocked seat back on sale. It reports false when the block
+// was already released.
+func (r *SeatBlockRepository) Release(ctx context.Context, id uuid.UUID, releasedBy uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE seat_blocks SET released_at = NOW(), released_by = $2
+		WHERE id = $1 AND released_at IS NULL
+	`, id, releasedBy)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// FindUpcomingShowtimeSeats maps the showtimes starting in [from, until) that
+// haven't started yet to which of the given seats they use.
+func (r *SeatBlockRepository) FindUpcomingShowtimeSeats(ctx context.Context, seatIDs []uuid.UUID, from time.Time, until time.Time) (map[uuid.UUID][]uuid.UUID, error) {
+	query := `
+		SELECT s.id, se.id
+		FROM showtimes s
+		JOIN seats se ON se.studio_id = s.studio_id
+		WHERE se.id = ANY($1) AND s.time >= $2 AND s.time < $3 AND s.time > NOW()
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(uuidStrings(seatIDs)), from, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	showtimeSeats := make(map[uuid.UUID][]uuid.UUID)
+	for rows.Next() {
+		var showtimeID, seatID uuid.UUID
+		if err := rows.Scan(&showtimeID, &seatID); err != nil {
+			return nil, err
+		}
+		showtimeSeats[showtimeID] = append(showtimeSeats[showtimeID], seatID)
+	}
+
+	return showtimeSeats, rows.Err()
+}
+
+// seatBlockColumns selects a block (aliased b) with its seat (se).
+const seatBlockColumns = `b.id, b.seat_id, b.showtime_id, b.starts_at, b.ends_at, b.reason, b.created_by, b.created_at, b.released_by, b.released_at,
+		       se.id, se.row, se.number, se.studio_id, se.grid_row, se.grid_column`
+
+// scanSeatBlock reads a row selected with seatBlockColumns.
+func scanSeatBlock(row interface{ Scan(dest ...any) error }) (*entities.SeatBlock, error) {
+	var block entities.SeatBlock
+	var seat entities.Seat
+
+	err := row.Scan(
+		&block.ID, &block.SeatID, &block.ShowtimeID, &block.StartsAt, &block.EndsAt, &block.Reason,
+		&block.CreatedBy, &block.CreatedAt, &block.ReleasedBy, &block.ReleasedAt,
+		&seat.ID, &seat.Row, &seat.Number, &seat.StudioID, &seat.GridRow, &seat.GridColumn,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	block.Seat = &seat
+	return &block, nil
+}
+
+// countBlockedSeats counts the given seats that are blocked for the showtime.
+// Seats of transaction excludeID are left out, so a booking keeps seats that
+// were blocked after it was made; pass uuid.Nil to count every block.
+func countBlockedSeats(ctx context.Context, q queryer, showtimeID uuid.UUID, seatIDs []uuid.UUID, excludeID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT b.seat_id) FROM seat_blocks b
+		JOIN showtimes s ON s.id = $1
+		WHERE ` + seatBlockedCondition + `
+		AND b.seat_id = ANY($2)
+		AND NOT EXISTS (
+			SELECT 1 FROM transaction_items ti
+			WHERE ti.transaction_id = $3 AND ti.seat_id = b.seat_id AND ti.replaced_at IS NULL
+		)
+	`
+
+	var count int
+	err := q.QueryRowContext(ctx, query, showtimeID, pq.Array(uuidStrings(seatIDs)), excludeID).Scan(&count)
+	return count, err
+}
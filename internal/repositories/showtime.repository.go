@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/senatroxx/filmix-backend/internal/database/entities"
 )
 
@@ -13,6 +14,7 @@ type IShowtimeRepository interface {
 	FindByMovieID(ctx context.Context, movieID uuid.UUID, date *time.Time) ([]entities.Showtime, error)
 	FindByTheaterID(ctx context.Context, theaterID uuid.UUID, date *time.Time) ([]entities.Showtime, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*entities.Showtime, error)
+	FindAvailability(ctx context.Context, showtimeIDs []uuid.UUID) (map[uuid.UUID][]entities.SeatAvailability, error)
 }
 
 type ShowtimeRepository struct {
@@ -94,8 +96,8 @@ func (r *ShowtimeRepository) FindByTheaterID(ctx context.Context, theaterID uuid
 func (r *ShowtimeRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.Showtime, error) {
 	query := `
 		SELECT 
-			s.id, s.status, s.time, s.expired_at, s.movie_id, s.studio_id, s.theater_id, s.seat_pricing_id,
-			st.id, st.name, st.theater_id,
+			s.id, s.status, s.time, s.expired_at, s.movie_id, s.studio_id, s.theater_id, s.seat_pricing_id, s.seat_pricing_override_id,
+			st.id, st.name, st.theater_id, st.screen_position,
 			t.id, t.name, t.address, t.latitude, t.longitude, t.cinema_id,
 			c.id, c.name, c.logo_url,
 			sp.id, sp.price, sp.day_type,
@@ -118,8 +120,8 @@ func (r *ShowtimeRepository) FindByID(ctx context.Context, id uuid.UUID) (*entit
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&showtime.ID, &showtime.Status, &showtime.Time, &showtime.ExpiredAt,
-		&showtime.MovieID, &showtime.StudioID, &showtime.TheaterID, &showtime.SeatPricingID,
-		&studio.ID, &studio.Name, &studio.TheaterID,
+		&showtime.MovieID, &showtime.StudioID, &showtime.TheaterID, &showtime.SeatPricingID, &showtime.SeatPricingOverrideID,
+		&studio.ID, &studio.Name, &studio.TheaterID, &studio.ScreenPosition,
 		&theater.ID, &theater.Name, &theater.Address, &theater.Latitude, &theater.Longitude, &theater.CinemaID,
 		&cinema.ID, &cinema.Name, &cinema.LogoURL,
 		&pricing.ID, &pricing.Price, &pricing.DayType,
@@ -138,6 +140,81 @@ func (r *ShowtimeRepository) FindByID(ctx context.Context, id uuid.UUID) (*entit
 	return &showtime, nil
 }
 
+// FindAvailability counts the seats of each showtime by seat type, in one
+// query for all of them. Seats taken off sale don't count. Booked, held and
+// blocked seats are gathered per showtime first and joined onto the seat map,
+// so each source is read once rather than once per seat.
+func (r *ShowtimeRepository) FindAvailability(ctx context.Context, showtimeIDs []uuid.UUID) (map[uuid.UUID][]entities.SeatAvailability, error) {
+	query := `
+		WITH booked AS (
+			SELECT DISTINCT t.showtime_id, ti.seat_id
+			FROM transactions t
+			JOIN transaction_items ti ON ti.transaction_id = t.id
+			WHERE t.showtime_id = ANY($1) AND ti.replaced_at IS NULL AND ` + seatBookedCondition + `
+		), held AS (
+			SELECT t.showtime_id, ti.seat_id
+			FROM transactions t
+			JOIN transaction_items ti ON ti.transaction_id = t.id
+			WHERE t.showtime_id = ANY($1) AND ti.replaced_at IS NULL AND ` + openPendingCondition + `
+			UNION
+			SELECT h.showtime_id, h.seat_id
+			FROM seat_holds h
+			WHERE h.showtime_id = ANY($1) AND ` + activeHoldCondition + `
+		), blocked AS (
+			SELECT DISTINCT s.id AS showtime_id, b.seat_id
+			FROM showtimes s
+			JOIN seat_blocks b ON ` + seatBlockedCondition + `
+			WHERE s.id = ANY($1)
+		), seat_states AS (
+			SELECT s.id AS showtime_id, se.seat_type_id,
+				CASE
+					WHEN bk.seat_id IS NOT NULL THEN 'booked'
+					WHEN hd.seat_id IS NOT NULL THEN 'held'
+					WHEN bl.seat_id IS NOT NULL THEN 'blocked'
+					ELSE 'available'
+				END AS state
+			FROM showtimes s
+			JOIN seats se ON se.studio_id = s.studio_id AND se.active = true
+			LEFT JOIN booked bk ON bk.showtime_id = s.id AND bk.seat_id = se.id
+			LEFT JOIN held hd ON hd.showtime_id = s.id AND hd.seat_id = se.id
+			LEFT JOIN blocked bl ON bl.showtime_id = s.id AND bl.seat_id = se.id
+			WHERE s.id = ANY($1)
+		)
+		SELECT ss.showtime_id, st.id, st.name,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE ss.state = 'available'),
+		       COUNT(*) FILTER (WHERE ss.state = 'held'),
+		       COUNT(*) FILTER (WHERE ss.state = 'booked'),
+		       COUNT(*) FILTER (WHERE ss.state = 'blocked')
+		FROM seat_states ss
+		JOIN seat_type st ON ss.seat_type_id = st.id
+		GROUP BY ss.showtime_id, st.id, st.name
+		ORDER BY st.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(uuidStrings(showtimeIDs)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	availability := make(map[uuid.UUID][]entities.SeatAvailability)
+	for rows.Next() {
+		var showtimeID uuid.UUID
+		var counts entities.SeatAvailability
+		err := rows.Scan(
+			&showtimeID, &counts.SeatTypeID, &counts.SeatType,
+			&counts.Total, &counts.Available, &counts.Held, &counts.Booked, &counts.Blocked,
+		)
+		if err != nil {
+			return nil, err
+		}
+		availability[showtimeID] = append(availability[showtimeID], counts)
+	}
+
+	return availability, rows.Err()
+}
+
 func (r *ShowtimeRepository) scanShowtimes(rows *sql.Rows) ([]entities.Showtime, error) {
 	var showtimes []entities.Showtime
 
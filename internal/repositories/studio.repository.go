@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+type IStudioRepository interface {
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.Studio, error)
+	FindSeats(ctx context.Context, studioID uuid.UUID) ([]entities.Seat, error)
+	FindSeatTypes(ctx context.Context, cinemaID uuid.UUID) ([]entities.SeatType, error)
+	FindSeatsInUse(ctx context.Context, seatIDs []uuid.UUID) ([]uuid.UUID, error)
+	ApplyLayout(ctx context.Context, studio *entities.Studio, seats []entities.Seat, gaps []entities.StudioGap) error
+}
+
+type StudioRepository struct {
+	db *sql.DB
+}
+
+func NewStudioRepository(db *sql.DB) IStudioRepository {
+	return &StudioRepository{db: db}
+}
+
+// FindByID returns a studio with its theater.
+func (r *StudioRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.Studio, error) {
+	query := `
+		SELECT st.id, st.name, st.theater_id, st.screen_position,
+		       th.id, th.name, th.cinema_id
+		FROM studios st
+		JOIN theaters th ON st.theater_id = th.id
+		WHERE st.id = $1
+	`
+
+	var studio entities.Studio
+	var theater entities.Theater
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&studio.ID, &studio.Name, &studio.TheaterID, &studio.ScreenPosition,
+		&theater.ID, &theater.Name, &theater.CinemaID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	studio.Theater = &theater
+	return &studio, nil
+}
+
+// FindSeats returns every seat of a studio with its type, those taken off
+// sale included, in seat map order.
+func (r *StudioRepository) FindSeats(ctx context.Context, studioID uuid.UUID) ([]entities.Seat, error) {
+	query := `
+		SELECT s.id, s.row, s.number, s.active, s.studio_id, s.seat_type_id,
+		       s.grid_row, s.grid_column, s.wheelchair, s.companion, s.paired_with_id,
+		       st.id, st.name
+		FROM seats s
+		JOIN seat_type st ON s.seat_type_id = st.id
+		WHERE s.studio_id = $1
+		ORDER BY s.grid_row, s.grid_column, s.active DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, studioID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seats []entities.Seat
+	for rows.Next() {
+		var seat entities.Seat
+		var seatType entities.SeatType
+
+		err := rows.Scan(
+			&seat.ID, &seat.Row, &seat.Number, &seat.Active, &seat.StudioID, &seat.SeatTypeID,
+			&seat.GridRow, &seat.GridColumn, &seat.Wheelchair, &seat.Companion, &seat.PairedWithID,
+			&seatType.ID, &seatType.Name,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		seat.SeatType = &seatType
+		seats = append(seats, seat)
+	}
+
+	return seats, rows.Err()
+}
+
+// FindSeatTypes returns the seat types of a cinema.
+func (r *StudioRepository) FindSeatTypes(ctx context.Context, cinemaID uuid.UUID) ([]entities.SeatType, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, cinema_id FROM seat_type WHERE cinema_id = $1 ORDER BY name
+	`, cinemaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seatTypes []entities.SeatType
+	for rows.Next() {
+		var seatType entities.SeatType
+		if err := rows.Scan(&seatType.ID, &seatType.Name, &seatType.CinemaID); err != nil {
+			return nil, err
+		}
+		seatTypes = append(seatTypes, seatType)
+	}
+
+	return seatTypes, rows.Err()
+}
+
+// FindSeatsInUse returns which of the given seats are taken for a showtime
+// that hasn't started yet, by a booking or a waitlist hold.
+func (r *StudioRepository) FindSeatsInUse(ctx context.Context, seatIDs []uuid.UUID) ([]uuid.UUID, error) {
+	return findSeatsInUse(ctx, r.db, seatIDs)
+}
+
+// ApplyLayout replaces the seat map of a studio, all or nothing. Seats are
+// never deleted, so transaction items keep pointing at them: seats left out
+// are taken off sale, and seats with a known ID are updated in place. The
+// given seats are put on sale and gaps replace the current ones. It returns
+// ErrSeatsTaken if a seat taken off sale is booked for an upcoming showtime.
+func (r *StudioRepository) ApplyLayout(ctx context.Context, studio *entities.Studio, seats []entities.Seat, gaps []entities.StudioGap) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	// Lock the studio so concurrent imports apply one after the other.
+	_, err = dbTx.ExecContext(ctx, `
+		UPDATE studios SET screen_position = $2 WHERE id = $1
+	`, studio.ID, studio.ScreenPosition)
+	if err != nil {
+		return fmt.Errorf("failed to update studio: %w", err)
+	}
+
+	keep := make([]string, len(seats))
+	for i, seat := range seats {
+		keep[i] = seat.ID.String()
+	}
+
+	rows, err := dbTx.QueryContext(ctx, `
+		SELECT id FROM seats WHERE studio_id = $1 AND active AND NOT id = ANY($2)
+	`, studio.ID, pq.Array(keep))
+	if err != nil {
+		return fmt.Errorf("failed to find seats to take off sale: %w", err)
+	}
+	var removed []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		removed = append(removed, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(removed) > 0 {
+		inUse, err := findSeatsInUse(ctx, dbTx, removed)
+		if err != nil {
+			return fmt.Errorf("failed to check seats in use: %w", err)
+		}
+		if len(inUse) > 0 {
+			return ErrSeatsTaken
+		}
+	}
+
+	// Taking every seat off sale first frees all cells, so seats can swap
+	// places without tripping the one-active-seat-per-cell index.
+	_, err = dbTx.ExecContext(ctx, `
+		UPDATE seats SET active = false, paired_with_id = NULL WHERE studio_id = $1
+	`, studio.ID)
+	if err != nil {
+		return fmt.Errorf("failed to take seats off sale: %w", err)
+	}
+
+	seatQuery := `
+		INSERT INTO seats (id, studio_id, row, number, seat_type_id, active, grid_row, grid_column, wheelchair, companion)
+		VALUES ($1, $2, $3, $4, $5, true, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			row = EXCLUDED.row, number = EXCLUDED.number, seat_type_id = EXCLUDED.seat_type_id, active = true,
+			grid_row = EXCLUDED.grid_row, grid_column = EXCLUDED.grid_column,
+			wheelchair = EXCLUDED.wheelchair, companion = EXCLUDED.companion
+	`
+	for _, seat := range seats {
+		_, err := dbTx.ExecContext(ctx, seatQuery,
+			seat.ID, studio.ID, seat.Row, seat.Number, seat.SeatTypeID,
+			seat.GridRow, seat.GridColumn, seat.Wheelchair, seat.Companion,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save seat %s%d: %w", seat.Row, seat.Number, err)
+		}
+	}
+
+	// Pairs are set once every seat exists, as each half points at the other.
+	for _, seat := range seats {
+		if seat.PairedWithID == nil {
+			continue
+		}
+		_, err := dbTx.ExecContext(ctx, `UPDATE seats SET paired_with_id = $2 WHERE id = $1`, seat.ID, seat.PairedWithID)
+		if err != nil {
+			return fmt.Errorf("failed to pair seat %s%d: %w", seat.Row, seat.Number, err)
+		}
+	}
+
+	if _, err := dbTx.ExecContext(ctx, `DELETE FROM studio_gaps WHERE studio_id = $1`, studio.ID); err != nil {
+		return fmt.Errorf("failed to clear gaps: %w", err)
+	}
+	gapQuery := `
+		INSERT INTO studio_gaps (id, studio_id, grid_row, grid_column, kind)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, gap := range gaps {
+		_, err := dbTx.ExecContext(ctx, gapQuery, gap.ID, studio.ID, gap.GridRow, gap.GridColumn, gap.Kind)
+		if err != nil {
+			return fmt.Errorf("failed to save gap: %w", err)
+		}
+	}
+
+	return dbTx.Commit()
+}
+
+func findSeatsInUse(ctx context.Context, q queryer, seatIDs []uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT ti.seat_id
+		FROM transaction_items ti
+		JOIN transactions t ON ti.transaction_id = t.id
+		JOIN showtimes s ON t.showtime_id = s.id
+		WHERE ti.seat_id = ANY($1) AND ti.replaced_at IS NULL AND ` + seatHoldingCondition + ` AND s.time > NOW()
+		UNION
+		SELECT h.seat_id
+		FROM seat_holds h
+		JOIN showtimes s ON h.showtime_id = s.id
+		WHERE h.seat_id = ANY($1) AND ` + activeHoldCondition + ` AND s.time > NOW()
+	`
+
+	rows, err := q.QueryContext(ctx, query, pq.Array(uuidStrings(seatIDs)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var inUse []uuid.UUID
+	for rows.Next() {
+		var seatID uuid.UUID
+		if err := rows.Scan(&seatID); err != nil {
+			return nil, err
+		}
+		inUse = append(inUse, seatID)
+	}
+
+	return inUse, rows.Err()
+}
@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+type ITransferRepository interface {
+	Create(ctx context.Context, transfer *entities.TicketTransfer) (bool, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.TicketTransfer, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]entities.TicketTransfer, error)
+	FindByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]entities.TicketTransfer, error)
+	Accept(ctx context.Context, id uuid.UUID) (bool, error)
+	Close(ctx context.Context, id uuid.UUID, status string) (bool, error)
+}
+
+type TransferRepository struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) ITransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// Create offers a seat to another user. It reports false when the seat is
+// already on offer to someone.
+func (r *TransferRepository) Create(ctx context.Context, transfer *entities.TicketTransfer) (bool, error) {
+	query := `
+		INSERT INTO ticket_transfers (id, transaction_id, transaction_item_id, from_user_id, to_user_id, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (transaction_item_id) WHERE status = 'pending' DO NOTHING
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		transfer.ID, transfer.TransactionID, transfer.TransactionItemID,
+		transfer.FromUserID, transfer.ToUserID, transfer.Status,
+	).Scan(&transfer.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *TransferRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.TicketTransfer, error) {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM ticket_transfers tt ` + transferJoins + `
+		WHERE tt.id = $1
+	`
+
+	return scanTransfer(r.db.QueryRowContext(ctx, query, id))
+}
+
+// FindByUserID returns the transfers a user sent or received, newest first.
+func (r *TransferRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]entities.TicketTransfer, error) {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM ticket_transfers tt ` + transferJoins + `
+		WHERE tt.from_user_id = $1 OR tt.to_user_id = $1
+		ORDER BY tt.created_at DESC
+	`
+
+	return r.findTransfers(ctx, query, userID)
+}
+
+// FindByTransactionID returns every transfer of a transaction's seats, oldest
+// first.
+func (r *TransferRepository) FindByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]entities.TicketTransfer, error) {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM ticket_transfers tt ` + transferJoins + `
+		WHERE tt.transaction_id = $1
+		ORDER BY tt.created_at
+	`
+
+	return r.findTransfers(ctx, query, transactionID)
+}
+
+// Accept hands the seat to the recipient. The sender must still hold the seat
+// of a paid transaction, and it must not have been admitted or replaced by a
+// booking change; otherwise the transfer is cancelled instead. It reports
+// false when the transfer did not go through.
+func (r *TransferRepository) Accept(ctx context.Context, id uuid.UUID) (bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	var itemID, fromUserID, toUserID uuid.UUID
+	err = dbTx.QueryRowContext(ctx,
+		`SELECT transaction_item_id, from_user_id, to_user_id FROM ticket_transfers WHERE id = $1 AND status = 'pending' FOR UPDATE`,
+		id,
+	).Scan(&itemID, &fromUserID, &toUserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// The booking is locked like refunds and check-ins lock it, so a seat can't
+	// change hands while the booking is being refunded.
+	_, err = dbTx.ExecContext(ctx, `
+		SELECT 1 FROM transactions t
+		JOIN transaction_items ti ON ti.transaction_id = t.id
+		WHERE ti.id = $1
+		FOR UPDATE OF t
+	`, itemID)
+	if err != nil {
+		return false, err
+	}
+
+	// The owner's seats have no holder, so handing one back clears it.
+	res, err := dbTx.ExecContext(ctx, `
+		UPDATE transaction_items ti
+		SET holder_id = NULLIF($3::uuid, t.user_id)
+		FROM transactions t
+		WHERE ti.id = $1 AND ti.transaction_id = t.id
+		AND t.status = $4 AND ti.replaced_at IS NULL AND ti.checked_in_at IS NULL
+		AND COALESCE(ti.holder_id, t.user_id) = $2
+	`, itemID, fromUserID, toUserID, entities.TransactionStatusPaid)
+	if err != nil {
+		return false, err
+	}
+	moved, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	status := entities.TransferStatusAccepted
+	if moved == 0 {
+		status = entities.TransferStatusCancelled
+	}
+	_, err = dbTx.ExecContext(ctx,
+		`UPDATE ticket_transfers SET status = $2, responded_at = NOW() WHERE id = $1`,
+		id, status,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return moved == 1, dbTx.Commit()
+}
+
+// Close ends a pending transfer with the given status, declined or cancelled.
+// It reports false when the transfer was no longer pending.
+func (r *TransferRepository) Close(ctx context.Context, id uuid.UUID, status string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE ticket_transfers SET status = $2, responded_at = NOW() WHERE id = $1 AND status = 'pending'`,
+		id, status,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *TransferRepository) findTransfers(ctx context.Context, query string, args ...any) ([]entities.TicketTransfer, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []entities.TicketTransfer
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, *transfer)
+	}
+
+	return transfers, rows.Err()
+}
+
+// transferColumns selects a transfer (aliased tt) with its users, seat,
+// showtime and movie, joined by transferJoins.
+const transferColumns = `tt.id, tt.transaction_id, tt.transaction_item_id, tt.from_user_id, tt.to_user_id, tt.status, tt.responded_at, tt.created_at,
+		       fu.name, fu.email, tu.name, tu.email,
+		       s.id, s.row, s.number,
+		       sh.id, sh.time, sh.theater_id,
+		       m.id, m.title, m.poster_url`
+
+const transferJoins = `
+		JOIN users fu ON tt.from_user_id = fu.id
+		JOIN users tu ON tt.to_user_id = tu.id
+		JOIN transaction_items ti ON tt.transaction_item_id = ti.id
+		JOIN seats s ON ti.seat_id = s.id
+		JOIN transactions t ON tt.transaction_id = t.id
+		JOIN showtimes sh ON t.showtime_id = sh.id
+		JOIN movies m ON sh.movie_id = m.id`
+
+// scanTransfer reads a row selected with transferColumns.
+func scanTransfer(row interface{ Scan(dest ...any) error }) (*entities.TicketTransfer, error) {
+	var transfer entities.TicketTransfer
+	var from, to entities.User
+	var seat entities.Seat
+	var showtime entities.Showtime
+	var movie entities.Movie
+
+	err := row.Scan(
+		&transfer.ID, &transfer.TransactionID, &transfer.TransactionItemID, &transfer.FromUserID, &transfer.ToUserID,
+		&transfer.Status, &transfer.RespondedAt, &transfer.CreatedAt,
+		&from.Name, &from.Email, &to.Name, &to.Email,
+		&seat.ID, &seat.Row, &seat.Number,
+		&showtime.ID, &showtime.Time, &showtime.TheaterID,
+		&movie.ID, &movie.Title, &movie.PosterURL,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	from.ID = transfer.FromUserID
+	to.ID = transfer.ToUserID
+	showtime.Movie = &movie
+	transfer.FromUser = &from
+	transfer.ToUser = &to
+	transfer.Seat = &seat
+	transfer.Showtime = &showtime
+	return &transfer, nil
+}
@@ -13,6 +13,7 @@ type IUserRepository interface {
 	FindByEmail(ctx context.Context, email string) (*entities.User, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
 	GetRoleByName(ctx context.Context, name string) (*entities.Role, error)
+	GetRoleByID(ctx context.Context, id uuid.UUID) (*entities.Role, error)
 }
 
 type UserRepository struct {
@@ -63,3 +64,13 @@ func (r *UserRepository) GetRoleByName(ctx context.Context, name string) (*entit
 	}
 	return role, nil
 }
+
+func (r *UserRepository) GetRoleByID(ctx context.Context, id uuid.UUID) (*entities.Role, error) {
+	role := &entities.Role{}
+	query := `SELECT id, name FROM roles WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&role.ID, &role.Name)
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
@@ -0,0 +1,380 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+type IWaitlistRepository interface {
+	Create(ctx context.Context, entry *entities.WaitlistEntry) (bool, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.WaitlistEntry, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]entities.WaitlistEntry, error)
+	Cancel(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
+	LapseEntries(ctx context.Context) (int64, error)
+	FindWaitingShowtimeIDs(ctx context.Context) ([]uuid.UUID, error)
+	NextWaiting(ctx context.Context, showtimeID uuid.UUID) (*entities.WaitlistEntry, error)
+	Offer(ctx context.Context, entryID uuid.UUID, seatIDs []uuid.UUID, expiresAt time.Time) (bool, error)
+}
+
+type WaitlistRepository struct {
+	db *sql.DB
+}
+
+func NewWaitlistRepository(db *sql.DB) IWaitlistRepository {
+	return &WaitlistRepository{db: db}
+}
+
+// Create puts a user in line for a showtime. It reports false when the user
+// is already waiting for it or holding seats offered from it.
+func (r *WaitlistRepository) Create(ctx context.Context, entry *entities.WaitlistEntry) (bool, error) {
+	query := `
+		INSERT INTO waitlist_entries (id, showtime_id, user_id, seat_count, status)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (showtime_id, user_id) WHERE status IN ('waiting', 'offered') DO NOTHING
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		entry.ID, entry.ShowtimeID, entry.UserID, entry.SeatCount, entry.Status,
+	).Scan(&entry.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *WaitlistRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistEntryColumns + `
+		FROM waitlist_entries w
+		JOIN showtimes s ON w.showtime_id = s.id
+		JOIN movies m ON s.movie_id = m.id
+		WHERE w.id = $1
+	`
+
+	entry, err := scanWaitlistEntry(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Holds, err = r.findHolds(ctx, entry.ID); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// FindByUserID returns every waitlist entry of a user, newest first.
+func (r *WaitlistRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]entities.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistEntryColumns + `
+		FROM waitlist_entries w
+		JOIN showtimes s ON w.showtime_id = s.id
+		JOIN movies m ON s.movie_id = m.id
+		WHERE w.user_id = $1
+		ORDER BY w.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []entities.WaitlistEntry
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		if entries[i].Status != entities.WaitlistStatusOffered {
+			continue
+		}
+		if entries[i].Holds, err = r.findHolds(ctx, entries[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// Cancel takes a user out of line, giving back any seats held for them. It
+// reports false when the entry is no longer open.
+func (r *WaitlistRepository) Cancel(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	res, err := dbTx.ExecContext(ctx, `
+		UPDATE waitlist_entries SET status = $3
+		WHERE id = $1 AND user_id = $2 AND status IN ($4, $5)
+	`, id, userID, entities.WaitlistStatusCancelled, entities.WaitlistStatusWaiting, entities.WaitlistStatusOffered)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil || affected != 1 {
+		return false, err
+	}
+
+	_, err = dbTx.ExecContext(ctx, `UPDATE seat_holds SET released_at = NOW() WHERE waitlist_entry_id = $1 AND released_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+
+	return true, dbTx.Commit()
+}
+
+// LapseEntries closes offers whose hold ran out and entries still waiting for
+// a showtime that has started. It returns how many entries were closed.
+func (r *WaitlistRepository) LapseEntries(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE waitlist_entries w SET status = $1
+		FROM showtimes s
+		WHERE w.showtime_id = s.id
+		AND (
+			(w.status = $2 AND w.offer_expires_at <= NOW())
+			OR (w.status = $3 AND s.time <= NOW())
+		)
+	`
+
+	res, err := r.db.ExecContext(ctx, query,
+		entities.WaitlistStatusLapsed, entities.WaitlistStatusOffered, entities.WaitlistStatusWaiting,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// FindWaitingShowtimeIDs returns the upcoming showtimes someone is waiting for.
+func (r *WaitlistRepository) FindWaitingShowtimeIDs(ctx context.Context) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT w.showtime_id
+		FROM waitlist_entries w
+		JOIN showtimes s ON w.showtime_id = s.id
+		WHERE w.status = $1 AND s.time > NOW()
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, entities.WaitlistStatusWaiting)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// NextWaiting returns the entry at the front of a showtime's line, or nil if
+// nobody is waiting.
+func (r *WaitlistRepository) NextWaiting(ctx context.Context, showtimeID uuid.UUID) (*entities.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistEntryColumns + `
+		FROM waitlist_entries w
+		JOIN showtimes s ON w.showtime_id = s.id
+		JOIN movies m ON s.movie_id = m.id
+		WHERE w.showtime_id = $1 AND w.status = $2
+		ORDER BY w.created_at
+		LIMIT 1
+	`
+
+	entry, err := scanWaitlistEntry(r.db.QueryRowContext(ctx, query, showtimeID, entities.WaitlistStatusWaiting))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return entry, err
+}
+
+// Offer holds the given seats for a waiting entry until expiresAt. The seats
+// are locked and checked like a booking would, so a hold never overlaps a
+// booking or another hold. It reports false when the entry stopped waiting.
+func (r *WaitlistRepository) Offer(ctx context.Context, entryID uuid.UUID, seatIDs []uuid.UUID, expiresAt time.Time) (bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	var showtimeID, userID uuid.UUID
+	err = dbTx.QueryRowContext(ctx, `
+		UPDATE waitlist_entries SET status = $2, offered_at = NOW(), offer_expires_at = $3
+		WHERE id = $1 AND status = $4
+		RETURNING showtime_id, user_id
+	`, entryID, entities.WaitlistStatusOffered, expiresAt, entities.WaitlistStatusWaiting).Scan(&showtimeID, &userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := lockShowtimeSeats(ctx, dbTx, showtimeID, seatIDs); err != nil {
+		return false, fmt.Errorf("failed to lock seats: %w", err)
+	}
+
+	taken, err := countTakenSeats(ctx, dbTx, showtimeID, seatIDs, uuid.Nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to check seat availability: %w", err)
+	}
+	held, err := countHeldSeats(ctx, dbTx, showtimeID, seatIDs, uuid.Nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to check seat holds: %w", err)
+	}
+	blocked, err := countBlockedSeats(ctx, dbTx, showtimeID, seatIDs, uuid.Nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to check seat blocks: %w", err)
+	}
+	if taken > 0 || held > 0 || blocked > 0 {
+		return false, ErrSeatsTaken
+	}
+
+	holdQuery := `
+		INSERT INTO seat_holds (id, showtime_id, seat_id, user_id, waitlist_entry_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	for _, seatID := range seatIDs {
+		_, err = dbTx.ExecContext(ctx, holdQuery, uuid.New(), showtimeID, seatID, userID, entryID, expiresAt)
+		if err != nil {
+			return false, fmt.Errorf("failed to insert seat hold: %w", err)
+		}
+	}
+
+	return true, dbTx.Commit()
+}
+
+func (r *WaitlistRepository) findHolds(ctx context.Context, entryID uuid.UUID) ([]entities.SeatHold, error) {
+	query := `
+		SELECT h.id, h.showtime_id, h.seat_id, h.user_id, h.waitlist_entry_id, h.expires_at, h.released_at, h.created_at,
+		       s.id, s.row, s.number
+		FROM seat_holds h
+		JOIN seats s ON h.seat_id = s.id
+		WHERE h.waitlist_entry_id = $1
+		ORDER BY s.row, s.number
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []entities.SeatHold
+	for rows.Next() {
+		var hold entities.SeatHold
+		var seat entities.Seat
+		err := rows.Scan(
+			&hold.ID, &hold.ShowtimeID, &hold.SeatID, &hold.UserID, &hold.WaitlistEntryID, &hold.ExpiresAt, &hold.ReleasedAt, &hold.CreatedAt,
+			&seat.ID, &seat.Row, &seat.Number,
+		)
+		if err != nil {
+			return nil, err
+		}
+		hold.Seat = &seat
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+// waitlistEntryColumns selects an entry (aliased w) with its showtime (s) and
+// movie (m). The position counts entries created earlier that still wait.
+const waitlistEntryColumns = `w.id, w.showtime_id, w.user_id, w.seat_count, w.status, w.offered_at, w.offer_expires_at, w.created_at,
+		       CASE WHEN w.status = 'waiting' THEN (
+		           SELECT COUNT(*) + 1 FROM waitlist_entries ahead
+		           WHERE ahead.showtime_id = w.showtime_id AND ahead.status = 'waiting' AND ahead.created_at < w.created_at
+		       ) ELSE 0 END,
+		       s.id, s.time, s.movie_id, s.studio_id, s.theater_id,
+		       m.id, m.title, m.poster_url`
+
+// scanWaitlistEntry reads a row selected with waitlistEntryColumns.
+func scanWaitlistEntry(row interface{ Scan(dest ...any) error }) (*entities.WaitlistEntry, error) {
+	var entry entities.WaitlistEntry
+	var showtime entities.Showtime
+	var movie entities.Movie
+
+	err := row.Scan(
+		&entry.ID, &entry.ShowtimeID, &entry.UserID, &entry.SeatCount, &entry.Status, &entry.OfferedAt, &entry.OfferExpiresAt, &entry.CreatedAt,
+		&entry.Position,
+		&showtime.ID, &showtime.Time, &showtime.MovieID, &showtime.StudioID, &showtime.TheaterID,
+		&movie.ID, &movie.Title, &movie.PosterURL,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	showtime.Movie = &movie
+	entry.Showtime = &showtime
+	return &entry, nil
+}
+
+// countHeldSeats counts the given seats held for the showtime for anyone but
+// holderID; pass uuid.Nil to count every hold. Seats held for a booking change
+// count even for its owner, as only settling the change may take them.
+func countHeldSeats(ctx context.Context, q queryer, showtimeID uuid.UUID, seatIDs []uuid.UUID, holderID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM seat_holds h
+		WHERE h.showtime_id = $1
+		AND ` + activeHoldCondition + `
+		AND h.seat_id = ANY($2)
+		AND (h.user_id <> $3 OR h.amendment_id IS NOT NULL)
+	`
+
+	seatIDStrings := make([]string, len(seatIDs))
+	for i, id := range seatIDs {
+		seatIDStrings[i] = id.String()
+	}
+
+	var count int
+	err := q.QueryRowContext(ctx, query, showtimeID, pq.Array(seatIDStrings), holderID).Scan(&count)
+	return count, err
+}
+
+// consumeHolds releases the waitlist holds a user has for a showtime once they
+// book it, fulfilling the offer behind them.
+func consumeHolds(ctx context.Context, q queryer, showtimeID uuid.UUID, userID uuid.UUID) error {
+	query := `
+		WITH released AS (
+			UPDATE seat_holds h SET released_at = NOW()
+			WHERE h.showtime_id = $1 AND h.user_id = $2 AND h.waitlist_entry_id IS NOT NULL AND ` + activeHoldCondition + `
+			RETURNING h.waitlist_entry_id
+		)
+		UPDATE waitlist_entries SET status = $3
+		WHERE id IN (SELECT waitlist_entry_id FROM released) AND status = $4
+	`
+
+	_, err := q.ExecContext(ctx, query, showtimeID, userID,
+		entities.WaitlistStatusFulfilled, entities.WaitlistStatusOffered,
+	)
+	return err
+}
@@ -74,7 +74,12 @@ func (s *AuthService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.Lo
 		return nil, ErrInvalidCredentials
 	}
 
-	tokenPair, err := utilities.GenerateTokenPair(user.ID, "user")
+	role, err := s.userRepository.GetRoleByID(ctx, user.RoleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	tokenPair, err := utilities.GenerateTokenPair(user.ID, role.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -104,7 +109,12 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *dto.RefreshTokenReq
 		return nil, utilities.ErrInvalidToken
 	}
 
-	tokenPair, err := utilities.GenerateTokenPair(user.ID, "user")
+	role, err := s.userRepository.GetRoleByID(ctx, user.RoleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	tokenPair, err := utilities.GenerateTokenPair(user.ID, role.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/integrations/payment"
+	"github.com/senatroxx/filmix-backend/internal/integrations/realtime"
 	"github.com/senatroxx/filmix-backend/internal/repositories"
 )
 
@@ -15,36 +17,194 @@ var (
 	ErrSeatsNotAvailable = errors.New("one or more seats are not available")
 	ErrShowtimeNotFound  = errors.New("showtime not found")
 	ErrBookingNotFound   = errors.New("booking not found")
+
+	ErrBookingNotCancellable = errors.New("booking can no longer be cancelled")
+	ErrRefundWindowClosed    = errors.New("refund window for this booking has closed")
+
+	ErrBookingNotAmendable   = errors.New("booking can no longer be changed")
+	ErrAmendmentWindowClosed = errors.New("change window for this booking has closed")
+	ErrInvalidAmendment      = errors.New("invalid booking change")
+
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+
+	ErrInvalidBookingFilter = errors.New("invalid booking filter")
+
+	ErrInvalidAddon    = errors.New("concession is not sold at this theater")
+	ErrAddonOutOfStock = errors.New("concession is out of stock")
+
+	ErrDuplicateSeats        = errors.New("seat requested more than once")
+	ErrSeatNotInStudio       = errors.New("seat is not in the showtime's studio")
+	ErrSeatInactive          = errors.New("seat is not for sale")
+	ErrPurchaseLimitExceeded = errors.New("purchase limit exceeded")
+)
+
+// SeatSelectionError names the requested seats that can't be booked. It wraps
+// ErrDuplicateSeats, ErrSeatNotInStudio or ErrSeatInactive.
+type SeatSelectionError struct {
+	Err     error
+	SeatIDs []uuid.UUID
+}
+
+func (e *SeatSelectionError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Err, e.SeatIDs)
+}
+
+func (e *SeatSelectionError) Unwrap() error {
+	return e.Err
+}
+
+// Names of the purchase limits, as reported by PurchaseLimitError.
+const (
+	LimitSeatsPerBooking  = "seats_per_booking"
+	LimitSeatsPerShowtime = "seats_per_showtime"
+	LimitPendingBookings  = "pending_bookings"
+	LimitBookingVelocity  = "booking_velocity"
 )
 
+// PurchaseLimitError reports the purchase limit a booking would exceed. It
+// wraps ErrPurchaseLimitExceeded.
+type PurchaseLimitError struct {
+	Limit string
+	Max   int
+}
+
+func (e *PurchaseLimitError) Error() string {
+	return fmt.Sprintf("%v: %s (max %d)", ErrPurchaseLimitExceeded, e.Limit, e.Max)
+}
+
+func (e *PurchaseLimitError) Unwrap() error {
+	return ErrPurchaseLimitExceeded
+}
+
 type CreateBookingInput struct {
 	UserID          uuid.UUID
 	ShowtimeID      uuid.UUID
 	SeatIDs         []uuid.UUID
 	PaymentMethodID uuid.UUID
+	PromoCode       string
+	Addons          []AddonInput
+}
+
+// AddonInput orders a quantity of a concession variant with the tickets.
+type AddonInput struct {
+	VariantID uuid.UUID
+	Quantity  int
+}
+
+type AmendBookingInput struct {
+	BookingID uuid.UUID
+	UserID    uuid.UUID
+	// ShowtimeID moves the booking to another showtime of the same movie at the
+	// same theater; nil keeps the current showtime.
+	ShowtimeID *uuid.UUID
+	SeatIDs    []uuid.UUID
+}
+
+// Values of BookingHistoryFilter.When.
+const (
+	BookingsUpcoming = "upcoming"
+	BookingsPast     = "past"
+)
+
+// BookingHistoryFilter selects a page of a user's bookings.
+type BookingHistoryFilter struct {
+	Statuses []string
+	// When is BookingsUpcoming or BookingsPast; empty means both.
+	When string
+	// From and To are showtime dates, both inclusive.
+	From *time.Time
+	To   *time.Time
+	// Ascending sorts by showtime earliest first. Nil picks earliest first for
+	// upcoming bookings and latest first otherwise.
+	Ascending *bool
+	Page      int
+	Limit     int
+}
+
+// RefundPolicy controls whether and how much of a paid booking is refunded
+// when its owner cancels it.
+type RefundPolicy struct {
+	// Cutoff is how long before the showtime refunds stop being accepted. Paid
+	// bookings can't be changed past it either.
+	Cutoff time.Duration
+	// FeePercent of the booking amount is withheld from every refund.
+	FeePercent int
+}
+
+// PurchaseLimits keeps single customers from sweeping up a showtime. A limit
+// of zero is not enforced.
+type PurchaseLimits struct {
+	// MaxSeatsPerBooking caps the seats of a single booking.
+	MaxSeatsPerBooking int
+	// MaxSeatsPerShowtime caps the seats a user holds for one showtime across
+	// all their bookings.
+	MaxSeatsPerShowtime int
+	// MaxPendingBookings caps how many of a user's bookings await payment at
+	// the same time.
+	MaxPendingBookings int
+	// MaxBookingsPerWindow caps how many bookings a user creates within any
+	// VelocityWindow.
+	MaxBookingsPerWindow int
+	VelocityWindow       time.Duration
 }
 
 type IBookingService interface {
 	CreateBooking(ctx context.Context, input CreateBookingInput) (*entities.Transaction, error)
 	GetBookingByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.Transaction, error)
-	GetUserBookings(ctx context.Context, userID uuid.UUID) ([]entities.Transaction, error)
+	GetBookingTimeline(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]entities.TransactionStatusHistory, error)
+	GetUserBookings(ctx context.Context, userID uuid.UUID, filter BookingHistoryFilter) ([]entities.Transaction, int, error)
+	CancelBooking(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.Transaction, error)
+	AmendBooking(ctx context.Context, input AmendBookingInput) (*entities.Transaction, error)
+	CreatePriorityHold(ctx context.Context, entry *entities.WaitlistEntry, expiresAt time.Time) error
+	ExpireStaleBookings(ctx context.Context, batchSize int) (int, error)
+	ExpireStaleAmendments(ctx context.Context, batchSize int) (int, error)
 }
 
 type BookingService struct {
-	bookingRepo  repositories.IBookingRepository
-	showtimeRepo repositories.IShowtimeRepository
-	seatRepo     repositories.ISeatRepository
+	bookingRepo       repositories.IBookingRepository
+	showtimeRepo      repositories.IShowtimeRepository
+	seatRepo          repositories.ISeatRepository
+	paymentMethodRepo repositories.IPaymentMethodRepository
+	waitlistRepo      repositories.IWaitlistRepository
+	concessionRepo    repositories.IConcessionRepository
+	pricingService    IPricingService
+	promotionService  IPromotionService
+	feeService        IFeeService
+	payments          *payment.Registry
+	refunds           RefundPolicy
+	limits            PurchaseLimits
+	seatEvents        realtime.Publisher
 }
 
 func NewBookingService(
 	bookingRepo repositories.IBookingRepository,
 	showtimeRepo repositories.IShowtimeRepository,
 	seatRepo repositories.ISeatRepository,
+	paymentMethodRepo repositories.IPaymentMethodRepository,
+	waitlistRepo repositories.IWaitlistRepository,
+	concessionRepo repositories.IConcessionRepository,
+	pricingService IPricingService,
+	promotionService IPromotionService,
+	feeService IFeeService,
+	payments *payment.Registry,
+	refunds RefundPolicy,
+	limits PurchaseLimits,
+	seatEvents realtime.Publisher,
 ) IBookingService {
 	return &BookingService{
-		bookingRepo:  bookingRepo,
-		showtimeRepo: showtimeRepo,
-		seatRepo:     seatRepo,
+		bookingRepo:       bookingRepo,
+		showtimeRepo:      showtimeRepo,
+		seatRepo:          seatRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		waitlistRepo:      waitlistRepo,
+		concessionRepo:    concessionRepo,
+		pricingService:    pricingService,
+		promotionService:  promotionService,
+		feeService:        feeService,
+		payments:          payments,
+		refunds:           refunds,
+		limits:            limits,
+		seatEvents:        seatEvents,
 	}
 }
 
@@ -54,17 +214,207 @@ func (s *BookingService) CreateBooking(ctx context.Context, input CreateBookingI
 		return nil, ErrShowtimeNotFound
 	}
 
-	available, err := s.bookingRepo.CheckSeatsAvailable(ctx, input.ShowtimeID, input.SeatIDs)
+	method, err := s.paymentMethodRepo.FindByID(ctx, input.PaymentMethodID)
+	if err != nil || !method.Active {
+		return nil, ErrPaymentMethodNotFound
+	}
+
+	provider, err := s.payments.Get(method.Code)
+	if err != nil {
+		return nil, ErrPaymentMethodNotFound
+	}
+
+	if err := s.validateSeats(ctx, showtime, input.SeatIDs); err != nil {
+		return nil, err
+	}
+	if err := s.checkSeatsPerBooking(len(input.SeatIDs)); err != nil {
+		return nil, err
+	}
+
+	txID := uuid.New()
+
+	items, totalAmount, err := s.priceSeats(ctx, showtime, txID, input.SeatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	addons, addonAmount, err := s.priceAddons(ctx, showtime.TheaterID, txID, input.Addons)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check seat availability: %w", err)
+		return nil, err
+	}
+
+	var promotionID *uuid.UUID
+	var discount int64
+	if input.PromoCode != "" {
+		promo, amount, err := s.promotionService.Apply(ctx, ApplyPromotionInput{
+			Code:            input.PromoCode,
+			Showtime:        showtime,
+			PaymentMethodID: input.PaymentMethodID,
+			Items:           items,
+		})
+		if err != nil {
+			return nil, err
+		}
+		promotionID = &promo.ID
+		discount = amount
 	}
-	if !available {
-		return nil, ErrSeatsNotAvailable
+
+	charges, err := s.feeService.Calculate(ctx, FeeInput{
+		TransactionID:       txID,
+		TheaterID:           showtime.TheaterID,
+		PaymentMethodTypeID: method.PaymentMethodTypeID,
+		Tickets:             len(items),
+		Base:                totalAmount - discount + addonAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The invoice number is only issued once the booking is paid, so holds
+	// that lapse never use one up.
+	tx := &entities.Transaction{
+		ID:              txID,
+		Status:          entities.TransactionStatusPending,
+		Amount:          totalAmount - discount + addonAmount + sumCharges(charges),
+		DiscountAmount:  discount,
+		PromotionID:     promotionID,
+		ExpiredAt:       time.Now().Add(15 * time.Minute), // 15 minutes to pay
+		PaymentMethodID: input.PaymentMethodID,
+		ShowtimeID:      input.ShowtimeID,
+		TheaterID:       showtime.TheaterID,
+		UserID:          input.UserID,
 	}
 
+	// Availability and purchase limits are checked inside the insert
+	// transaction while the seats and the user's bookings are locked, so a
+	// concurrent booking cannot slip in between.
+	err = s.bookingRepo.Create(ctx, tx, items, addons, charges, s.purchaseCheck(len(input.SeatIDs), false))
+	if err != nil {
+		if errors.Is(err, ErrPurchaseLimitExceeded) {
+			return nil, err
+		}
+		if errors.Is(err, repositories.ErrSeatsTaken) {
+			return nil, ErrSeatsNotAvailable
+		}
+		if errors.Is(err, repositories.ErrPromotionExhausted) {
+			return nil, ErrPromoUsageLimitReached
+		}
+		if errors.Is(err, repositories.ErrAddonsOutOfStock) {
+			return nil, ErrAddonOutOfStock
+		}
+		return nil, fmt.Errorf("failed to create booking: %w", err)
+	}
+	publishSeats(ctx, s.seatEvents, showtime.ID, realtime.SeatHeld, input.SeatIDs, &tx.ExpiredAt)
+
+	// The charge is only opened once the seats are ours, so a lost seat race
+	// never leaves a dangling charge at the provider. The reference stays unset
+	// until the provider assigns one.
+	if err := s.openCharge(ctx, provider, tx); err != nil {
+		_ = s.bookingRepo.Expire(ctx, txID, entities.StatusChange{
+			Actor:  entities.ActorSystem,
+			Reason: "payment charge could not be created",
+		})
+		publishSeats(ctx, s.seatEvents, showtime.ID, realtime.SeatReleased, input.SeatIDs, nil)
+		return nil, fmt.Errorf("failed to create payment charge: %w", err)
+	}
+
+	return s.bookingRepo.FindByID(ctx, txID)
+}
+
+// validateSeats checks that the requested seats are distinct, active seats of
+// the showtime's studio.
+func (s *BookingService) validateSeats(ctx context.Context, showtime *entities.Showtime, seatIDs []uuid.UUID) error {
+	seen := make(map[uuid.UUID]bool, len(seatIDs))
+	var duplicates []uuid.UUID
+	for _, id := range seatIDs {
+		if seen[id] {
+			duplicates = append(duplicates, id)
+		}
+		seen[id] = true
+	}
+	if len(duplicates) > 0 {
+		return &SeatSelectionError{Err: ErrDuplicateSeats, SeatIDs: duplicates}
+	}
+
+	seats, err := s.seatRepo.FindByIDs(ctx, seatIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get seats: %w", err)
+	}
+
+	found := make(map[uuid.UUID]entities.Seat, len(seats))
+	for _, seat := range seats {
+		found[seat.ID] = seat
+	}
+
+	var foreign, inactive []uuid.UUID
+	for _, id := range seatIDs {
+		seat, ok := found[id]
+		switch {
+		case !ok || seat.StudioID != showtime.StudioID:
+			foreign = append(foreign, id)
+		case !seat.Active:
+			inactive = append(inactive, id)
+		}
+	}
+	if len(foreign) > 0 {
+		return &SeatSelectionError{Err: ErrSeatNotInStudio, SeatIDs: foreign}
+	}
+	if len(inactive) > 0 {
+		return &SeatSelectionError{Err: ErrSeatInactive, SeatIDs: inactive}
+	}
+
+	return nil
+}
+
+// checkSeatsPerBooking checks a booking of seatCount seats against the one
+// purchase limit that doesn't depend on the user's other bookings.
+func (s *BookingService) checkSeatsPerBooking(seatCount int) error {
+	if s.limits.MaxSeatsPerBooking > 0 && seatCount > s.limits.MaxSeatsPerBooking {
+		return &PurchaseLimitError{Limit: LimitSeatsPerBooking, Max: s.limits.MaxSeatsPerBooking}
+	}
+	return nil
+}
+
+// purchaseCheck checks a booking of seatCount seats against the purchase
+// limits that count the user's other bookings. The repository runs it with
+// those bookings locked. When changing an existing booking only the seats per
+// showtime count: its seats are replaced, and it is not a new booking.
+func (s *BookingService) purchaseCheck(seatCount int, amending bool) *repositories.PurchaseCheck {
+	limits := s.limits
+	return &repositories.PurchaseCheck{
+		Since: time.Now().Add(-limits.VelocityWindow),
+		Check: func(activity *repositories.UserBookingActivity) error {
+			if limits.MaxSeatsPerShowtime > 0 && activity.ShowtimeSeats+seatCount > limits.MaxSeatsPerShowtime {
+				return &PurchaseLimitError{Limit: LimitSeatsPerShowtime, Max: limits.MaxSeatsPerShowtime}
+			}
+
+			if amending {
+				return nil
+			}
+
+			if limits.MaxPendingBookings > 0 && activity.PendingBookings >= limits.MaxPendingBookings {
+				return &PurchaseLimitError{Limit: LimitPendingBookings, Max: limits.MaxPendingBookings}
+			}
+			if limits.MaxBookingsPerWindow > 0 && limits.VelocityWindow > 0 && activity.RecentBookings >= limits.MaxBookingsPerWindow {
+				return &PurchaseLimitError{Limit: LimitBookingVelocity, Max: limits.MaxBookingsPerWindow}
+			}
+
+			return nil
+		},
+	}
+}
+
+// priceSeats builds the items of a booking for the given seats of a showtime
+// and returns them with their total.
+func (s *BookingService) priceSeats(ctx context.Context, showtime *entities.Showtime, txID uuid.UUID, seatIDs []uuid.UUID) ([]entities.TransactionItem, int64, error) {
 	seats, err := s.seatRepo.FindByStudioID(ctx, showtime.StudioID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get seats: %w", err)
+		return nil, 0, fmt.Errorf("failed to get seats: %w", err)
+	}
+
+	prices, err := s.pricingService.ResolveSeatPrices(ctx, showtime, seats)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to resolve seat prices: %w", err)
 	}
 
 	seatMap := make(map[uuid.UUID]entities.Seat)
@@ -74,15 +424,14 @@ func (s *BookingService) CreateBooking(ctx context.Context, input CreateBookingI
 
 	var totalAmount int64
 	var items []entities.TransactionItem
-	txID := uuid.New()
 
-	for _, seatID := range input.SeatIDs {
+	for _, seatID := range seatIDs {
 		seat, exists := seatMap[seatID]
 		if !exists {
-			return nil, fmt.Errorf("seat %s not found in studio", seatID)
+			return nil, 0, fmt.Errorf("seat %s not found in studio", seatID)
 		}
 
-		price := showtime.Pricing.Price
+		price := prices[seat.ID]
 
 		items = append(items, entities.TransactionItem{
 			ID:            uuid.New(),
@@ -95,26 +444,77 @@ func (s *BookingService) CreateBooking(ctx context.Context, input CreateBookingI
 		totalAmount += price
 	}
 
-	invoiceNumber := fmt.Sprintf("INV-%s", txID.String()[:8])
-	tx := &entities.Transaction{
-		ID:              txID,
-		Status:          "pending",
-		ExternalRef:     "",
-		InvoiceNumber:   &invoiceNumber,
-		Amount:          totalAmount,
-		ExpiredAt:       time.Now().Add(15 * time.Minute), // 15 minutes to pay
-		PaymentMethodID: input.PaymentMethodID,
-		ShowtimeID:      input.ShowtimeID,
-		TheaterID:       showtime.TheaterID,
-		UserID:          input.UserID,
+	return items, totalAmount, nil
+}
+
+// priceAddons turns the ordered concessions into transaction lines at their
+// current price. Repeated variants are merged into one line. Stock is checked
+// when the booking is stored.
+func (s *BookingService) priceAddons(ctx context.Context, theaterID uuid.UUID, txID uuid.UUID, inputs []AddonInput) ([]entities.TransactionAddon, int64, error) {
+	if len(inputs) == 0 {
+		return nil, 0, nil
 	}
 
-	err = s.bookingRepo.Create(ctx, tx, items)
+	quantities := make(map[uuid.UUID]int, len(inputs))
+	var ids []uuid.UUID
+	for _, in := range inputs {
+		if in.Quantity <= 0 {
+			return nil, 0, ErrInvalidAddon
+		}
+		if _, ok := quantities[in.VariantID]; !ok {
+			ids = append(ids, in.VariantID)
+		}
+		quantities[in.VariantID] += in.Quantity
+	}
+
+	variants, err := s.concessionRepo.FindVariantsByIDs(ctx, ids)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create booking: %w", err)
+		return nil, 0, fmt.Errorf("failed to get concessions: %w", err)
+	}
+	byID := make(map[uuid.UUID]entities.ConcessionVariant, len(variants))
+	for _, v := range variants {
+		byID[v.ID] = v
 	}
 
-	return s.bookingRepo.FindByID(ctx, txID)
+	addons := make([]entities.TransactionAddon, 0, len(ids))
+	var total int64
+	for _, id := range ids {
+		v, ok := byID[id]
+		if !ok || !v.Active || !v.Item.Active || v.Item.TheaterID != theaterID {
+			return nil, 0, ErrInvalidAddon
+		}
+		if v.Available < quantities[id] {
+			return nil, 0, ErrAddonOutOfStock
+		}
+		addons = append(addons, entities.TransactionAddon{
+			ID:            uuid.New(),
+			TransactionID: txID,
+			VariantID:     id,
+			Quantity:      quantities[id],
+			UnitPrice:     v.Price,
+		})
+		total += v.Price * int64(quantities[id])
+	}
+
+	return addons, total, nil
+}
+
+func (s *BookingService) openCharge(ctx context.Context, provider payment.Provider, tx *entities.Transaction) error {
+	charge, err := provider.CreateCharge(ctx, payment.ChargeRequest{
+		TransactionID: tx.ID,
+		Amount:        tx.Amount,
+		ExpiresAt:     tx.ExpiredAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.bookingRepo.AttachCharge(ctx, tx.ID, charge.Reference, &entities.PaymentInstructions{
+		Kind:      charge.Instructions.Kind,
+		VANumber:  charge.Instructions.VANumber,
+		Deeplink:  charge.Instructions.Deeplink,
+		ExpiresAt: charge.Instructions.ExpiresAt,
+	})
 }
 
 func (s *BookingService) GetBookingByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.Transaction, error) {
@@ -130,6 +530,607 @@ func (s *BookingService) GetBookingByID(ctx context.Context, id uuid.UUID, userI
 	return booking, nil
 }
 
-func (s *BookingService) GetUserBookings(ctx context.Context, userID uuid.UUID) ([]entities.Transaction, error) {
-	return s.bookingRepo.FindByUserID(ctx, userID)
+// GetBookingTimeline returns the status history of a booking, oldest first.
+func (s *BookingService) GetBookingTimeline(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]entities.TransactionStatusHistory, error) {
+	if _, err := s.GetBookingByID(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.bookingRepo.FindStatusHistory(ctx, id)
+}
+
+// GetUserBookings returns a page of a user's bookings, sorted by showtime, and
+// how many match the filter in total.
+func (s *BookingService) GetUserBookings(ctx context.Context, userID uuid.UUID, filter BookingHistoryFilter) ([]entities.Transaction, int, error) {
+	repoFilter := repositories.BookingFilter{
+		From:  filter.From,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}
+
+	for _, status := range filter.Statuses {
+		st := entities.TransactionStatus(status)
+		if !st.Valid() {
+			return nil, 0, ErrInvalidBookingFilter
+		}
+		repoFilter.Statuses = append(repoFilter.Statuses, st)
+	}
+
+	switch filter.When {
+	case "":
+	case BookingsUpcoming, BookingsPast:
+		upcoming := filter.When == BookingsUpcoming
+		repoFilter.Upcoming = &upcoming
+	default:
+		return nil, 0, ErrInvalidBookingFilter
+	}
+
+	if filter.To != nil {
+		end := filter.To.AddDate(0, 0, 1)
+		repoFilter.To = &end
+	}
+	if filter.From != nil && repoFilter.To != nil && !filter.From.Before(*repoFilter.To) {
+		return nil, 0, ErrInvalidBookingFilter
+	}
+
+	if filter.Ascending != nil {
+		repoFilter.Ascending = *filter.Ascending
+	} else {
+		repoFilter.Ascending = filter.When == BookingsUpcoming
+	}
+
+	return s.bookingRepo.FindByUserID(ctx, userID, repoFilter)
+}
+
+// CancelBooking cancels a booking on behalf of its owner. A pending booking is
+// released right away; a paid one is refunded through its payment provider as
+// long as the showtime is at least the refund cutoff away. Either way the seats
+// become available again.
+func (s *BookingService) CancelBooking(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.Transaction, error) {
+	booking, err := s.GetBookingByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch booking.Status {
+	case entities.TransactionStatusPending:
+		ok, err := s.bookingRepo.Cancel(ctx, id, entities.StatusChange{
+			Actor:  entities.ActorUser(userID),
+			Reason: "cancelled by customer",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel booking: %w", err)
+		}
+		if !ok {
+			return nil, ErrBookingNotCancellable
+		}
+	case entities.TransactionStatusPaid:
+		// Seats given to others or already admitted are no longer the
+		// owner's to refund.
+		if seatsHandedOver(booking) {
+			return nil, ErrBookingNotCancellable
+		}
+		if err := s.refund(ctx, booking); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrBookingNotCancellable
+	}
+	publishSeats(ctx, s.seatEvents, booking.ShowtimeID, realtime.SeatReleased, itemSeatIDs(booking.Items), nil)
+
+	return s.bookingRepo.FindByID(ctx, id)
+}
+
+func (s *BookingService) refund(ctx context.Context, booking *entities.Transaction) error {
+	if time.Until(booking.Showtime.Time) < s.refunds.Cutoff {
+		return ErrRefundWindowClosed
+	}
+	if booking.ExternalRef == nil {
+		return ErrBookingNotCancellable
+	}
+
+	provider, err := s.payments.Get(booking.PaymentMethod.Code)
+	if err != nil {
+		return ErrPaymentMethodNotFound
+	}
+
+	// A change still waiting for its extra cost is given up first, so its
+	// charge can't be paid once the booking is refunded. One already paid is
+	// left to settle before the booking can be cancelled.
+	if amendment := outstandingAmendment(booking); amendment != nil && amendment.Settlement == entities.AmendmentSettlementCharge {
+		if _, err := s.dropAmendment(ctx, provider, booking, amendment); err != nil {
+			if errors.Is(err, payment.ErrChargeNotCancellable) {
+				return ErrBookingNotCancellable
+			}
+			return err
+		}
+	}
+
+	amount := booking.Amount - refundFee(booking.Amount, s.refunds.FeePercent)
+
+	// The booking leaves paid before the provider is asked for the money, so a
+	// concurrent cancel cannot trigger a second refund.
+	ok, err := s.bookingRepo.RequestRefund(ctx, booking.ID, amount, entities.StatusChange{
+		Actor:  entities.ActorUser(booking.UserID),
+		Reason: "cancelled by customer",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to request refund: %w", err)
+	}
+	if !ok {
+		return ErrBookingNotCancellable
+	}
+
+	if amount == 0 {
+		_, err := s.bookingRepo.CompleteRefund(ctx, booking.ID, "", time.Now(), entities.StatusChange{
+			Actor:  entities.ActorSystem,
+			Reason: "nothing left to refund after fee",
+		})
+		return err
+	}
+
+	refund, err := provider.Refund(ctx, payment.RefundRequest{
+		ChargeReference: *booking.ExternalRef,
+		Amount:          amount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create payment refund: %w", err)
+	}
+
+	// Providers that settle later confirm through the refund webhook.
+	if refund.Status != payment.RefundStatusSucceeded {
+		return s.bookingRepo.AttachRefundRef(ctx, booking.ID, refund.Reference)
+	}
+
+	_, err = s.bookingRepo.CompleteRefund(ctx, booking.ID, refund.Reference, time.Now(), entities.StatusChange{
+		Actor:  entities.ActorProvider(booking.PaymentMethod.Code),
+		Reason: "refund settled by provider",
+	})
+	return err
+}
+
+// AmendBooking changes the seats of a pending or paid booking, optionally
+// moving it to another showtime of the same movie. The seats are repriced, the
+// booking's promo code is applied to them again and fees and taxes are
+// recalculated. A pending booking simply gets a new charge for the new amount;
+// for a paid one the difference is charged or refunded through its payment
+// provider.
+func (s *BookingService) AmendBooking(ctx context.Context, input AmendBookingInput) (*entities.Transaction, error) {
+	booking, err := s.GetBookingByID(ctx, input.BookingID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status != entities.TransactionStatusPending && booking.Status != entities.TransactionStatusPaid {
+		return nil, ErrBookingNotAmendable
+	}
+	// One change at a time: the difference of the last one must be settled.
+	if outstandingAmendment(booking) != nil || seatsHandedOver(booking) {
+		return nil, ErrBookingNotAmendable
+	}
+
+	targetID := booking.ShowtimeID
+	if input.ShowtimeID != nil {
+		targetID = *input.ShowtimeID
+	}
+
+	showtime, err := s.showtimeRepo.FindByID(ctx, targetID)
+	if err != nil {
+		return nil, ErrShowtimeNotFound
+	}
+	if showtime.MovieID != booking.Showtime.MovieID || showtime.TheaterID != booking.TheaterID {
+		return nil, ErrInvalidAmendment
+	}
+	if targetID == booking.ShowtimeID && sameSeats(booking.Items, input.SeatIDs) {
+		return nil, ErrInvalidAmendment
+	}
+
+	if booking.Status == entities.TransactionStatusPaid {
+		if time.Until(booking.Showtime.Time) < s.refunds.Cutoff || time.Until(showtime.Time) < s.refunds.Cutoff {
+			return nil, ErrAmendmentWindowClosed
+		}
+	} else if !showtime.Time.After(time.Now()) {
+		return nil, ErrInvalidAmendment
+	}
+
+	provider, err := s.payments.Get(booking.PaymentMethod.Code)
+	if err != nil {
+		return nil, ErrPaymentMethodNotFound
+	}
+
+	if err := s.validateSeats(ctx, showtime, input.SeatIDs); err != nil {
+		return nil, err
+	}
+	if err := s.checkSeatsPerBooking(len(input.SeatIDs)); err != nil {
+		return nil, err
+	}
+
+	items, subtotal, err := s.priceSeats(ctx, showtime, booking.ID, input.SeatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Concessions don't depend on the seats and are kept as ordered.
+	var addonAmount int64
+	for _, a := range booking.Addons {
+		addonAmount += a.UnitPrice * int64(a.Quantity)
+	}
+
+	// The promotion is checked against the new seats and showtime like on a new
+	// booking. It was redeemed when the booking was made, so its usage limits
+	// don't apply again.
+	var discount int64
+	if booking.Promotion != nil {
+		_, discount, err = s.promotionService.Apply(ctx, ApplyPromotionInput{
+			Code:            booking.Promotion.Code,
+			Showtime:        showtime,
+			PaymentMethodID: booking.PaymentMethodID,
+			Items:           items,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	charges, err := s.feeService.Calculate(ctx, FeeInput{
+		TransactionID:       booking.ID,
+		TheaterID:           booking.TheaterID,
+		PaymentMethodTypeID: booking.PaymentMethod.PaymentMethodTypeID,
+		Tickets:             len(items),
+		Base:                subtotal - discount + addonAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	newAmount := subtotal - discount + addonAmount + sumCharges(charges)
+
+	amendment := &entities.BookingAmendment{
+		ID:             uuid.New(),
+		TransactionID:  booking.ID,
+		FromShowtimeID: booking.ShowtimeID,
+		ToShowtimeID:   targetID,
+		PreviousAmount: booking.Amount,
+		NewAmount:      newAmount,
+		Difference:     newAmount - booking.Amount,
+		Settlement:     entities.AmendmentSettlementNone,
+		Actor:          entities.ActorUser(input.UserID),
+	}
+
+	switch {
+	case booking.Status == entities.TransactionStatusPending || amendment.Difference == 0:
+		now := time.Now()
+		amendment.SettledAt = &now
+	case amendment.Difference > 0:
+		// The booking keeps its seats until the difference is paid; the new
+		// ones are held for it meanwhile.
+		expiresAt := time.Now().Add(15 * time.Minute) // 15 minutes to pay the difference
+		amendment.Settlement = entities.AmendmentSettlementCharge
+		amendment.ExpiresAt = &expiresAt
+	default:
+		amendment.Settlement = entities.AmendmentSettlementCredit
+	}
+
+	// The old charge no longer matches the amount, so a pending booking is paid
+	// through a new one within the same payment window. The old one is voided
+	// first; if the customer has already paid it, the booking stays as it is.
+	recharge := booking.Status == entities.TransactionStatusPending && amendment.Difference != 0
+	if recharge && booking.ExternalRef != nil {
+		if err := provider.CancelCharge(ctx, *booking.ExternalRef); err != nil {
+			if errors.Is(err, payment.ErrChargeNotCancellable) {
+				return nil, ErrBookingNotAmendable
+			}
+			return nil, fmt.Errorf("failed to cancel charge: %w", err)
+		}
+	}
+
+	err = s.bookingRepo.Amend(ctx, amendment, items, charges, booking.Status, discount, s.purchaseCheck(len(input.SeatIDs), true))
+	if err != nil {
+		// The booking is unchanged, so it needs a charge for its old amount.
+		if recharge {
+			if chargeErr := s.openCharge(ctx, provider, booking); chargeErr != nil {
+				return nil, fmt.Errorf("failed to amend booking: %w", errors.Join(err, chargeErr))
+			}
+		}
+		if errors.Is(err, ErrPurchaseLimitExceeded) {
+			return nil, err
+		}
+		if errors.Is(err, repositories.ErrSeatsTaken) {
+			return nil, ErrSeatsNotAvailable
+		}
+		if errors.Is(err, repositories.ErrTransactionChanged) {
+			return nil, ErrBookingNotAmendable
+		}
+		return nil, fmt.Errorf("failed to amend booking: %w", err)
+	}
+
+	switch {
+	case amendment.Settlement == entities.AmendmentSettlementCharge:
+		publishSeats(ctx, s.seatEvents, targetID, realtime.SeatHeld, seatsGained(booking, targetID, input.SeatIDs), amendment.ExpiresAt)
+	case booking.Status == entities.TransactionStatusPending:
+		publishSeats(ctx, s.seatEvents, booking.ShowtimeID, realtime.SeatReleased, itemSeatIDs(booking.Items), nil)
+		publishSeats(ctx, s.seatEvents, targetID, realtime.SeatHeld, input.SeatIDs, &booking.ExpiredAt)
+	default:
+		publishSeats(ctx, s.seatEvents, booking.ShowtimeID, realtime.SeatReleased, itemSeatIDs(booking.Items), nil)
+		publishSeats(ctx, s.seatEvents, targetID, realtime.SeatBooked, input.SeatIDs, nil)
+	}
+
+	switch amendment.Settlement {
+	case entities.AmendmentSettlementCharge:
+		err = s.chargeAmendment(ctx, provider, amendment)
+	case entities.AmendmentSettlementCredit:
+		err = s.creditAmendment(ctx, provider, booking, amendment)
+	default:
+		if recharge {
+			booking.Amount = newAmount
+			err = s.openCharge(ctx, provider, booking)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to settle booking change: %w", err)
+	}
+
+	return s.bookingRepo.FindByID(ctx, booking.ID)
+}
+
+// chargeAmendment opens a charge for the extra cost of an amendment, payable
+// for as long as its seats are held.
+func (s *BookingService) chargeAmendment(ctx context.Context, provider payment.Provider, amendment *entities.BookingAmendment) error {
+	charge, err := provider.CreateCharge(ctx, payment.ChargeRequest{
+		TransactionID: amendment.TransactionID,
+		Amount:        amendment.Difference,
+		ExpiresAt:     *amendment.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.bookingRepo.AttachAmendmentRef(ctx, amendment.ID, charge.Reference, &entities.PaymentInstructions{
+		Kind:      charge.Instructions.Kind,
+		VANumber:  charge.Instructions.VANumber,
+		Deeplink:  charge.Instructions.Deeplink,
+		ExpiresAt: charge.Instructions.ExpiresAt,
+	})
+}
+
+// creditAmendment refunds what an amendment made cheaper against the
+// booking's original charge. No refund fee applies.
+func (s *BookingService) creditAmendment(ctx context.Context, provider payment.Provider, booking *entities.Transaction, amendment *entities.BookingAmendment) error {
+	if booking.ExternalRef == nil {
+		return errors.New("booking has no charge to refund")
+	}
+
+	refund, err := provider.Refund(ctx, payment.RefundRequest{
+		ChargeReference: *booking.ExternalRef,
+		Amount:          -amendment.Difference,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Providers that settle later confirm through the refund webhook.
+	if refund.Status != payment.RefundStatusSucceeded {
+		return s.bookingRepo.AttachAmendmentRef(ctx, amendment.ID, refund.Reference, nil)
+	}
+
+	_, err = s.bookingRepo.SettleAmendment(ctx, amendment.ID, refund.Reference, time.Now())
+	return err
+}
+
+// CreatePriorityHold holds the best free seats of the entry's showtime for a
+// waitlisted user until expiresAt. Nobody else can book them meanwhile; the
+// user books them like any other seats.
+func (s *BookingService) CreatePriorityHold(ctx context.Context, entry *entities.WaitlistEntry, expiresAt time.Time) error {
+	showtime, err := s.showtimeRepo.FindByID(ctx, entry.ShowtimeID)
+	if err != nil {
+		return fmt.Errorf("failed to get showtime: %w", err)
+	}
+
+	seats, err := s.seatRepo.FindByStudioID(ctx, showtime.StudioID)
+	if err != nil {
+		return fmt.Errorf("failed to get seats: %w", err)
+	}
+
+	bookedIDs, err := s.seatRepo.FindBookedSeatIDs(ctx, entry.ShowtimeID)
+	if err != nil {
+		return fmt.Errorf("failed to get booked seats: %w", err)
+	}
+
+	booked := make(map[uuid.UUID]bool, len(bookedIDs))
+	for _, id := range bookedIDs {
+		booked[id] = true
+	}
+
+	blocks := suggestSeats(seats, showtime.Studio.ScreenPosition, func(seat entities.Seat) bool { return !booked[seat.ID] }, entry.SeatCount)
+	if blocks == nil {
+		return ErrNotEnoughSeats
+	}
+
+	var seatIDs []uuid.UUID
+	for _, block := range blocks {
+		for _, seat := range block {
+			seatIDs = append(seatIDs, seat.ID)
+		}
+	}
+
+	ok, err := s.waitlistRepo.Offer(ctx, entry.ID, seatIDs, expiresAt)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSeatsTaken) {
+			return ErrSeatsNotAvailable
+		}
+		return fmt.Errorf("failed to hold seats: %w", err)
+	}
+	if !ok {
+		return ErrWaitlistEntryNotOpen
+	}
+	publishSeats(ctx, s.seatEvents, entry.ShowtimeID, realtime.SeatHeld, seatIDs, &expiresAt)
+
+	return nil
+}
+
+// outstandingAmendment returns the amendment of a booking whose difference is
+// still to be charged or refunded, if any.
+func outstandingAmendment(booking *entities.Transaction) *entities.BookingAmendment {
+	for i := range booking.Amendments {
+		if booking.Amendments[i].SettledAt == nil && booking.Amendments[i].ExpiredAt == nil {
+			return &booking.Amendments[i]
+		}
+	}
+	return nil
+}
+
+// seatsGained returns the seats of a showtime that an amendment adds to the
+// booking, leaving out those it already has.
+func seatsGained(booking *entities.Transaction, showtimeID uuid.UUID, seatIDs []uuid.UUID) []uuid.UUID {
+	if showtimeID != booking.ShowtimeID {
+		return seatIDs
+	}
+
+	current := make(map[uuid.UUID]bool, len(booking.Items))
+	for _, item := range booking.Items {
+		current[item.SeatID] = true
+	}
+
+	var gained []uuid.UUID
+	for _, id := range seatIDs {
+		if !current[id] {
+			gained = append(gained, id)
+		}
+	}
+	return gained
+}
+
+// seatsHandedOver reports whether any seat of the booking was transferred to
+// someone else or has already been admitted.
+func seatsHandedOver(booking *entities.Transaction) bool {
+	for _, item := range booking.Items {
+		if item.HolderID != nil || item.CheckedInAt != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// sameSeats reports whether seatIDs are exactly the seats of items.
+func sameSeats(items []entities.TransactionItem, seatIDs []uuid.UUID) bool {
+	if len(items) != len(seatIDs) {
+		return false
+	}
+
+	current := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		current[item.SeatID] = true
+	}
+	for _, id := range seatIDs {
+		if !current[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// refundFee returns the part of amount withheld on refund, rounded half up.
+func refundFee(amount int64, percent int) int64 {
+	if percent <= 0 {
+		return 0
+	}
+	if percent >= 100 {
+		return amount
+	}
+	return (amount*int64(percent) + 50) / 100
+}
+
+// ExpireStaleBookings releases pending bookings whose payment window has lapsed,
+// working through them in batches of batchSize. It returns how many bookings
+// were expired.
+func (s *BookingService) ExpireStaleBookings(ctx context.Context, batchSize int) (int, error) {
+	if batchSize < 1 {
+		batchSize = 100
+	}
+
+	total := 0
+	for {
+		expired, err := s.bookingRepo.ExpirePending(ctx, batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to expire bookings: %w", err)
+		}
+
+		for _, tx := range expired {
+			publishSeats(ctx, s.seatEvents, tx.ShowtimeID, realtime.SeatReleased, itemSeatIDs(tx.Items), nil)
+		}
+
+		total += len(expired)
+		if len(expired) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// ExpireStaleAmendments gives up on booking changes whose extra cost was not
+// paid in time, handling up to batchSize of them. Their charge is cancelled and
+// the seats held for them are released; the bookings keep their old seats. It
+// returns how many changes were expired.
+func (s *BookingService) ExpireStaleAmendments(ctx context.Context, batchSize int) (int, error) {
+	if batchSize < 1 {
+		batchSize = 100
+	}
+
+	lapsed, err := s.bookingRepo.FindLapsedAmendments(ctx, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find lapsed booking changes: %w", err)
+	}
+
+	total := 0
+	for _, amendment := range lapsed {
+		booking, err := s.bookingRepo.FindByID(ctx, amendment.TransactionID)
+		if err != nil {
+			return total, fmt.Errorf("failed to get booking: %w", err)
+		}
+
+		provider, err := s.payments.Get(booking.PaymentMethod.Code)
+		if err != nil {
+			return total, ErrPaymentMethodNotFound
+		}
+
+		ok, err := s.dropAmendment(ctx, provider, booking, &amendment)
+		// A charge paid at the last moment is left to its webhook, which
+		// settles the change or refunds it if the seats went meanwhile.
+		if errors.Is(err, payment.ErrChargeNotCancellable) {
+			if err := s.bookingRepo.MarkAmendmentSettlementPending(ctx, amendment.ID); err != nil {
+				return total, fmt.Errorf("failed to mark booking change pending: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return total, err
+		}
+		if ok {
+			total++
+		}
+	}
+
+	return total, nil
+}
+
+// dropAmendment gives up on a charged amendment that is still unpaid: its
+// charge is cancelled and the seats held for it are released. The error wraps
+// payment.ErrChargeNotCancellable when the charge has already been paid. It
+// reports false when the amendment was settled or expired in the meantime.
+func (s *BookingService) dropAmendment(ctx context.Context, provider payment.Provider, booking *entities.Transaction, amendment *entities.BookingAmendment) (bool, error) {
+	if amendment.SettlementRef != nil {
+		if err := provider.CancelCharge(ctx, *amendment.SettlementRef); err != nil {
+			return false, fmt.Errorf("failed to cancel charge: %w", err)
+		}
+	}
+
+	ok, err := s.bookingRepo.ExpireAmendment(ctx, amendment.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to expire booking change: %w", err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	released := seatsGained(booking, amendment.ToShowtimeID, itemSeatIDs(amendment.Items))
+	publishSeats(ctx, s.seatEvents, amendment.ToShowtimeID, realtime.SeatReleased, released, nil)
+	return true, nil
 }
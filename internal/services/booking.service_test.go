@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// TestCreateBookingConcurrentSameSeat races many bookings for one seat: the
+// seat claim inside the insert transaction must let exactly one through.
+func TestCreateBookingConcurrentSameSeat(t *testing.T) {
+	const attempts = 20
+
+	f := newBookingFixture(t, 1)
+	seatID := f.seatIDs[0]
+
+	users := make([]uuid.UUID, attempts)
+	for i := range users {
+		users[i] = f.newUser(t)
+	}
+
+	errs := make([]error, attempts)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, userID := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, errs[i] = f.bookings.CreateBooking(context.Background(), CreateBookingInput{
+				UserID:          userID,
+				ShowtimeID:      f.showtimeID,
+				SeatIDs:         []uuid.UUID{seatID},
+				PaymentMethodID: f.paymentMethodID,
+			})
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrSeatsNotAvailable):
+			t.Errorf("booking %d: got error %v, want %v", i, err, ErrSeatsNotAvailable)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d bookings got the seat, want exactly 1", succeeded)
+	}
+}
+
+// TestCreateBookingManyInARow books seat after seat: bookings are stored before
+// the provider assigns their reference, so they must not collide on it.
+func TestCreateBookingManyInARow(t *testing.T) {
+	const bookings = 10
+
+	f := newBookingFixture(t, bookings)
+	userID := f.newUser(t)
+
+	refs := make(map[string]bool, bookings)
+	for i, seatID := range f.seatIDs {
+		booking, err := f.bookings.CreateBooking(context.Background(), CreateBookingInput{
+			UserID:          userID,
+			ShowtimeID:      f.showtimeID,
+			SeatIDs:         []uuid.UUID{seatID},
+			PaymentMethodID: f.paymentMethodID,
+		})
+		if err != nil {
+			t.Fatalf("booking %d: %v", i, err)
+		}
+		if booking.ExternalRef == nil || *booking.ExternalRef == "" {
+			t.Fatalf("booking %d has no payment reference", i)
+		}
+		if refs[*booking.ExternalRef] {
+			t.Fatalf("booking %d reuses payment reference %q", i, *booking.ExternalRef)
+		}
+		refs[*booking.ExternalRef] = true
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+var (
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+)
+
+const (
+	calendarProductID = "-//Filmix//Bookings//EN"
+	// calendarFeedHistory is how far back the feed lists showtimes.
+	calendarFeedHistory = 365 * 24 * time.Hour
+	// defaultShowDuration is assumed for movies without a known runtime.
+	defaultShowDuration = 2 * time.Hour
+)
+
+type ICalendarService interface {
+	RenderBookingCalendar(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID) ([]byte, error)
+	CreateFeed(ctx context.Context, userID uuid.UUID) (string, error)
+	RevokeFeed(ctx context.Context, userID uuid.UUID) error
+	RenderFeed(ctx context.Context, token string) ([]byte, error)
+}
+
+type CalendarService struct {
+	calendarRepo repositories.ICalendarRepository
+	bookingRepo  repositories.IBookingRepository
+}
+
+func NewCalendarService(calendarRepo repositories.ICalendarRepository, bookingRepo repositories.IBookingRepository) ICalendarService {
+	return &CalendarService{
+		calendarRepo: calendarRepo,
+		bookingRepo:  bookingRepo,
+	}
+}
+
+// RenderBookingCalendar returns a booking as an iCalendar file with a single
+// event, for its owner or anyone holding one of its seats. The event lists the
+// seats the user holds.
+func (s *CalendarService) RenderBookingCalendar(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID) ([]byte, error) {
+	booking, err := s.bookingRepo.FindByID(ctx, bookingID)
+	if err != nil {
+		return nil, ErrBookingNotFound
+	}
+	if booking.UserID != userID && len(heldItems(booking, userID)) == 0 {
+		return nil, ErrBookingNotFound
+	}
+	booking.Items = heldItems(booking, userID)
+
+	return renderCalendar("", []entities.Transaction{*booking}), nil
+}
+
+// CreateFeed issues a new feed token for the user, revoking the previous one.
+// The token is only returned here; the feed keeps its hash.
+func (s *CalendarService) CreateFeed(ctx context.Context, userID uuid.UUID) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate feed token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	feed := &entities.CalendarFeed{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: hashFeedToken(token),
+	}
+	if err := s.calendarRepo.Rotate(ctx, feed); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *CalendarService) RevokeFeed(ctx context.Context, userID uuid.UUID) error {
+	revoked, err := s.calendarRepo.Revoke(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrCalendarFeedNotFound
+	}
+	return nil
+}
+
+// RenderFeed returns the iCalendar feed unlocked by a token: every booking its
+// user holds a seat of, own or transferred to them, that was paid for a
+// showtime in the last year.
+func (s *CalendarService) RenderFeed(ctx context.Context, token string) ([]byte, error) {
+	feed, err := s.calendarRepo.FindByTokenHash(ctx, hashFeedToken(token))
+	if err != nil {
+		return nil, ErrCalendarFeedNotFound
+	}
+
+	bookings, err := s.calendarRepo.FindFeedBookings(ctx, feed.UserID, time.Now().Add(-calendarFeedHistory))
+	if err != nil {
+		return nil, err
+	}
+
+	return renderCalendar("Filmix bookings", bookings), nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// renderCalendar writes bookings as RFC 5545 events. Each booking keeps the
+// same UID for its lifetime, and its SEQUENCE grows with every settled
+// amendment and with cancellation, so calendar apps update the event they
+// already have.
+func renderCalendar(name string, bookings []entities.Transaction) []byte {
+	var b strings.Builder
+	stamp := formatCalendarTime(time.Now())
+
+	writeCalendarLine(&b, "BEGIN", "VCALENDAR")
+	writeCalendarLine(&b, "VERSION", "2.0")
+	writeCalendarLine(&b, "PRODID", calendarProductID)
+	writeCalendarLine(&b, "CALSCALE", "GREGORIAN")
+	writeCalendarLine(&b, "METHOD", "PUBLISH")
+	if name != "" {
+		writeCalendarLine(&b, "X-WR-CALNAME", escapeCalendarText(name))
+	}
+
+	for _, booking := range bookings {
+		status, sequence := calendarStatus(booking)
+
+		start := booking.Showtime.Time
+		duration := time.Duration(booking.Showtime.Movie.Duration) * time.Minute
+		if duration <= 0 {
+			duration = defaultShowDuration
+		}
+
+		seats := make([]string, 0, len(booking.Items))
+		for _, item := range booking.Items {
+			seats = append(seats, seatLabel(item.Seat))
+		}
+		description := fmt.Sprintf("Booking %s", booking.ID)
+		if len(seats) > 0 {
+			description = fmt.Sprintf("Seats: %s\nBooking %s", strings.Join(seats, ", "), booking.ID)
+		}
+
+		writeCalendarLine(&b, "BEGIN", "VEVENT")
+		writeCalendarLine(&b, "UID", booking.ID.String()+"@filmix")
+		writeCalendarLine(&b, "DTSTAMP", stamp)
+		writeCalendarLine(&b, "SEQUENCE", fmt.Sprint(sequence))
+		writeCalendarLine(&b, "DTSTART", formatCalendarTime(start))
+		writeCalendarLine(&b, "DTEND", formatCalendarTime(start.Add(duration)))
+		writeCalendarLine(&b, "SUMMARY", escapeCalendarText(booking.Showtime.Movie.Title))
+		if theater := booking.Theater; theater != nil {
+			writeCalendarLine(&b, "LOCATION", escapeCalendarText(theater.Name+", "+theater.Address))
+			writeCalendarLine(&b, "GEO", fmt.Sprintf("%.6f;%.6f", theater.Latitude, theater.Longitude))
+		}
+		writeCalendarLine(&b, "DESCRIPTION", escapeCalendarText(description))
+		writeCalendarLine(&b, "STATUS", status)
+		writeCalendarLine(&b, "END", "VEVENT")
+	}
+
+	writeCalendarLine(&b, "END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+// calendarStatus maps a booking to its event status and revision number.
+// Only settled amendments count: one still waiting for payment hasn't moved
+// the booking yet, and one that lapses never will.
+func calendarStatus(booking entities.Transaction) (string, int) {
+	var sequence int
+	for _, amendment := range booking.Amendments {
+		if amendment.SettledAt != nil {
+			sequence++
+		}
+	}
+	switch booking.Status {
+	case entities.TransactionStatusPaid, entities.TransactionStatusUsed:
+		return "CONFIRMED", sequence
+	case entities.TransactionStatusPending:
+		return "TENTATIVE", sequence
+	default:
+		return "CANCELLED", sequence + 1
+	}
+}
+
+func formatCalendarTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeCalendarText escapes a TEXT value as RFC 5545 section 3.3.11 requires.
+func escapeCalendarText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeCalendarLine writes a content line, folding it into lines of at most
+// 75 octets without splitting UTF-8 characters.
+func writeCalendarLine(b *strings.Builder, name, value string) {
+	line := name + ":" + value
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit.
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+type IConcessionService interface {
+	GetCatalog(ctx context.Context, theaterID uuid.UUID) ([]entities.ConcessionItem, error)
+}
+
+type ConcessionService struct {
+	concessionRepo repositories.IConcessionRepository
+}
+
+func NewConcessionService(concessionRepo repositories.IConcessionRepository) IConcessionService {
+	return &ConcessionService{concessionRepo: concessionRepo}
+}
+
+// GetCatalog returns what a theater sells alongside tickets, with how many of
+// each variant can still be ordered.
+func (s *ConcessionService) GetCatalog(ctx context.Context, theaterID uuid.UUID) ([]entities.ConcessionItem, error) {
+	return s.concessionRepo.FindCatalog(ctx, theaterID)
+}
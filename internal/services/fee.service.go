@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+// FeeInput describes the order fees and taxes are charged on.
+type FeeInput struct {
+	TransactionID       uuid.UUID
+	TheaterID           uuid.UUID
+	PaymentMethodTypeID uuid.UUID
+	Tickets             int
+	// Base is the ticket subtotal after discounts plus any concession add-ons,
+	// in minor units. Fees are charged on it and taxes such as VAT on it plus
+	// the fees.
+	Base int64
+}
+
+type IFeeService interface {
+	Calculate(ctx context.Context, input FeeInput) ([]entities.TransactionCharge, error)
+}
+
+type FeeService struct {
+	feeRepo repositories.IFeeRepository
+}
+
+func NewFeeService(feeRepo repositories.IFeeRepository) IFeeService {
+	return &FeeService{feeRepo: feeRepo}
+}
+
+// Calculate returns one charge per fee and tax rule of the theater that
+// applies to the order. Rules tied to a payment method type only apply when
+// paying with a method of that type.
+//
+// Fees are charged on the base, taxes on the base plus all fees; several taxes
+// don't compound. All amounts are integer minor units: percent rules are in
+// basis points and every line is rounded half up on its own, so the total is
+// exactly the sum of the lines shown.
+func (s *FeeService) Calculate(ctx context.Context, input FeeInput) ([]entities.TransactionCharge, error) {
+	rules, err := s.feeRepo.FindActiveRules(ctx, input.TheaterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee rules: %w", err)
+	}
+
+	var fees, taxes []entities.FeeRule
+	for _, rule := range rules {
+		if rule.PaymentMethodTypeID != nil && *rule.PaymentMethodTypeID != input.PaymentMethodTypeID {
+			continue
+		}
+		if rule.Kind == entities.ChargeKindTax {
+			taxes = append(taxes, rule)
+		} else {
+			fees = append(fees, rule)
+		}
+	}
+
+	var charges []entities.TransactionCharge
+	taxable := input.Base
+	for _, rule := range fees {
+		amount := feeAmount(rule, input.Tickets, input.Base)
+		if amount == 0 {
+			continue
+		}
+		charges = append(charges, newCharge(input.TransactionID, rule, amount))
+		taxable += amount
+	}
+	for _, rule := range taxes {
+		amount := feeAmount(rule, input.Tickets, taxable)
+		if amount == 0 {
+			continue
+		}
+		charges = append(charges, newCharge(input.TransactionID, rule, amount))
+	}
+
+	return charges, nil
+}
+
+// feeAmount applies a rule to an order of tickets worth base.
+func feeAmount(rule entities.FeeRule, tickets int, base int64) int64 {
+	switch rule.Basis {
+	case entities.FeeBasisPerTicket:
+		return rule.Value * int64(tickets)
+	case entities.FeeBasisPerOrder:
+		return rule.Value
+	case entities.FeeBasisPercent:
+		return percentOf(base, rule.Value)
+	}
+	return 0
+}
+
+// percentOf returns bps basis points of amount, rounded half up.
+func percentOf(amount int64, bps int64) int64 {
+	if amount <= 0 || bps <= 0 {
+		return 0
+	}
+	return (amount*bps + 5000) / 10000
+}
+
+func newCharge(txID uuid.UUID, rule entities.FeeRule, amount int64) entities.TransactionCharge {
+	return entities.TransactionCharge{
+		ID:            uuid.New(),
+		TransactionID: txID,
+		Kind:          rule.Kind,
+		Name:          rule.Name,
+		Amount:        amount,
+		FeeRuleID:     &rule.ID,
+	}
+}
+
+// sumCharges returns the total of the given charges.
+func sumCharges(charges []entities.TransactionCharge) int64 {
+	var total int64
+	for _, c := range charges {
+		total += c.Amount
+	}
+	return total
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+var (
+	ErrIdempotencyKeyReused   = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyKeyInFlight = errors.New("a request with this idempotency key is still being processed")
+)
+
+// idempotencyStaleAfter is how long an unfinished request may hold its key
+// before a retry is allowed to take it over.
+const idempotencyStaleAfter = time.Minute
+
+type IdempotencyRequest struct {
+	UserID uuid.UUID
+	Key    string
+	// Fingerprint identifies the request payload, so a key cannot be reused
+	// for a different request.
+	Fingerprint string
+}
+
+type IIdempotencyService interface {
+	Begin(ctx context.Context, req IdempotencyRequest) (*entities.IdempotencyKey, error)
+	Complete(ctx context.Context, req IdempotencyRequest, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, req IdempotencyRequest) error
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type IdempotencyService struct {
+	idempotencyRepo repositories.IIdempotencyRepository
+	ttl             time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo repositories.IIdempotencyRepository, ttl time.Duration) IIdempotencyService {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+	}
+}
+
+// Begin reserves the key for a new request and returns nil, or returns the
+// stored response of an earlier request with the same key and payload, which
+// the caller should replay instead of processing the request again.
+func (s *IdempotencyService) Begin(ctx context.Context, req IdempotencyRequest) (*entities.IdempotencyKey, error) {
+	reserved, err := s.idempotencyRepo.Reserve(ctx, &entities.IdempotencyKey{
+		UserID:      req.UserID,
+		Key:         req.Key,
+		Fingerprint: req.Fingerprint,
+		ExpiresAt:   time.Now().Add(s.ttl),
+	}, idempotencyStaleAfter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	existing, err := s.idempotencyRepo.FindByKey(ctx, req.UserID, req.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	if existing.Fingerprint != req.Fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == nil {
+		return nil, ErrIdempotencyKeyInFlight
+	}
+
+	return existing, nil
+}
+
+// Complete stores the response to replay for the key.
+func (s *IdempotencyService) Complete(ctx context.Context, req IdempotencyRequest, statusCode int, contentType string, body []byte) error {
+	return s.idempotencyRepo.Complete(ctx, req.UserID, req.Key, statusCode, contentType, body)
+}
+
+// Release frees the key without storing a response, so the request can be
+// retried, e.g. after a server error.
+func (s *IdempotencyService) Release(ctx context.Context, req IdempotencyRequest) error {
+	return s.idempotencyRepo.Delete(ctx, req.UserID, req.Key)
+}
+
+// PurgeExpired deletes keys past their replay window.
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.idempotencyRepo.DeleteExpired(ctx)
+}
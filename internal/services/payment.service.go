@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/integrations/payment"
+	"github.com/senatroxx/filmix-backend/internal/integrations/realtime"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+var (
+	ErrInvalidWebhookSignature      = errors.New("invalid webhook signature")
+	ErrInvalidWebhookPayload        = errors.New("invalid webhook payload")
+	ErrPaymentAmountMismatch        = errors.New("payment amount does not match booking")
+	ErrBookingNotPayable            = errors.New("booking can no longer be paid")
+	ErrPaymentSimulationUnavailable = errors.New("payment simulation is not available")
+	ErrNoRefundPending              = errors.New("booking has no refund in progress")
+)
+
+type IPaymentService interface {
+	HandleWebhook(ctx context.Context, providerCode string, body []byte, signature string) error
+	SimulatePayment(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID) error
+}
+
+type PaymentService struct {
+	bookingRepo       repositories.IBookingRepository
+	paymentMethodRepo repositories.IPaymentMethodRepository
+	payments          *payment.Registry
+	allowSimulation   bool
+	location          *time.Location
+	seatEvents        realtime.Publisher
+}
+
+func NewPaymentService(
+	bookingRepo repositories.IBookingRepository,
+	paymentMethodRepo repositories.IPaymentMethodRepository,
+	payments *payment.Registry,
+	allowSimulation bool,
+	location *time.Location,
+	seatEvents realtime.Publisher,
+) IPaymentService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &PaymentService{
+		bookingRepo:       bookingRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		payments:          payments,
+		allowSimulation:   allowSimulation,
+		location:          location,
+		seatEvents:        seatEvents,
+	}
+}
+
+// HandleWebhook verifies and applies a provider notification. Redelivery of an
+// event that was already applied is acknowledged without error.
+func (s *PaymentService) HandleWebhook(ctx context.Context, providerCode string, body []byte, signature string) error {
+	provider, err := s.payments.Get(providerCode)
+	if err != nil {
+		return ErrPaymentMethodNotFound
+	}
+
+	event, err := provider.ParseWebhook(body, signature)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return ErrInvalidWebhookSignature
+		}
+		return ErrInvalidWebhookPayload
+	}
+
+	switch event.Type {
+	case payment.EventPaymentSucceeded:
+		return s.settle(ctx, providerCode, event)
+	case payment.EventRefundSucceeded:
+		return s.completeRefund(ctx, providerCode, event)
+	default:
+		// Events we don't act on are acknowledged so the provider stops retrying.
+		return nil
+	}
+}
+
+func (s *PaymentService) settle(ctx context.Context, providerCode string, event *payment.Event) error {
+	// Charges for the extra cost of a booking change have their own reference.
+	if amendment, err := s.bookingRepo.FindAmendmentBySettlementRef(ctx, event.Reference); err == nil {
+		return s.settleAmendment(ctx, providerCode, amendment, event)
+	}
+
+	tx, err := s.findEventBooking(ctx, providerCode, event)
+	if err != nil {
+		return err
+	}
+
+	if tx.Status == entities.TransactionStatusPaid {
+		return nil
+	}
+	if tx.Amount != event.Amount {
+		return ErrPaymentAmountMismatch
+	}
+
+	paidAt := event.OccurredAt
+	if paidAt.IsZero() {
+		paidAt = time.Now()
+	}
+
+	// Invoices are numbered per calendar month in the theaters' time zone.
+	invoicePeriod := paidAt.In(s.location).Format("200601")
+
+	ok, err := s.bookingRepo.MarkPaid(ctx, tx.ID, paidAt, invoicePeriod, entities.StatusChange{
+		Actor:  entities.ActorProvider(providerCode),
+		Reason: "payment confirmed by provider",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark booking paid: %w", err)
+	}
+	if !ok {
+		return s.refundLatePayment(ctx, providerCode, tx.ID)
+	}
+
+	if paid, err := s.bookingRepo.FindByID(ctx, tx.ID); err == nil {
+		publishSeats(ctx, s.seatEvents, paid.ShowtimeID, realtime.SeatBooked, itemSeatIDs(paid.Items), nil)
+	}
+
+	return nil
+}
+
+// refundLatePayment gives back a payment that landed after its booking stopped
+// being payable: the hold lapsed or the customer cancelled, and the seats may
+// be someone else's by now. The whole amount is refunded and the event is
+// acknowledged, as is any redelivery of it.
+func (s *PaymentService) refundLatePayment(ctx context.Context, providerCode string, id uuid.UUID) error {
+	tx, err := s.bookingRepo.FindByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	from := tx.Status
+	switch tx.Status {
+	case entities.TransactionStatusPending:
+		// The hold lapsed but hasn't been expired yet.
+		if err := s.bookingRepo.Expire(ctx, tx.ID, entities.StatusChange{
+			Actor:  entities.ActorSystem,
+			Reason: "payment window lapsed",
+		}); err != nil {
+			return fmt.Errorf("failed to expire booking: %w", err)
+		}
+		publishSeats(ctx, s.seatEvents, tx.ShowtimeID, realtime.SeatReleased, itemSeatIDs(tx.Items), nil)
+		from = entities.TransactionStatusExpired
+	case entities.TransactionStatusExpired, entities.TransactionStatusCancelled:
+	default:
+		// Paid or refunded meanwhile by an earlier delivery of the event.
+		return nil
+	}
+
+	provider, err := s.payments.Get(providerCode)
+	if err != nil {
+		return ErrPaymentMethodNotFound
+	}
+
+	// The refund is recorded before the provider is asked for it, so a
+	// redelivered payment cannot trigger a second one.
+	ok, err := s.bookingRepo.RequestLateRefund(ctx, tx.ID, from, entities.StatusChange{
+		Actor:  entities.ActorProvider(providerCode),
+		Reason: "paid after the booking lapsed",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to request refund: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	refund, err := provider.Refund(ctx, payment.RefundRequest{
+		ChargeReference: *tx.ExternalRef,
+		Amount:          tx.Amount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refund late payment: %w", err)
+	}
+
+	// Providers that settle later confirm through the refund webhook.
+	if refund.Status != payment.RefundStatusSucceeded {
+		return s.bookingRepo.AttachRefundRef(ctx, tx.ID, refund.Reference)
+	}
+
+	_, err = s.bookingRepo.CompleteRefund(ctx, tx.ID, refund.Reference, time.Now(), entities.StatusChange{
+		Actor:  entities.ActorProvider(providerCode),
+		Reason: "refund settled by provider",
+	})
+	return err
+}
+
+func (s *PaymentService) completeRefund(ctx context.Context, providerCode string, event *payment.Event) error {
+	// A booking change's payment is refunded against the change's own charge.
+	if amendment, err := s.bookingRepo.FindAmendmentBySettlementRef(ctx, event.Reference); err == nil {
+		return s.completeAmendmentRefund(ctx, providerCode, amendment, event)
+	}
+
+	tx, err := s.findEventBooking(ctx, providerCode, event)
+	if err != nil {
+		return err
+	}
+
+	if event.RefundReference != "" {
+		amendment, err := s.bookingRepo.FindAmendmentBySettlementRef(ctx, event.RefundReference)
+		if err == nil && amendment.TransactionID == tx.ID {
+			return s.settleAmendment(ctx, providerCode, amendment, event)
+		}
+	}
+
+	if tx.Status == entities.TransactionStatusRefunded {
+		return nil
+	}
+
+	refundedAt := event.OccurredAt
+	if refundedAt.IsZero() {
+		refundedAt = time.Now()
+	}
+
+	ok, err := s.bookingRepo.CompleteRefund(ctx, tx.ID, event.RefundReference, refundedAt, entities.StatusChange{
+		Actor:  entities.ActorProvider(providerCode),
+		Reason: "refund confirmed by provider",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete refund: %w", err)
+	}
+	if !ok {
+		return ErrNoRefundPending
+	}
+
+	return nil
+}
+
+// settleAmendment applies the payment or refund of a booking change's
+// difference. Redelivered events are acknowledged. A payment that can't be
+// applied any more is refunded, and acknowledged as well.
+func (s *PaymentService) settleAmendment(ctx context.Context, providerCode string, amendment *entities.BookingAmendment, event *payment.Event) error {
+	if amendment.SettledAt != nil || amendment.RefundAmount != nil {
+		return nil
+	}
+
+	tx, err := s.bookingRepo.FindByID(ctx, amendment.TransactionID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking: %w", err)
+	}
+	if tx.PaymentMethod.Code != providerCode {
+		return ErrBookingNotFound
+	}
+
+	if amendment.Settlement == entities.AmendmentSettlementCharge && event.Amount != amendment.Difference {
+		return ErrPaymentAmountMismatch
+	}
+	if amendment.ExpiredAt != nil {
+		return s.refundAmendment(ctx, tx, amendment)
+	}
+
+	settledAt := event.OccurredAt
+	if settledAt.IsZero() {
+		settledAt = time.Now()
+	}
+
+	ok, err := s.bookingRepo.SettleAmendment(ctx, amendment.ID, "", settledAt)
+	if errors.Is(err, repositories.ErrTransactionChanged) || errors.Is(err, repositories.ErrSeatsTaken) {
+		return s.refundAmendment(ctx, tx, amendment)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to settle booking change: %w", err)
+	}
+	if !ok {
+		// Settled or expired since it was loaded; look again.
+		current, err := s.bookingRepo.FindAmendmentBySettlementRef(ctx, *amendment.SettlementRef)
+		if err != nil {
+			return fmt.Errorf("failed to get booking change: %w", err)
+		}
+		return s.settleAmendment(ctx, providerCode, current, event)
+	}
+
+	// A paid change moves the booking onto the seats held for it.
+	if ok && amendment.Settlement == entities.AmendmentSettlementCharge {
+		publishSeats(ctx, s.seatEvents, tx.ShowtimeID, realtime.SeatReleased, itemSeatIDs(tx.Items), nil)
+		publishSeats(ctx, s.seatEvents, amendment.ToShowtimeID, realtime.SeatBooked, itemSeatIDs(amendment.Items), nil)
+	}
+
+	return nil
+}
+
+// refundAmendment returns the payment of a charged booking change that can no
+// longer be applied: it came in after the change lapsed, or the booking or the
+// held seats moved on meanwhile. The change is given up, so the booking keeps
+// its seats.
+func (s *PaymentService) refundAmendment(ctx context.Context, tx *entities.Transaction, amendment *entities.BookingAmendment) error {
+	provider, err := s.payments.Get(tx.PaymentMethod.Code)
+	if err != nil {
+		return ErrPaymentMethodNotFound
+	}
+
+	// The refund is recorded before the provider is asked for it, so a
+	// redelivered payment cannot trigger a second one.
+	released, ok, err := s.bookingRepo.RequestAmendmentRefund(ctx, amendment.ID, amendment.Difference)
+	if err != nil {
+		return fmt.Errorf("failed to request booking change refund: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+	publishSeats(ctx, s.seatEvents, amendment.ToShowtimeID, realtime.SeatReleased, seatsGained(tx, amendment.ToShowtimeID, released), nil)
+
+	refund, err := provider.Refund(ctx, payment.RefundRequest{
+		ChargeReference: *amendment.SettlementRef,
+		Amount:          amendment.Difference,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refund booking change payment: %w", err)
+	}
+
+	// Providers that settle later confirm through the refund webhook.
+	if refund.Status != payment.RefundStatusSucceeded {
+		return s.bookingRepo.AttachAmendmentRefundRef(ctx, amendment.ID, refund.Reference)
+	}
+
+	_, err = s.bookingRepo.CompleteAmendmentRefund(ctx, amendment.ID, refund.Reference, time.Now())
+	return err
+}
+
+// completeAmendmentRefund records that the refund of a booking change's
+// payment went through. Redelivered events are acknowledged.
+func (s *PaymentService) completeAmendmentRefund(ctx context.Context, providerCode string, amendment *entities.BookingAmendment, event *payment.Event) error {
+	tx, err := s.bookingRepo.FindByID(ctx, amendment.TransactionID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking: %w", err)
+	}
+	if tx.PaymentMethod.Code != providerCode {
+		return ErrBookingNotFound
+	}
+
+	if amendment.RefundedAt != nil {
+		return nil
+	}
+	if amendment.RefundAmount == nil {
+		return ErrNoRefundPending
+	}
+
+	refundedAt := event.OccurredAt
+	if refundedAt.IsZero() {
+		refundedAt = time.Now()
+	}
+
+	if _, err := s.bookingRepo.CompleteAmendmentRefund(ctx, amendment.ID, event.RefundReference, refundedAt); err != nil {
+		return fmt.Errorf("failed to complete booking change refund: %w", err)
+	}
+
+	return nil
+}
+
+// findEventBooking loads the booking an event refers to, making sure it was
+// paid through the provider that sent the event.
+func (s *PaymentService) findEventBooking(ctx context.Context, providerCode string, event *payment.Event) (*entities.Transaction, error) {
+	tx, err := s.bookingRepo.FindByExternalRef(ctx, event.Reference)
+	if err != nil {
+		return nil, ErrBookingNotFound
+	}
+
+	method, err := s.paymentMethodRepo.FindByID(ctx, tx.PaymentMethodID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment method: %w", err)
+	}
+	if method.Code != providerCode {
+		return nil, ErrBookingNotFound
+	}
+
+	return tx, nil
+}
+
+// SimulatePayment pays a booking through its provider's simulator, producing
+// the same signed webhook a real payment would. Only available outside prod.
+func (s *PaymentService) SimulatePayment(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID) error {
+	if !s.allowSimulation {
+		return ErrPaymentSimulationUnavailable
+	}
+
+	tx, err := s.bookingRepo.FindByID(ctx, bookingID)
+	if err != nil || tx.UserID != userID {
+		return ErrBookingNotFound
+	}
+
+	provider, err := s.payments.Get(tx.PaymentMethod.Code)
+	if err != nil {
+		return ErrPaymentMethodNotFound
+	}
+
+	simulator, ok := provider.(payment.Simulator)
+	if !ok {
+		return ErrPaymentSimulationUnavailable
+	}
+
+	event := payment.Event{
+		Type:       payment.EventPaymentSucceeded,
+		Amount:     tx.Amount,
+		OccurredAt: time.Now(),
+	}
+
+	// A paid booking can still owe the extra cost of a change.
+	if amendment := outstandingAmendment(tx); tx.Status == entities.TransactionStatusPaid && amendment != nil {
+		if amendment.Settlement != entities.AmendmentSettlementCharge || amendment.SettlementRef == nil {
+			return ErrBookingNotPayable
+		}
+		event.Reference = *amendment.SettlementRef
+		event.Amount = amendment.Difference
+	} else if tx.ExternalRef != nil {
+		event.Reference = *tx.ExternalRef
+	} else {
+		return ErrBookingNotPayable
+	}
+
+	body, signature, err := simulator.SimulateEvent(event)
+	if err != nil {
+		return err
+	}
+
+	return s.HandleWebhook(ctx, tx.PaymentMethod.Code, body, signature)
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+type IPricingService interface {
+	ResolveSeatPrices(ctx context.Context, showtime *entities.Showtime, seats []entities.Seat) (map[uuid.UUID]int64, error)
+}
+
+type PricingService struct {
+	pricingRepo repositories.IPricingRepository
+	location    *time.Location
+}
+
+// NewPricingService creates a pricing service that derives day types in the
+// given location, i.e. the local time zone of the theaters.
+func NewPricingService(pricingRepo repositories.IPricingRepository, location *time.Location) IPricingService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &PricingService{
+		pricingRepo: pricingRepo,
+		location:    location,
+	}
+}
+
+// ResolveSeatPrices returns the price of every given seat for the showtime,
+// keyed by seat ID. Per seat type, the first match wins:
+//
+//  1. the override pinned to the showtime, if it targets that seat type
+//  2. an active override for the showtime's movie at its theater
+//  3. the theater's seat pricing for the seat type and day type
+//  4. the showtime's base pricing
+func (s *PricingService) ResolveSeatPrices(ctx context.Context, showtime *entities.Showtime, seats []entities.Seat) (map[uuid.UUID]int64, error) {
+	byType := make(map[uuid.UUID]int64)
+
+	pricings, err := s.pricingRepo.FindSeatPricings(ctx, showtime.TheaterID, s.DayType(showtime))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seat pricings: %w", err)
+	}
+	for _, sp := range pricings {
+		byType[sp.SeatTypeID] = sp.Price
+	}
+
+	overrides, err := s.pricingRepo.FindActiveOverrides(ctx, showtime.MovieID, showtime.TheaterID, showtime.Time)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pricing overrides: %w", err)
+	}
+	// Overrides come most recent first, so walk backwards to let it win.
+	for i := len(overrides) - 1; i >= 0; i-- {
+		byType[overrides[i].SeatTypeID] = overrides[i].Price
+	}
+
+	if showtime.SeatPricingOverrideID != nil {
+		pinned, err := s.pricingRepo.FindOverrideByID(ctx, *showtime.SeatPricingOverrideID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get showtime pricing override: %w", err)
+		}
+		if pinned.Active {
+			byType[pinned.SeatTypeID] = pinned.Price
+		}
+	}
+
+	var basePrice int64
+	if showtime.Pricing != nil {
+		basePrice = showtime.Pricing.Price
+	}
+
+	prices := make(map[uuid.UUID]int64, len(seats))
+	for _, seat := range seats {
+		price, ok := byType[seat.SeatTypeID]
+		if !ok {
+			price = basePrice
+		}
+		prices[seat.ID] = price
+	}
+
+	return prices, nil
+}
+
+// DayType classifies the showtime by its local calendar day.
+func (s *PricingService) DayType(showtime *entities.Showtime) string {
+	switch showtime.Time.In(s.location).Weekday() {
+	case time.Saturday, time.Sunday:
+		return entities.DayTypeWeekend
+	default:
+		return entities.DayTypeWeekday
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+var (
+	ErrPromoCodeNotFound      = errors.New("promo code not found")
+	ErrPromoCodeNotApplicable = errors.New("promo code does not apply to this booking")
+	ErrPromoMinSpendNotMet    = errors.New("booking does not reach the promo minimum spend")
+	ErrPromoUsageLimitReached = errors.New("promo code usage limit reached")
+)
+
+type ApplyPromotionInput struct {
+	Code            string
+	Showtime        *entities.Showtime
+	PaymentMethodID uuid.UUID
+	Items           []entities.TransactionItem
+}
+
+type IPromotionService interface {
+	Apply(ctx context.Context, input ApplyPromotionInput) (*entities.Promotion, int64, error)
+}
+
+type PromotionService struct {
+	promotionRepo repositories.IPromotionRepository
+}
+
+func NewPromotionService(promotionRepo repositories.IPromotionRepository) IPromotionService {
+	return &PromotionService{promotionRepo: promotionRepo}
+}
+
+// Apply checks a promo code against a booking and returns the promotion with
+// the discount it grants. Usage limits are not checked here; they are enforced
+// atomically when the booking is stored.
+func (s *PromotionService) Apply(ctx context.Context, input ApplyPromotionInput) (*entities.Promotion, int64, error) {
+	promo, err := s.promotionRepo.FindByCode(ctx, normalizePromoCode(input.Code))
+	if err != nil || !promo.Active {
+		return nil, 0, ErrPromoCodeNotFound
+	}
+
+	now := time.Now()
+	if promo.StartsAt != nil && now.Before(*promo.StartsAt) {
+		return nil, 0, ErrPromoCodeNotApplicable
+	}
+	if promo.EndsAt != nil && !now.Before(*promo.EndsAt) {
+		return nil, 0, ErrPromoCodeNotApplicable
+	}
+
+	if promo.MovieID != nil && *promo.MovieID != input.Showtime.MovieID {
+		return nil, 0, ErrPromoCodeNotApplicable
+	}
+	if promo.TheaterID != nil && *promo.TheaterID != input.Showtime.TheaterID {
+		return nil, 0, ErrPromoCodeNotApplicable
+	}
+	if promo.PaymentMethodID != nil && *promo.PaymentMethodID != input.PaymentMethodID {
+		return nil, 0, ErrPromoCodeNotApplicable
+	}
+
+	var subtotal, eligible int64
+	for _, item := range input.Items {
+		subtotal += item.Price
+		if promo.SeatTypeID == nil || *promo.SeatTypeID == item.SeatTypeID {
+			eligible += item.Price
+		}
+	}
+
+	if subtotal < promo.MinSpend {
+		return nil, 0, ErrPromoMinSpendNotMet
+	}
+	if eligible == 0 {
+		return nil, 0, ErrPromoCodeNotApplicable
+	}
+
+	return promo, promotionDiscount(promo, eligible), nil
+}
+
+// promotionDiscount returns the discount on base, never more than base itself.
+// Percentages are rounded half up.
+func promotionDiscount(promo *entities.Promotion, base int64) int64 {
+	var discount int64
+	switch promo.DiscountType {
+	case entities.DiscountTypePercent:
+		discount = (base*promo.DiscountValue + 50) / 100
+		if promo.MaxDiscount != nil && discount > *promo.MaxDiscount {
+			discount = *promo.MaxDiscount
+		}
+	case entities.DiscountTypeFixed:
+		discount = promo.DiscountValue
+	}
+
+	if discount > base {
+		discount = base
+	}
+	return discount
+}
+
+// normalizePromoCode makes codes case-insensitive; they are stored upper case.
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
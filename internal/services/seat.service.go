@@ -2,30 +2,92 @@ package services
 
 import (
 	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/integrations/realtime"
 	"github.com/senatroxx/filmix-backend/internal/repositories"
 )
 
+var (
+	ErrInvalidSeatCount = errors.New("invalid seat count")
+	ErrSeatTypeNotFound = errors.New("seat type not found")
+	ErrNotEnoughSeats   = errors.New("not enough seats available")
+)
+
+// MaxSuggestedSeats caps how many seats a single suggestion may cover.
+const MaxSuggestedSeats = 10
+
 type SeatWithAvailability struct {
 	entities.Seat
-	IsBooked bool `json:"is_booked"`
+	IsBooked bool  `json:"is_booked"`
+	Price    int64 `json:"price"`
+}
+
+// Kinds of seat map cells besides gaps (see entities.GapKindAisle).
+const (
+	CellKindSeat  = "seat"
+	CellKindEmpty = "empty"
+)
+
+// SeatMap is a showtime's studio as a grid to draw. Cells[r][c] is grid row
+// r+1, column c+1.
+type SeatMap struct {
+	Studio  *entities.Studio
+	Rows    int
+	Columns int
+	Cells   [][]SeatMapCell
+}
+
+// SeatMapCell is one cell of a seat map: a seat, a gap such as an aisle, or
+// nothing. Seat is set for seat cells only.
+type SeatMapCell struct {
+	Kind string
+	Seat *SeatWithAvailability
+}
+
+// SeatWatch follows the seats of a showtime: Held, Booked and Blocked are the
+// seats taken when it started, and the subscription's events every change
+// since.
+type SeatWatch struct {
+	Held    []uuid.UUID
+	Booked  []uuid.UUID
+	Blocked []uuid.UUID
+	*realtime.Subscription
+}
+
+// SeatSuggestion is a set of free seats picked for a group. Each block is a
+// run of adjacent seats in one row; Contiguous means everyone sits together.
+type SeatSuggestion struct {
+	Blocks     [][]SeatWithAvailability
+	Contiguous bool
 }
 
 type ISeatService interface {
 	GetSeatsForShowtime(ctx context.Context, showtimeID uuid.UUID) ([]SeatWithAvailability, error)
+	GetSeatMap(ctx context.Context, showtimeID uuid.UUID) (*SeatMap, error)
+	WatchSeats(ctx context.Context, showtimeID uuid.UUID) (*SeatWatch, error)
+	SuggestSeats(ctx context.Context, showtimeID uuid.UUID, count int, seatType string) (*SeatSuggestion, error)
 }
 
 type SeatService struct {
-	seatRepo     repositories.ISeatRepository
-	showtimeRepo repositories.IShowtimeRepository
+	seatRepo       repositories.ISeatRepository
+	showtimeRepo   repositories.IShowtimeRepository
+	pricingService IPricingService
+	seatEvents     realtime.Broker
 }
 
-func NewSeatService(seatRepo repositories.ISeatRepository, showtimeRepo repositories.IShowtimeRepository) ISeatService {
+func NewSeatService(seatRepo repositories.ISeatRepository, showtimeRepo repositories.IShowtimeRepository, pricingService IPricingService, seatEvents realtime.Broker) ISeatService {
 	return &SeatService{
-		seatRepo:     seatRepo,
-		showtimeRepo: showtimeRepo,
+		seatRepo:       seatRepo,
+		showtimeRepo:   showtimeRepo,
+		pricingService: pricingService,
+		seatEvents:     seatEvents,
 	}
 }
 
@@ -35,12 +97,94 @@ func (s *SeatService) GetSeatsForShowtime(ctx context.Context, showtimeID uuid.U
 		return nil, err
 	}
 
+	return s.seatsForShowtime(ctx, showtime)
+}
+
+// GetSeatMap lays out the seats of a showtime with their availability on the
+// studio's grid. The grid spans every seat and gap; cells with neither are
+// empty.
+func (s *SeatService) GetSeatMap(ctx context.Context, showtimeID uuid.UUID) (*SeatMap, error) {
+	showtime, err := s.showtimeRepo.FindByID(ctx, showtimeID)
+	if err != nil {
+		return nil, ErrShowtimeNotFound
+	}
+
+	seats, err := s.seatsForShowtime(ctx, showtime)
+	if err != nil {
+		return nil, err
+	}
+
+	gaps, err := s.seatRepo.FindGapsByStudioID(ctx, showtime.StudioID)
+	if err != nil {
+		return nil, err
+	}
+
+	seatMap := &SeatMap{Studio: showtime.Studio}
+	for _, seat := range seats {
+		seatMap.Rows = max(seatMap.Rows, seat.GridRow)
+		seatMap.Columns = max(seatMap.Columns, seat.GridColumn)
+	}
+	for _, gap := range gaps {
+		seatMap.Rows = max(seatMap.Rows, gap.GridRow)
+		seatMap.Columns = max(seatMap.Columns, gap.GridColumn)
+	}
+
+	seatMap.Cells = make([][]SeatMapCell, seatMap.Rows)
+	for r := range seatMap.Cells {
+		seatMap.Cells[r] = make([]SeatMapCell, seatMap.Columns)
+		for c := range seatMap.Cells[r] {
+			seatMap.Cells[r][c].Kind = CellKindEmpty
+		}
+	}
+	for _, gap := range gaps {
+		seatMap.Cells[gap.GridRow-1][gap.GridColumn-1].Kind = gap.Kind
+	}
+	for i := range seats {
+		seatMap.Cells[seats[i].GridRow-1][seats[i].GridColumn-1] = SeatMapCell{Kind: CellKindSeat, Seat: &seats[i]}
+	}
+
+	return seatMap, nil
+}
+
+// WatchSeats starts following the seats of a showtime. The caller must close
+// the watch when done.
+func (s *SeatService) WatchSeats(ctx context.Context, showtimeID uuid.UUID) (*SeatWatch, error) {
+	if _, err := s.showtimeRepo.FindByID(ctx, showtimeID); err != nil {
+		return nil, ErrShowtimeNotFound
+	}
+
+	// Subscribing before taking the snapshot means no change can fall in
+	// between; one that lands in both is harmless to apply twice.
+	sub := s.seatEvents.Subscribe(showtimeID)
+
+	held, booked, err := s.seatRepo.FindSeatStates(ctx, showtimeID)
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+	blocked, err := s.seatRepo.FindBlockedSeatIDs(ctx, showtimeID)
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	return &SeatWatch{Held: held, Booked: booked, Blocked: blocked, Subscription: sub}, nil
+}
+
+// seatsForShowtime returns the seats of a showtime's studio that are for sale,
+// with their availability and price.
+func (s *SeatService) seatsForShowtime(ctx context.Context, showtime *entities.Showtime) ([]SeatWithAvailability, error) {
 	seats, err := s.seatRepo.FindByStudioID(ctx, showtime.StudioID)
 	if err != nil {
 		return nil, err
 	}
 
-	bookedIDs, err := s.seatRepo.FindBookedSeatIDs(ctx, showtimeID)
+	bookedIDs, err := s.seatRepo.FindBookedSeatIDs(ctx, showtime.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := s.pricingService.ResolveSeatPrices(ctx, showtime, seats)
 	if err != nil {
 		return nil, err
 	}
@@ -55,8 +199,225 @@ func (s *SeatService) GetSeatsForShowtime(ctx context.Context, showtimeID uuid.U
 		result = append(result, SeatWithAvailability{
 			Seat:     seat,
 			IsBooked: bookedMap[seat.ID],
+			Price:    prices[seat.ID],
 		})
 	}
 
 	return result, nil
 }
+
+// SuggestSeats picks the best free seats for a group of count people,
+// optionally limited to one seat type (by name or ID). See suggestSeats for
+// how blocks are chosen.
+func (s *SeatService) SuggestSeats(ctx context.Context, showtimeID uuid.UUID, count int, seatType string) (*SeatSuggestion, error) {
+	if count < 1 || count > MaxSuggestedSeats {
+		return nil, ErrInvalidSeatCount
+	}
+
+	showtime, err := s.showtimeRepo.FindByID(ctx, showtimeID)
+	if err != nil {
+		return nil, ErrShowtimeNotFound
+	}
+
+	seats, err := s.seatsForShowtime(ctx, showtime)
+	if err != nil {
+		return nil, err
+	}
+
+	matchesType := func(seat entities.Seat) bool { return true }
+	if seatType != "" {
+		typeID, parseErr := uuid.Parse(seatType)
+		matchesType = func(seat entities.Seat) bool {
+			if parseErr == nil {
+				return seat.SeatTypeID == typeID
+			}
+			return seat.SeatType != nil && strings.EqualFold(seat.SeatType.Name, seatType)
+		}
+
+		known := false
+		for _, seat := range seats {
+			if matchesType(seat.Seat) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, ErrSeatTypeNotFound
+		}
+	}
+
+	layout := make([]entities.Seat, len(seats))
+	byID := make(map[uuid.UUID]SeatWithAvailability, len(seats))
+	for i, seat := range seats {
+		layout[i] = seat.Seat
+		byID[seat.ID] = seat
+	}
+
+	blocks := suggestSeats(layout, showtime.Studio.ScreenPosition, func(seat entities.Seat) bool {
+		return !byID[seat.ID].IsBooked && matchesType(seat)
+	}, count)
+	if blocks == nil {
+		return nil, ErrNotEnoughSeats
+	}
+
+	suggestion := &SeatSuggestion{Contiguous: len(blocks) == 1}
+	for _, block := range blocks {
+		var picked []SeatWithAvailability
+		for _, seat := range block {
+			picked = append(picked, byID[seat.ID])
+		}
+		suggestion.Blocks = append(suggestion.Blocks, picked)
+	}
+
+	return suggestion, nil
+}
+
+// publishSeats announces that seats of a showtime changed state. It is best
+// effort: a missed event only leaves a seat map stale until it is reloaded.
+func publishSeats(ctx context.Context, publisher realtime.Publisher, showtimeID uuid.UUID, state string, seatIDs []uuid.UUID, expiresAt *time.Time) {
+	if len(seatIDs) == 0 {
+		return
+	}
+
+	_ = publisher.Publish(ctx, realtime.SeatEvent{
+		ShowtimeID: showtimeID,
+		State:      state,
+		SeatIDs:    seatIDs,
+		ExpiresAt:  expiresAt,
+		At:         time.Now(),
+	})
+}
+
+// itemSeatIDs returns the seats of transaction items.
+func itemSeatIDs(items []entities.TransactionItem) []uuid.UUID {
+	seatIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		seatIDs[i] = item.SeatID
+	}
+	return seatIDs
+}
+
+// idealRowDepth is where in the room, from the screen (0) to the back wall
+// (1), the best row sits.
+const idealRowDepth = 2.0 / 3.0
+
+// seatRun is a stretch of adjacent seats in one row, ordered by column.
+type seatRun struct {
+	row   int
+	seats []entities.Seat
+}
+
+// suggestSeats picks count seats out of a studio layout, where available
+// reports which seats may be taken. Seat map rows are ordered from the screen
+// back, given where the screen is; seats are adjacent when they sit in
+// neighbouring cells, so aisles split a row. A block scores better the closer
+// it is to the middle of its row and to the row idealRowDepth back.
+//
+// The best single block wins. When no row has count adjacent free seats, the
+// group is split into as few blocks as possible, taking the best block of the
+// largest size still available each time. It returns nil if there are fewer
+// than count free seats.
+func suggestSeats(layout []entities.Seat, screen string, available func(entities.Seat) bool, count int) [][]entities.Seat {
+	rows := make(map[int][]entities.Seat)
+	for _, seat := range layout {
+		rows[seat.GridRow] = append(rows[seat.GridRow], seat)
+	}
+
+	gridRows := make([]int, 0, len(rows))
+	for gridRow := range rows {
+		gridRows = append(gridRows, gridRow)
+	}
+	sort.Slice(gridRows, func(i, j int) bool {
+		if screen == entities.ScreenPositionBottom {
+			return gridRows[i] > gridRows[j]
+		}
+		return gridRows[i] < gridRows[j]
+	})
+
+	var runs []seatRun
+	centers := make([]float64, len(gridRows))
+	halfWidths := make([]float64, len(gridRows))
+	free := 0
+
+	for i, gridRow := range gridRows {
+		seats := rows[gridRow]
+		sort.Slice(seats, func(a, b int) bool { return seats[a].GridColumn < seats[b].GridColumn })
+
+		first, last := seats[0].GridColumn, seats[len(seats)-1].GridColumn
+		centers[i] = float64(first+last) / 2
+		halfWidths[i] = float64(last-first) / 2
+
+		var run []entities.Seat
+		for _, seat := range seats {
+			if !available(seat) {
+				if len(run) > 0 {
+					runs = append(runs, seatRun{row: i, seats: run})
+					run = nil
+				}
+				continue
+			}
+			if len(run) > 0 && seat.GridColumn != run[len(run)-1].GridColumn+1 {
+				runs = append(runs, seatRun{row: i, seats: run})
+				run = nil
+			}
+			run = append(run, seat)
+			free++
+		}
+		if len(run) > 0 {
+			runs = append(runs, seatRun{row: i, seats: run})
+		}
+	}
+
+	if free < count {
+		return nil
+	}
+
+	score := func(row int, block []entities.Seat) float64 {
+		var horizontal, depth float64
+		if halfWidths[row] > 0 {
+			mid := float64(block[0].GridColumn+block[len(block)-1].GridColumn) / 2
+			horizontal = math.Abs(mid-centers[row]) / halfWidths[row]
+		}
+		if len(gridRows) > 1 {
+			depth = math.Abs(float64(row)/float64(len(gridRows)-1) - idealRowDepth)
+		}
+		return horizontal + depth
+	}
+
+	var blocks [][]entities.Seat
+	for remaining := count; remaining > 0; {
+		size := 0
+		for _, run := range runs {
+			size = max(size, len(run.seats))
+		}
+		size = min(size, remaining)
+
+		bestRun, bestStart, bestScore := -1, 0, math.Inf(1)
+		for i, run := range runs {
+			for start := 0; start+size <= len(run.seats); start++ {
+				if sc := score(run.row, run.seats[start:start+size]); sc < bestScore {
+					bestRun, bestStart, bestScore = i, start, sc
+				}
+			}
+		}
+
+		run := runs[bestRun]
+		blocks = append(blocks, append([]entities.Seat(nil), run.seats[bestStart:bestStart+size]...))
+
+		// Whatever is left on either side of the block stays up for grabs.
+		rest := []seatRun{
+			{row: run.row, seats: run.seats[:bestStart]},
+			{row: run.row, seats: run.seats[bestStart+size:]},
+		}
+		runs = append(runs[:bestRun], runs[bestRun+1:]...)
+		for _, r := range rest {
+			if len(r.seats) > 0 {
+				runs = append(runs, r)
+			}
+		}
+
+		remaining -= size
+	}
+
+	return blocks
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+var (
+	standardSeatTypeID = uuid.New()
+	vipSeatTypeID      = uuid.New()
+)
+
+// seatMap builds a studio layout from a drawing with one string per grid row,
+// starting at grid row 1. Each character is a cell: 'o' a free seat, 'v' a
+// free VIP seat, 'x' a booked seat and '.' a cell without a seat, such as an
+// aisle or an inactive seat (those never reach suggestSeats). Seats are named
+// by row letter and column, e.g. "B3".
+func seatMap(rows ...string) (layout []entities.Seat, booked map[uuid.UUID]bool) {
+	booked = make(map[uuid.UUID]bool)
+	for r, cells := range rows {
+		for c, cell := range cells {
+			if cell == '.' {
+				continue
+			}
+
+			seat := entities.Seat{
+				ID:         uuid.New(),
+				Row:        string(rune('A' + r)),
+				Number:     c + 1,
+				Active:     true,
+				SeatTypeID: standardSeatTypeID,
+				GridRow:    r + 1,
+				GridColumn: c + 1,
+			}
+			if cell == 'v' {
+				seat.SeatTypeID = vipSeatTypeID
+			}
+			if cell == 'x' {
+				booked[seat.ID] = true
+			}
+			layout = append(layout, seat)
+		}
+	}
+	return layout, booked
+}
+
+func seatNames(blocks [][]entities.Seat) [][]string {
+	if blocks == nil {
+		return nil
+	}
+
+	names := make([][]string, len(blocks))
+	for i, block := range blocks {
+		for _, seat := range block {
+			names[i] = append(names[i], fmt.Sprintf("%s%d", seat.Row, seat.Number))
+		}
+	}
+	return names
+}
+
+func TestSuggestSeats(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     []string
+		screen   string
+		seatType uuid.UUID
+		count    int
+		want     [][]string
+	}{
+		{
+			name:  "contiguous block in the middle of the best row",
+			rows:  []string{"oooooo", "oooooo", "oooooo"},
+			count: 2,
+			want:  [][]string{{"B3", "B4"}},
+		},
+		{
+			name:   "rows counted from a screen at the bottom",
+			rows:   []string{"oooo", "oooo", "oooo", "oooo"},
+			screen: entities.ScreenPositionBottom,
+			count:  2,
+			want:   [][]string{{"B2", "B3"}},
+		},
+		{
+			name:  "block never spans an aisle",
+			rows:  []string{"oooo.ooo"},
+			count: 3,
+			want:  [][]string{{"A2", "A3", "A4"}},
+		},
+		{
+			name:  "booked seats split the best row",
+			rows:  []string{"oooooo", "ooxxoo", "oooooo"},
+			count: 2,
+			want:  [][]string{{"C3", "C4"}},
+		},
+		{
+			name:  "inactive seats leave holes in the best row",
+			rows:  []string{"oooooo", "oo..oo", "oooooo"},
+			count: 2,
+			want:  [][]string{{"C3", "C4"}},
+		},
+		{
+			name:     "seat type filter",
+			rows:     []string{"oooooo", "oooooo", "ovvvvo"},
+			seatType: vipSeatTypeID,
+			count:    2,
+			want:     [][]string{{"C3", "C4"}},
+		},
+		{
+			name:  "count larger than any block is split",
+			rows:  []string{"oooo.oo"},
+			count: 5,
+			want:  [][]string{{"A1", "A2", "A3", "A4"}, {"A6"}},
+		},
+		{
+			name:  "not enough free seats",
+			rows:  []string{"oxo"},
+			count: 3,
+			want:  nil,
+		},
+		{
+			name:     "not enough seats of the type",
+			rows:     []string{"oooo", "ovvo"},
+			seatType: vipSeatTypeID,
+			count:    3,
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout, booked := seatMap(tt.rows...)
+			screen := tt.screen
+			if screen == "" {
+				screen = entities.ScreenPositionTop
+			}
+
+			got := suggestSeats(layout, screen, func(seat entities.Seat) bool {
+				return !booked[seat.ID] && (tt.seatType == uuid.Nil || seat.SeatTypeID == tt.seatType)
+			}, tt.count)
+
+			if names := seatNames(got); !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("suggestSeats() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/integrations/realtime"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+var (
+	ErrSeatBlockNotFound       = errors.New("seat block not found")
+	ErrSeatBlockReleased       = errors.New("seat block already released")
+	ErrInvalidSeatBlock        = errors.New("block either a showtime or a time range that ends in the future")
+	ErrSeatBlockFilter         = errors.New("filter seat blocks by showtime or studio")
+	ErrSeatNotFound            = errors.New("seat not found")
+	ErrSeatsInDifferentStudios = errors.New("seats are in different studios")
+)
+
+// BlockSeatsInput takes seats off sale for one showtime, or for every
+// showtime of their studio starting in [StartsAt, EndsAt).
+type BlockSeatsInput struct {
+	SeatIDs    []uuid.UUID
+	ShowtimeID *uuid.UUID
+	StartsAt   *time.Time
+	EndsAt     *time.Time
+	Reason     string
+	StaffID    uuid.UUID
+}
+
+type ISeatBlockService interface {
+	BlockSeats(ctx context.Context, input BlockSeatsInput) ([]entities.SeatBlock, error)
+	GetBlocks(ctx context.Context, filter repositories.SeatBlockFilter) ([]entities.SeatBlock, error)
+	ReleaseBlock(ctx context.Context, id uuid.UUID, staffID uuid.UUID) (*entities.SeatBlock, error)
+}
+
+type SeatBlockService struct {
+	seatBlockRepo repositories.ISeatBlockRepository
+	seatRepo      repositories.ISeatRepository
+	showtimeRepo  repositories.IShowtimeRepository
+	seatEvents    realtime.Publisher
+}
+
+func NewSeatBlockService(seatBlockRepo repositories.ISeatBlockRepository, seatRepo repositories.ISeatRepository, showtimeRepo repositories.IShowtimeRepository, seatEvents realtime.Publisher) ISeatBlockService {
+	return &SeatBlockService{
+		seatBlockRepo: seatBlockRepo,
+		seatRepo:      seatRepo,
+		showtimeRepo:  showtimeRepo,
+		seatEvents:    seatEvents,
+	}
+}
+
+// BlockSeats creates a block for each seat. The seats must be distinct and
+// in one studio: the showtime's, for a showtime block. Bookings that already
+// have the seats keep them.
+func (s *SeatBlockService) BlockSeats(ctx context.Context, input BlockSeatsInput) ([]entities.SeatBlock, error) {
+	if len(input.SeatIDs) == 0 {
+		return nil, ErrInvalidSeatCount
+	}
+
+	byShowtime := input.ShowtimeID != nil
+	byRange := input.StartsAt != nil || input.EndsAt != nil
+	if byShowtime == byRange {
+		return nil, ErrInvalidSeatBlock
+	}
+	if byRange && (input.StartsAt == nil || input.EndsAt == nil || !input.EndsAt.After(*input.StartsAt) || !input.EndsAt.After(time.Now())) {
+		return nil, ErrInvalidSeatBlock
+	}
+
+	var showtime *entities.Showtime
+	if byShowtime {
+		var err error
+		showtime, err = s.showtimeRepo.FindByID(ctx, *input.ShowtimeID)
+		if err != nil {
+			return nil, ErrShowtimeNotFound
+		}
+		if !showtime.Time.After(time.Now()) {
+			return nil, ErrShowtimeStarted
+		}
+	}
+
+	seats, err := s.validateSeats(ctx, showtime, input.SeatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]entities.SeatBlock, len(input.SeatIDs))
+	for i, seatID := range input.SeatIDs {
+		blocks[i] = entities.SeatBlock{
+			ID:         uuid.New(),
+			SeatID:     seatID,
+			ShowtimeID: input.ShowtimeID,
+			StartsAt:   input.StartsAt,
+			EndsAt:     input.EndsAt,
+			Reason:     input.Reason,
+			CreatedBy:  input.StaffID,
+		}
+		seat := seats[seatID]
+		blocks[i].Seat = &seat
+	}
+	if err := s.seatBlockRepo.Create(ctx, blocks); err != nil {
+		return nil, fmt.Errorf("failed to block seats: %w", err)
+	}
+
+	showtimeSeats, err := s.affectedShowtimes(ctx, &blocks[0], input.SeatIDs)
+	if err == nil {
+		for showtimeID, seatIDs := range showtimeSeats {
+			publishSeats(ctx, s.seatEvents, showtimeID, realtime.SeatBlocked, seatIDs, nil)
+		}
+	}
+
+	return blocks, nil
+}
+
+// validateSeats checks that the seats to block are distinct, known seats of
+// one studio, the showtime's if there is one, and returns them by ID.
+func (s *SeatBlockService) validateSeats(ctx context.Context, showtime *entities.Showtime, seatIDs []uuid.UUID) (map[uuid.UUID]entities.Seat, error) {
+	seen := make(map[uuid.UUID]bool, len(seatIDs))
+	var duplicates []uuid.UUID
+	for _, id := range seatIDs {
+		if seen[id] {
+			duplicates = append(duplicates, id)
+		}
+		seen[id] = true
+	}
+	if len(duplicates) > 0 {
+		return nil, &SeatSelectionError{Err: ErrDuplicateSeats, SeatIDs: duplicates}
+	}
+
+	seats, err := s.seatRepo.FindByIDs(ctx, seatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seats: %w", err)
+	}
+
+	found := make(map[uuid.UUID]entities.Seat, len(seats))
+	for _, seat := range seats {
+		found[seat.ID] = seat
+	}
+
+	var unknown, foreign []uuid.UUID
+	studioID := uuid.Nil
+	if showtime != nil {
+		studioID = showtime.StudioID
+	}
+	for _, id := range seatIDs {
+		seat, ok := found[id]
+		switch {
+		case !ok:
+			unknown = append(unknown, id)
+		case studioID == uuid.Nil:
+			studioID = seat.StudioID
+		case seat.StudioID != studioID:
+			foreign = append(foreign, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, &SeatSelectionError{Err: ErrSeatNotFound, SeatIDs: unknown}
+	}
+	if len(foreign) > 0 && showtime != nil {
+		return nil, &SeatSelectionError{Err: ErrSeatNotInStudio, SeatIDs: foreign}
+	}
+	if len(foreign) > 0 {
+		return nil, &SeatSelectionError{Err: ErrSeatsInDifferentStudios, SeatIDs: foreign}
+	}
+
+	return found, nil
+}
+
+func (s *SeatBlockService) GetBlocks(ctx context.Context, filter repositories.SeatBlockFilter) ([]entities.SeatBlock, error) {
+	if filter.ShowtimeID == nil && filter.StudioID == nil {
+		return nil, ErrSeatBlockFilter
+	}
+
+	return s.seatBlockRepo.FindActive(ctx, filter)
+}
+
+// ReleaseBlock puts a blocked seat back on sale. Showtimes with a waitlist
+// offer it to the next person in line on the waitlist worker's next pass.
+func (s *SeatBlockService) ReleaseBlock(ctx context.Context, id uuid.UUID, staffID uuid.UUID) (*entities.SeatBlock, error) {
+	block, err := s.seatBlockRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrSeatBlockNotFound
+	}
+
+	released, err := s.seatBlockRepo.Release(ctx, id, staffID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release seat block: %w", err)
+	}
+	if !released {
+		return nil, ErrSeatBlockReleased
+	}
+
+	// The seat may still be taken for a showtime, by a booking or another
+	// block; only announce it where it is free now.
+	showtimeSeats, err := s.affectedShowtimes(ctx, block, []uuid.UUID{block.SeatID})
+	if err == nil {
+		for showtimeID := range showtimeSeats {
+			takenIDs, err := s.seatRepo.FindBookedSeatIDs(ctx, showtimeID)
+			if err != nil || slices.Contains(takenIDs, block.SeatID) {
+				continue
+			}
+			publishSeats(ctx, s.seatEvents, showtimeID, realtime.SeatReleased, []uuid.UUID{block.SeatID}, nil)
+		}
+	}
+
+	return s.seatBlockRepo.FindByID(ctx, id)
+}
+
+// affectedShowtimes maps the upcoming showtimes a block covers to its seats
+// among seatIDs.
+func (s *SeatBlockService) affectedShowtimes(ctx context.Context, block *entities.SeatBlock, seatIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	if block.ShowtimeID != nil {
+		return map[uuid.UUID][]uuid.UUID{*block.ShowtimeID: seatIDs}, nil
+	}
+
+	return s.seatBlockRepo.FindUpcomingShowtimeSeats(ctx, seatIDs, *block.StartsAt, *block.EndsAt)
+}
@@ -1,21 +1,83 @@
 package services
 
-import "github.com/senatroxx/filmix-backend/internal/repositories"
+import (
+	"time"
+
+	"github.com/senatroxx/filmix-backend/internal/integrations/notification"
+	"github.com/senatroxx/filmix-backend/internal/integrations/payment"
+	"github.com/senatroxx/filmix-backend/internal/integrations/realtime"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
 
 type Services struct {
-	AuthService     IAuthService
-	MovieService    IMovieService
-	ShowtimeService IShowtimeService
-	SeatService     ISeatService
-	BookingService  IBookingService
+	AuthService        IAuthService
+	MovieService       IMovieService
+	ShowtimeService    IShowtimeService
+	SeatService        ISeatService
+	BookingService     IBookingService
+	PaymentService     IPaymentService
+	PricingService     IPricingService
+	PromotionService   IPromotionService
+	FeeService         IFeeService
+	ConcessionService  IConcessionService
+	TransferService    ITransferService
+	CalendarService    ICalendarService
+	SeatBlockService   ISeatBlockService
+	StudioService      IStudioService
+	TicketService      ITicketService
+	IdempotencyService IIdempotencyService
+	WaitlistService    IWaitlistService
 }
 
-func RegisterServices(r *repositories.Repositories) *Services {
-	return &Services{
-		AuthService:     NewAuthService(r.UserRepository),
-		MovieService:    NewMovieService(r.MovieRepository),
-		ShowtimeService: NewShowtimeService(r.ShowtimeRepository),
-		SeatService:     NewSeatService(r.SeatRepository, r.ShowtimeRepository),
-		BookingService:  NewBookingService(r.BookingRepository, r.ShowtimeRepository, r.SeatRepository),
+// Options carries service dependencies that come from configuration rather
+// than from the database.
+type Options struct {
+	Payments               *payment.Registry
+	AllowPaymentSimulation bool
+	// Location is the local time zone of the theaters, used for day types and
+	// invoice periods.
+	Location *time.Location
+	Refunds  RefundPolicy
+	Limits   PurchaseLimits
+	Tickets  TicketPolicy
+	// IdempotencyTTL is how long responses are kept for Idempotency-Key replay.
+	IdempotencyTTL time.Duration
+	Notifier       notification.Notifier
+	// WaitlistHoldTTL is how long seats offered to a waitlisted user stay
+	// theirs.
+	WaitlistHoldTTL time.Duration
+	// SeatEvents carries seat changes to live seat maps.
+	SeatEvents realtime.Broker
+}
+
+func RegisterServices(r *repositories.Repositories, opts Options) (*Services, error) {
+	ticketService, err := NewTicketService(r.BookingRepository, opts.Tickets)
+	if err != nil {
+		return nil, err
 	}
+
+	pricingService := NewPricingService(r.PricingRepository, opts.Location)
+	promotionService := NewPromotionService(r.PromotionRepository)
+	feeService := NewFeeService(r.FeeRepository)
+	bookingService := NewBookingService(r.BookingRepository, r.ShowtimeRepository, r.SeatRepository, r.PaymentMethodRepository, r.WaitlistRepository, r.ConcessionRepository, pricingService, promotionService, feeService, opts.Payments, opts.Refunds, opts.Limits, opts.SeatEvents)
+
+	return &Services{
+		AuthService:        NewAuthService(r.UserRepository),
+		MovieService:       NewMovieService(r.MovieRepository),
+		ShowtimeService:    NewShowtimeService(r.ShowtimeRepository),
+		SeatService:        NewSeatService(r.SeatRepository, r.ShowtimeRepository, pricingService, opts.SeatEvents),
+		BookingService:     bookingService,
+		PaymentService:     NewPaymentService(r.BookingRepository, r.PaymentMethodRepository, opts.Payments, opts.AllowPaymentSimulation, opts.Location, opts.SeatEvents),
+		PricingService:     pricingService,
+		PromotionService:   promotionService,
+		FeeService:         feeService,
+		ConcessionService:  NewConcessionService(r.ConcessionRepository),
+		TransferService:    NewTransferService(r.TransferRepository, r.BookingRepository, r.UserRepository, opts.Notifier, opts.Location),
+		CalendarService:    NewCalendarService(r.CalendarRepository, r.BookingRepository),
+		SeatBlockService:   NewSeatBlockService(r.SeatBlockRepository, r.SeatRepository, r.ShowtimeRepository, opts.SeatEvents),
+		StudioService:      NewStudioService(r.StudioRepository, r.SeatRepository),
+		TicketService:      ticketService,
+		IdempotencyService: NewIdempotencyService(r.IdempotencyRepository, opts.IdempotencyTTL),
+		WaitlistService:    NewWaitlistService(r.WaitlistRepository, r.ShowtimeRepository, r.SeatRepository, bookingService, opts.Notifier, opts.SeatEvents, opts.WaitlistHoldTTL, opts.Location),
+	}, nil
 }
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,11 +25,43 @@ func NewShowtimeService(showtimeRepo repositories.IShowtimeRepository) IShowtime
 }
 
 func (s *ShowtimeService) GetShowtimesByMovieID(ctx context.Context, movieID uuid.UUID, date *time.Time) ([]entities.Showtime, error) {
-	return s.showtimeRepo.FindByMovieID(ctx, movieID, date)
+	showtimes, err := s.showtimeRepo.FindByMovieID(ctx, movieID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return showtimes, s.loadAvailability(ctx, showtimes)
 }
 
 func (s *ShowtimeService) GetShowtimesByTheaterID(ctx context.Context, theaterID uuid.UUID, date *time.Time) ([]entities.Showtime, error) {
-	return s.showtimeRepo.FindByTheaterID(ctx, theaterID, date)
+	showtimes, err := s.showtimeRepo.FindByTheaterID(ctx, theaterID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return showtimes, s.loadAvailability(ctx, showtimes)
+}
+
+// loadAvailability fills in the seat counts of the showtimes.
+func (s *ShowtimeService) loadAvailability(ctx context.Context, showtimes []entities.Showtime) error {
+	if len(showtimes) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(showtimes))
+	for i, showtime := range showtimes {
+		ids[i] = showtime.ID
+	}
+
+	availability, err := s.showtimeRepo.FindAvailability(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to count seats: %w", err)
+	}
+
+	for i := range showtimes {
+		showtimes[i].Availability = availability[showtimes[i].ID]
+	}
+	return nil
 }
 
 func (s *ShowtimeService) GetShowtimeByID(ctx context.Context, id uuid.UUID) (*entities.Showtime, error) {
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+var (
+	ErrStudioNotFound = errors.New("studio not found")
+	ErrSeatsInUse     = errors.New("seat is booked for an upcoming showtime")
+)
+
+// LayoutChanges is what importing a layout does to a studio's seats, by seat
+// label (row and number, e.g. A1). Seats are matched by label: Retyped seats
+// change seat type and Updated seats move or change flags, keeping their
+// history either way.
+type LayoutChanges struct {
+	Added       []string
+	Reactivated []string
+	Deactivated []string
+	Retyped     []string
+	Updated     []string
+	Gaps        int
+	Applied     bool
+}
+
+type IStudioService interface {
+	ImportLayout(ctx context.Context, studioID uuid.UUID, layout *StudioLayout, dryRun bool) (*LayoutChanges, error)
+	ExportLayout(ctx context.Context, studioID uuid.UUID) (*StudioLayout, error)
+}
+
+type StudioService struct {
+	studioRepo repositories.IStudioRepository
+	seatRepo   repositories.ISeatRepository
+}
+
+func NewStudioService(studioRepo repositories.IStudioRepository, seatRepo repositories.ISeatRepository) IStudioService {
+	return &StudioService{
+		studioRepo: studioRepo,
+		seatRepo:   seatRepo,
+	}
+}
+
+// ImportLayout checks a layout against the studio's cinema and replaces the
+// studio's seat map with it, unless dryRun is set. Seats left out are taken
+// off sale, which fails with a SeatSelectionError wrapping ErrSeatsInUse while
+// any of them is booked for an upcoming showtime.
+func (s *StudioService) ImportLayout(ctx context.Context, studioID uuid.UUID, layout *StudioLayout, dryRun bool) (*LayoutChanges, error) {
+	studio, err := s.studioRepo.FindByID(ctx, studioID)
+	if err != nil {
+		return nil, ErrStudioNotFound
+	}
+
+	parsed, err := layout.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	seatTypes, err := s.studioRepo.FindSeatTypes(ctx, studio.Theater.CinemaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seat types: %w", err)
+	}
+	typeIDs := make(map[string]uuid.UUID, len(layout.Types))
+	problems := &LayoutError{}
+	for code, name := range layout.Types {
+		i := slices.IndexFunc(seatTypes, func(t entities.SeatType) bool { return strings.EqualFold(t.Name, name) })
+		if i < 0 {
+			problems.add("seat type %q of code %q is not a seat type of this cinema", name, code)
+			continue
+		}
+		typeIDs[code] = seatTypes[i].ID
+	}
+	if len(problems.Problems) > 0 {
+		return nil, problems
+	}
+
+	existing, err := s.studioRepo.FindSeats(ctx, studioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seats: %w", err)
+	}
+	// A label may belong to several seats that were taken off sale over time;
+	// the one on sale, or else the first, carries it on.
+	byLabel := make(map[string]entities.Seat, len(existing))
+	for _, seat := range existing {
+		label := seatLabel(&seat)
+		if current, ok := byLabel[label]; !ok || (!current.Active && seat.Active) {
+			byLabel[label] = seat
+		}
+	}
+
+	changes := &LayoutChanges{Gaps: len(parsed.Gaps)}
+	seats := make([]entities.Seat, len(parsed.Seats))
+	for i, p := range parsed.Seats {
+		seat := p.Seat
+		seat.StudioID = studioID
+		seat.SeatTypeID = typeIDs[p.TypeCode]
+
+		label := seatLabel(&seat)
+		old, ok := byLabel[label]
+		switch {
+		case !ok:
+			seat.ID = uuid.New()
+			changes.Added = append(changes.Added, label)
+		case !old.Active:
+			seat.ID = old.ID
+			changes.Reactivated = append(changes.Reactivated, label)
+		default:
+			seat.ID = old.ID
+			if old.SeatTypeID != seat.SeatTypeID {
+				changes.Retyped = append(changes.Retyped, label)
+			}
+		}
+		seats[i] = seat
+	}
+
+	labelsByID := make(map[uuid.UUID]string, len(existing))
+	for _, seat := range existing {
+		labelsByID[seat.ID] = seatLabel(&seat)
+	}
+	for i, p := range parsed.Seats {
+		if p.PairIndex >= 0 {
+			seats[i].PairedWithID = &seats[p.PairIndex].ID
+		}
+
+		old, ok := byLabel[seatLabel(&seats[i])]
+		if !ok || !old.Active {
+			continue
+		}
+		oldPair, newPair := "", ""
+		if old.PairedWithID != nil {
+			oldPair = labelsByID[*old.PairedWithID]
+		}
+		if p.PairIndex >= 0 {
+			newPair = seatLabel(&seats[p.PairIndex])
+		}
+		if old.GridRow != seats[i].GridRow || old.GridColumn != seats[i].GridColumn ||
+			old.Wheelchair != seats[i].Wheelchair || old.Companion != seats[i].Companion || oldPair != newPair {
+			changes.Updated = append(changes.Updated, seatLabel(&seats[i]))
+		}
+	}
+
+	kept := make(map[uuid.UUID]bool, len(seats))
+	for _, seat := range seats {
+		kept[seat.ID] = true
+	}
+	var removed []uuid.UUID
+	for _, seat := range existing {
+		if seat.Active && !kept[seat.ID] {
+			removed = append(removed, seat.ID)
+			changes.Deactivated = append(changes.Deactivated, seatLabel(&seat))
+		}
+	}
+
+	if len(removed) > 0 {
+		inUse, err := s.studioRepo.FindSeatsInUse(ctx, removed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check seats in use: %w", err)
+		}
+		if len(inUse) > 0 {
+			return nil, &SeatSelectionError{Err: ErrSeatsInUse, SeatIDs: inUse}
+		}
+	}
+
+	if dryRun {
+		return changes, nil
+	}
+
+	gaps := make([]entities.StudioGap, len(parsed.Gaps))
+	for i, gap := range parsed.Gaps {
+		gap.ID = uuid.New()
+		gap.StudioID = studioID
+		gaps[i] = gap
+	}
+
+	studio.ScreenPosition = parsed.Screen
+	if err := s.studioRepo.ApplyLayout(ctx, studio, seats, gaps); err != nil {
+		if errors.Is(err, repositories.ErrSeatsTaken) {
+			return nil, &SeatSelectionError{Err: ErrSeatsInUse, SeatIDs: removed}
+		}
+		return nil, fmt.Errorf("failed to apply layout: %w", err)
+	}
+
+	changes.Applied = true
+	return changes, nil
+}
+
+// ExportLayout writes a studio's seat map as a layout that imports back
+// unchanged. Seat types get codes from their names.
+func (s *StudioService) ExportLayout(ctx context.Context, studioID uuid.UUID) (*StudioLayout, error) {
+	studio, err := s.studioRepo.FindByID(ctx, studioID)
+	if err != nil {
+		return nil, ErrStudioNotFound
+	}
+
+	seats, err := s.studioRepo.FindSeats(ctx, studioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seats: %w", err)
+	}
+	gaps, err := s.seatRepo.FindGapsByStudioID(ctx, studioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gaps: %w", err)
+	}
+
+	var active []entities.Seat
+	var seatTypes []entities.SeatType
+	for _, seat := range seats {
+		if !seat.Active {
+			continue
+		}
+		active = append(active, seat)
+		if !slices.ContainsFunc(seatTypes, func(t entities.SeatType) bool { return t.ID == seat.SeatTypeID }) {
+			seatTypes = append(seatTypes, *seat.SeatType)
+		}
+	}
+	codes := seatTypeCodes(seatTypes)
+
+	rows, columns := 0, 0
+	for _, seat := range active {
+		rows = max(rows, seat.GridRow)
+		columns = max(columns, seat.GridColumn)
+	}
+	for _, gap := range gaps {
+		rows = max(rows, gap.GridRow)
+		columns = max(columns, gap.GridColumn)
+	}
+
+	layout := &StudioLayout{
+		Screen: studio.ScreenPosition,
+		Types:  make(map[string]string, len(seatTypes)),
+		Rows:   make([]LayoutRow, rows),
+	}
+	for _, seatType := range seatTypes {
+		layout.Types[codes[seatType.ID]] = seatType.Name
+	}
+	for r := range layout.Rows {
+		layout.Rows[r].Cells = make([]string, columns)
+	}
+	for _, gap := range gaps {
+		token := layoutAisle
+		if gap.Kind == entities.GapKindStairs {
+			token = layoutStairs
+		}
+		layout.Rows[gap.GridRow-1].Cells[gap.GridColumn-1] = token
+	}
+	for _, seat := range active {
+		row := &layout.Rows[seat.GridRow-1]
+		if row.Label == "" {
+			row.Label = seat.Row
+		}
+		row.Cells[seat.GridColumn-1] = layoutSeatToken(codes[seat.SeatTypeID], seat)
+	}
+
+	// Drop the empty cells at the end of each row.
+	for r := range layout.Rows {
+		cells := layout.Rows[r].Cells
+		for len(cells) > 0 && cells[len(cells)-1] == layoutEmpty {
+			cells = cells[:len(cells)-1]
+		}
+		layout.Rows[r].Cells = cells
+	}
+
+	return layout, nil
+}
+
+// seatTypeCodes gives each seat type a distinct code of letters: the
+// shortest prefix of its name that is still free, or its initial followed by
+// as many extra letters as it takes.
+func seatTypeCodes(seatTypes []entities.SeatType) map[uuid.UUID]string {
+	codes := make(map[uuid.UUID]string, len(seatTypes))
+	taken := make(map[string]bool, len(seatTypes))
+
+	for _, seatType := range seatTypes {
+		letters := []rune(strings.ToUpper(strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) {
+				return r
+			}
+			return -1
+		}, seatType.Name)))
+		if len(letters) == 0 {
+			letters = []rune("T")
+		}
+
+		code := ""
+		for n := 1; n <= len(letters); n++ {
+			if !taken[string(letters[:n])] {
+				code = string(letters[:n])
+				break
+			}
+		}
+		for suffix := 0; code == ""; suffix++ {
+			if candidate := string(letters[:1]) + strings.Repeat("X", suffix/26) + string(rune('A'+suffix%26)); !taken[candidate] {
+				code = candidate
+			}
+		}
+
+		taken[code] = true
+		codes[seatType.ID] = code
+	}
+
+	return codes
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+)
+
+// A studio layout is the seat map of a studio as text. In CSV, each record
+// starts with its kind:
+//
+//	screen,top
+//	type,S,Standard
+//	type,V,VIP
+//	row,A,S1,S2,|,S3,S4
+//	row,B,S1/w,S2/c,|,V3/p,V4/p
+//
+// screen says which edge of the map faces the screen. type maps a seat type
+// code (letters only) to the name of one of the cinema's seat types. Each row
+// record is one grid row, in order: its label, then one token per grid
+// column. A token is empty for an empty cell, | for an aisle, # for stairs,
+// or a seat: type code and number, optionally followed by / and flags (w for
+// a wheelchair space, c for a companion seat, p for half a couple seat).
+// Adjacent couple seats pair up from the left. A row without seats may have
+// an empty label.
+//
+// The JSON form holds the same: {"screen": "top", "types": {"S": "Standard"},
+// "rows": [{"label": "A", "cells": ["S1", "S2", "|"]}]}.
+type StudioLayout struct {
+	Screen string            `json:"screen"`
+	Types  map[string]string `json:"types"`
+	Rows   []LayoutRow       `json:"rows"`
+}
+
+type LayoutRow struct {
+	Label string   `json:"label"`
+	Cells []string `json:"cells"`
+}
+
+// Tokens of the layout cells that hold no seat.
+const (
+	layoutEmpty  = ""
+	layoutAisle  = "|"
+	layoutStairs = "#"
+)
+
+// LayoutError lists everything wrong with a studio layout.
+type LayoutError struct {
+	Problems []string
+}
+
+func (e *LayoutError) Error() string {
+	return "invalid studio layout: " + strings.Join(e.Problems, "; ")
+}
+
+func (e *LayoutError) add(format string, args ...any) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+// layoutSeat is a seat of a parsed layout.
+type layoutSeat struct {
+	entities.Seat
+	TypeCode string
+	// Couple marks half a couple seat; PairIndex is the other half, as an
+	// index into the parsed seats.
+	Couple    bool
+	PairIndex int
+}
+
+// parsedLayout is a studio layout checked and laid out on the grid.
+type parsedLayout struct {
+	Screen string
+	Seats  []layoutSeat
+	Gaps   []entities.StudioGap
+}
+
+// ParseLayoutCSV reads a studio layout in its CSV form.
+func ParseLayoutCSV(r io.Reader) (*StudioLayout, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	layout := &StudioLayout{Types: make(map[string]string)}
+	problems := &LayoutError{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, &LayoutError{Problems: []string{err.Error()}}
+		}
+		line, _ := reader.FieldPos(0)
+
+		switch strings.TrimSpace(record[0]) {
+		case "screen":
+			if len(record) != 2 {
+				problems.add("line %d: screen takes one value", line)
+				continue
+			}
+			layout.Screen = strings.TrimSpace(record[1])
+		case "type":
+			if len(record) != 3 {
+				problems.add("line %d: type takes a code and a name", line)
+				continue
+			}
+			code := strings.TrimSpace(record[1])
+			if _, ok := layout.Types[code]; ok {
+				problems.add("line %d: seat type code %q is defined twice", line, code)
+				continue
+			}
+			layout.Types[code] = strings.TrimSpace(record[2])
+		case "row":
+			if len(record) < 2 {
+				problems.add("line %d: row needs a label", line)
+				continue
+			}
+			row := LayoutRow{Label: strings.TrimSpace(record[1])}
+			for _, cell := range record[2:] {
+				row.Cells = append(row.Cells, strings.TrimSpace(cell))
+			}
+			layout.Rows = append(layout.Rows, row)
+		default:
+			problems.add("line %d: unknown record %q", line, record[0])
+		}
+	}
+
+	if len(problems.Problems) > 0 {
+		return nil, problems
+	}
+	return layout, nil
+}
+
+// ParseLayoutJSON reads a studio layout in its JSON form.
+func ParseLayoutJSON(r io.Reader) (*StudioLayout, error) {
+	var layout StudioLayout
+	if err := json.NewDecoder(r).Decode(&layout); err != nil {
+		return nil, &LayoutError{Problems: []string{err.Error()}}
+	}
+	return &layout, nil
+}
+
+// WriteCSV writes the layout in its CSV form, seat types sorted by code.
+func (l *StudioLayout) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"screen", l.Screen}); err != nil {
+		return err
+	}
+
+	codes := make([]string, 0, len(l.Types))
+	for code := range l.Types {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	for _, code := range codes {
+		if err := writer.Write([]string{"type", code, l.Types[code]}); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range l.Rows {
+		record := append([]string{"row", row.Label}, row.Cells...)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// parse checks the layout and places its seats and gaps on the grid. Seat
+// types are left as codes for the caller to resolve.
+func (l *StudioLayout) parse() (*parsedLayout, error) {
+	problems := &LayoutError{}
+	parsed := &parsedLayout{Screen: l.Screen}
+
+	if parsed.Screen == "" {
+		parsed.Screen = entities.ScreenPositionTop
+	}
+	if parsed.Screen != entities.ScreenPositionTop && parsed.Screen != entities.ScreenPositionBottom {
+		problems.add("screen must be %q or %q", entities.ScreenPositionTop, entities.ScreenPositionBottom)
+	}
+
+	for code, name := range l.Types {
+		if code == "" || strings.IndexFunc(code, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+			problems.add("seat type code %q must be letters only", code)
+		}
+		if name == "" {
+			problems.add("seat type code %q needs a name", code)
+		}
+	}
+
+	labels := make(map[string]bool)
+	for r, row := range l.Rows {
+		gridRow := r + 1
+		where := fmt.Sprintf("row %d", gridRow)
+		if row.Label != "" {
+			where = fmt.Sprintf("row %s", row.Label)
+			if labels[row.Label] {
+				problems.add("%s appears more than once", where)
+			}
+			labels[row.Label] = true
+		}
+
+		numbers := make(map[int]bool)
+		// coupleRun is the couple seats just before the current cell that
+		// haven't been paired yet.
+		var coupleRun []int
+		endCoupleRun := func(column int) {
+			if len(coupleRun)%2 != 0 {
+				problems.add("%s, column %d: couple seat has no partner", where, column)
+			}
+			coupleRun = nil
+		}
+
+		for c, cell := range row.Cells {
+			gridColumn := c + 1
+			switch cell {
+			case layoutEmpty:
+				endCoupleRun(gridColumn - 1)
+				continue
+			case layoutAisle, layoutStairs:
+				endCoupleRun(gridColumn - 1)
+				kind := entities.GapKindAisle
+				if cell == layoutStairs {
+					kind = entities.GapKindStairs
+				}
+				parsed.Gaps = append(parsed.Gaps, entities.StudioGap{GridRow: gridRow, GridColumn: gridColumn, Kind: kind})
+				continue
+			}
+
+			seat, err := parseLayoutSeat(cell)
+			if err != nil {
+				problems.add("%s, column %d: %v", where, gridColumn, err)
+				endCoupleRun(gridColumn - 1)
+				continue
+			}
+			if row.Label == "" {
+				problems.add("%s, column %d: a row with seats needs a label", where, gridColumn)
+			}
+			if _, ok := l.Types[seat.TypeCode]; !ok {
+				problems.add("%s, column %d: unknown seat type code %q", where, gridColumn, seat.TypeCode)
+			}
+			if numbers[seat.Number] {
+				problems.add("%s, column %d: seat number %d appears more than once", where, gridColumn, seat.Number)
+			}
+			numbers[seat.Number] = true
+
+			seat.Row = row.Label
+			seat.GridRow = gridRow
+			seat.GridColumn = gridColumn
+			seat.Active = true
+
+			if !seat.Couple {
+				endCoupleRun(gridColumn - 1)
+			} else {
+				coupleRun = append(coupleRun, len(parsed.Seats))
+				if len(coupleRun)%2 == 0 {
+					left, right := coupleRun[len(coupleRun)-2], len(parsed.Seats)
+					parsed.Seats[left].PairIndex = right
+					seat.PairIndex = left
+				}
+			}
+			parsed.Seats = append(parsed.Seats, *seat)
+		}
+		endCoupleRun(len(row.Cells))
+	}
+
+	if len(parsed.Seats) == 0 {
+		problems.add("layout has no seats")
+	}
+
+	if len(problems.Problems) > 0 {
+		return nil, problems
+	}
+	return parsed, nil
+}
+
+// parseLayoutSeat reads a seat token such as V12/wp.
+func parseLayoutSeat(token string) (*layoutSeat, error) {
+	name, flags, _ := strings.Cut(token, "/")
+
+	split := strings.IndexFunc(name, func(r rune) bool { return !unicode.IsLetter(r) })
+	if split <= 0 {
+		return nil, fmt.Errorf("%q is not a seat, an aisle (%s) or stairs (%s)", token, layoutAisle, layoutStairs)
+	}
+	number, err := strconv.Atoi(name[split:])
+	if err != nil || number < 1 {
+		return nil, fmt.Errorf("%q has no valid seat number", token)
+	}
+
+	seat := &layoutSeat{TypeCode: name[:split], PairIndex: -1}
+	seat.Number = number
+	for _, flag := range flags {
+		switch flag {
+		case 'w':
+			seat.Wheelchair = true
+		case 'c':
+			seat.Companion = true
+		case 'p':
+			seat.Couple = true
+		default:
+			return nil, fmt.Errorf("%q has unknown flag %q", token, flag)
+		}
+	}
+	if seat.Wheelchair && seat.Companion {
+		return nil, fmt.Errorf("%q can't be both a wheelchair space and a companion seat", token)
+	}
+
+	return seat, nil
+}
+
+// layoutSeatToken writes a seat as a layout token.
+func layoutSeatToken(code string, seat entities.Seat) string {
+	token := code + strconv.Itoa(seat.Number)
+
+	var flags string
+	if seat.Wheelchair {
+		flags += "w"
+	}
+	if seat.Companion {
+		flags += "c"
+	}
+	if seat.PairedWithID != nil {
+		flags += "p"
+	}
+	if flags != "" {
+		token += "/" + flags
+	}
+
+	return token
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/integrations/payment"
+	"github.com/senatroxx/filmix-backend/internal/integrations/realtime"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+// openTestDB connects to the Postgres database in TEST_DATABASE_URL (a
+// postgres:// URL) and migrates it to the latest version. Tests that need it
+// are skipped when the variable is unset.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	m, err := migrate.New("file://../database/migrations", dsn)
+	if err != nil {
+		t.Fatalf("failed to open migrations: %v", err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	m.Close()
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// bookingFixture is a bookable showtime of its own, with a payment method
+// backed by the simulated provider. Everything it creates is removed when
+// the test ends.
+type bookingFixture struct {
+	db              *sql.DB
+	bookings        IBookingService
+	roleID          uuid.UUID
+	showtimeID      uuid.UUID
+	seatIDs         []uuid.UUID
+	paymentMethodID uuid.UUID
+}
+
+func newBookingFixture(t *testing.T, seatCount int) *bookingFixture {
+	t.Helper()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+	suffix := uuid.NewString()[:8]
+
+	f := &bookingFixture{
+		db:              db,
+		roleID:          uuid.New(),
+		showtimeID:      uuid.New(),
+		paymentMethodID: uuid.New(),
+	}
+	cinemaID, theaterID, studioID := uuid.New(), uuid.New(), uuid.New()
+	seatTypeID, pricingID := uuid.New(), uuid.New()
+	movieID, statusID, ratingID := uuid.New(), uuid.New(), uuid.New()
+	methodTypeID := uuid.New()
+	methodCode := "TEST_" + suffix
+	showtimeAt := time.Now().Add(48 * time.Hour)
+
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := db.ExecContext(ctx, query, args...); err != nil {
+			t.Fatalf("failed to set up booking fixture: %v", err)
+		}
+	}
+
+	t.Cleanup(func() {
+		for _, cleanup := range []struct {
+			query string
+			id    uuid.UUID
+		}{
+			{`DELETE FROM cinemas WHERE id = $1`, cinemaID},
+			{`DELETE FROM movies WHERE id = $1`, movieID},
+			{`DELETE FROM movie_statuses WHERE id = $1`, statusID},
+			{`DELETE FROM movie_ratings WHERE id = $1`, ratingID},
+			{`DELETE FROM roles WHERE id = $1`, f.roleID},
+			{`DELETE FROM payment_method_types WHERE id = $1`, methodTypeID},
+		} {
+			if _, err := db.ExecContext(ctx, cleanup.query, cleanup.id); err != nil {
+				t.Logf("failed to clean up booking fixture: %v", err)
+			}
+		}
+	})
+
+	exec(`INSERT INTO roles (id, name) VALUES ($1, $2)`, f.roleID, "customer-"+suffix)
+	exec(`INSERT INTO movie_statuses (id, status) VALUES ($1, $2)`, statusID, "status-"+suffix)
+	exec(`INSERT INTO movie_ratings (id, rating) VALUES ($1, $2)`, ratingID, "rating-"+suffix)
+	exec(`
+		INSERT INTO movies (id, title, tagline, overview, poster_url, backdrop_url, trailer_url, duration, popularity, movie_status_id, movie_rating_id)
+		VALUES ($1, $2, '', '', '', '', '', 120, 0, $3, $4)
+	`, movieID, "Movie "+suffix, statusID, ratingID)
+	exec(`INSERT INTO cinemas (id, name, logo_url) VALUES ($1, $2, '')`, cinemaID, "Cinema "+suffix)
+	exec(`
+		INSERT INTO theaters (id, name, code, address, latitude, longitude, cinema_id)
+		VALUES ($1, $2, $3, '', 0, 0, $4)
+	`, theaterID, "Theater "+suffix, "T"+suffix, cinemaID)
+	exec(`INSERT INTO studios (id, name, theater_id) VALUES ($1, 'Studio 1', $2)`, studioID, theaterID)
+	exec(`INSERT INTO seat_type (id, name, cinema_id) VALUES ($1, 'Standard', $2)`, seatTypeID, cinemaID)
+	exec(`
+		INSERT INTO seat_pricings (id, price, day_type, seat_type_id, theater_id)
+		VALUES ($1, 50000, $2, $3, $4)
+	`, pricingID, entities.DayTypeWeekday, seatTypeID, theaterID)
+	exec(`
+		INSERT INTO showtimes (id, status, time, expired_at, movie_id, studio_id, theater_id, seat_pricing_id)
+		VALUES ($1, true, $2, $3, $4, $5, $6, $7)
+	`, f.showtimeID, showtimeAt, showtimeAt.Add(2*time.Hour), movieID, studioID, theaterID, pricingID)
+	for i := 1; i <= seatCount; i++ {
+		seatID := uuid.New()
+		exec(`
+			INSERT INTO seats (id, row, number, active, studio_id, seat_type_id, grid_row, grid_column)
+			VALUES ($1, 'A', $2, true, $3, $4, 1, $2)
+		`, seatID, i, studioID, seatTypeID)
+		f.seatIDs = append(f.seatIDs, seatID)
+	}
+	exec(`INSERT INTO payment_method_types (id, name) VALUES ($1, $2)`, methodTypeID, "type-"+suffix)
+	exec(`
+		INSERT INTO payment_methods (id, code, name, logo_url, active, payment_method_type_id)
+		VALUES ($1, $2, 'Test', '', true, $3)
+	`, f.paymentMethodID, methodCode, methodTypeID)
+
+	payments := payment.NewRegistry()
+	payments.Register(methodCode, payment.NewSimulatedProvider(methodCode, payment.KindDeeplink, "test-secret"))
+	svc, err := RegisterServices(repositories.RegisterRepositories(db), Options{
+		Payments:   payments,
+		Location:   time.UTC,
+		Tickets:    TicketPolicy{Secret: []byte("test-secret")},
+		SeatEvents: realtime.NewLocalBroker(),
+	})
+	if err != nil {
+		t.Fatalf("failed to set up services: %v", err)
+	}
+	f.bookings = svc.BookingService
+
+	return f
+}
+
+// newUser creates a customer to book with.
+func (f *bookingFixture) newUser(t *testing.T) uuid.UUID {
+	t.Helper()
+
+	id := uuid.New()
+	_, err := f.db.ExecContext(context.Background(), `
+		INSERT INTO users (id, name, email, password, role_id) VALUES ($1, 'Test', $2, '', $3)
+	`, id, fmt.Sprintf("%s@example.com", id), f.roleID)
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	return id
+}
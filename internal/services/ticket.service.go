@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+	"github.com/skip2/go-qrcode"
+)
+
+var (
+	ErrTicketNotAvailable  = errors.New("ticket is only available for paid bookings")
+	ErrInvalidTicket       = errors.New("invalid ticket")
+	ErrTicketAlreadyUsed   = errors.New("ticket has already been used")
+	ErrTicketNotValid      = errors.New("booking is no longer valid for entry")
+	ErrCheckInNotOpen      = errors.New("check-in is not open yet")
+	ErrCheckInClosed       = errors.New("check-in has closed")
+	ErrTicketSecretMissing = errors.New("TICKET_SECRET must be set")
+)
+
+const ticketIssuer = "filmix-ticket"
+
+// TicketPolicy configures how tickets are signed and when they can be used.
+type TicketPolicy struct {
+	Secret []byte
+	// OpensBefore is how long before the showtime check-in opens.
+	OpensBefore time.Duration
+	// ClosesAfter is how long after the showtime check-in is still accepted.
+	ClosesAfter time.Duration
+}
+
+type ITicketService interface {
+	IssueTicket(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID) (string, error)
+	RenderTicketQR(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID) ([]byte, error)
+	CheckIn(ctx context.Context, token string, staffID uuid.UUID) (*entities.Transaction, error)
+}
+
+type TicketService struct {
+	bookingRepo repositories.IBookingRepository
+	policy      TicketPolicy
+}
+
+// NewTicketService fails with ErrTicketSecretMissing when the policy has no
+// secret to sign tickets with.
+func NewTicketService(bookingRepo repositories.IBookingRepository, policy TicketPolicy) (ITicketService, error) {
+	if len(policy.Secret) == 0 {
+		return nil, ErrTicketSecretMissing
+	}
+
+	return &TicketService{
+		bookingRepo: bookingRepo,
+		policy:      policy,
+	}, nil
+}
+
+// ticketClaims is the payload of a ticket token. The subject is the booking ID
+// and HolderID who the ticket admits; tokens without one admit the owner.
+type ticketClaims struct {
+	ShowtimeID string `json:"sid"`
+	HolderID   string `json:"hid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// IssueTicket returns the signed ticket token for the seats of a paid booking
+// that the user holds: the owner's seats not transferred away, or the seats
+// transferred to them. Tokens carry no secret state, so issuing one again
+// yields an equally valid ticket.
+func (s *TicketService) IssueTicket(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID) (string, error) {
+	booking, err := s.bookingRepo.FindByID(ctx, bookingID)
+	if err != nil {
+		return "", ErrBookingNotFound
+	}
+
+	held := heldItems(booking, userID)
+	if booking.UserID != userID && len(held) == 0 {
+		return "", ErrBookingNotFound
+	}
+
+	if booking.Status != entities.TransactionStatusPaid && booking.Status != entities.TransactionStatusUsed {
+		return "", ErrTicketNotAvailable
+	}
+	if unpaidAmendment(booking) || len(held) == 0 {
+		return "", ErrTicketNotAvailable
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, ticketClaims{
+		ShowtimeID: booking.ShowtimeID.String(),
+		HolderID:   userID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   ticketIssuer,
+			Subject:  booking.ID.String(),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	})
+
+	return token.SignedString(s.policy.Secret)
+}
+
+// RenderTicketQR encodes the booking's ticket token as a QR code PNG.
+func (s *TicketService) RenderTicketQR(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID) ([]byte, error) {
+	token, err := s.IssueTicket(ctx, bookingID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := qrcode.Encode(token, qrcode.Medium, 512)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render ticket QR: %w", err)
+	}
+
+	return png, nil
+}
+
+// CheckIn admits the holder of a ticket token with the seats they hold. The
+// booking must be paid and the showtime within its check-in window; each seat
+// gets in once. The returned booking lists only the admitted seats.
+func (s *TicketService) CheckIn(ctx context.Context, token string, staffID uuid.UUID) (*entities.Transaction, error) {
+	var claims ticketClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		return s.policy.Secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(ticketIssuer))
+	if err != nil {
+		return nil, ErrInvalidTicket
+	}
+
+	bookingID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidTicket
+	}
+
+	booking, err := s.bookingRepo.FindByID(ctx, bookingID)
+	if err != nil || booking.ShowtimeID.String() != claims.ShowtimeID {
+		return nil, ErrInvalidTicket
+	}
+
+	holderID := booking.UserID
+	if claims.HolderID != "" {
+		if holderID, err = uuid.Parse(claims.HolderID); err != nil {
+			return nil, ErrInvalidTicket
+		}
+	}
+
+	if err := admissionError(booking.Status); err != nil {
+		return nil, err
+	}
+	if unpaidAmendment(booking) {
+		return nil, ErrTicketNotValid
+	}
+	// A ticket whose seats were all transferred away admits nobody.
+	if err := seatAdmissionError(heldItems(booking, holderID)); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if now.Before(booking.Showtime.Time.Add(-s.policy.OpensBefore)) {
+		return nil, ErrCheckInNotOpen
+	}
+	if now.After(booking.Showtime.Time.Add(s.policy.ClosesAfter)) {
+		return nil, ErrCheckInClosed
+	}
+
+	ok, err := s.bookingRepo.CheckIn(ctx, bookingID, holderID, staffID, entities.StatusChange{
+		Actor:  entities.ActorUser(staffID),
+		Reason: "checked in at the door",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check in: %w", err)
+	}
+	if !ok {
+		// Lost a race with another scan or a cancellation; report which.
+		current, err := s.bookingRepo.FindByID(ctx, bookingID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get booking: %w", err)
+		}
+		if err := admissionError(current.Status); err != nil {
+			return nil, err
+		}
+		if err := seatAdmissionError(heldItems(current, holderID)); err != nil {
+			return nil, err
+		}
+		return nil, ErrTicketNotValid
+	}
+
+	admitted, err := s.bookingRepo.FindByID(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+	admitted.Items = heldItems(admitted, holderID)
+
+	return admitted, nil
+}
+
+// heldItems returns the seats of the booking whose ticket is with userID.
+func heldItems(booking *entities.Transaction, userID uuid.UUID) []entities.TransactionItem {
+	var held []entities.TransactionItem
+	for _, item := range booking.Items {
+		if seatHolder(booking, item) == userID {
+			held = append(held, item)
+		}
+	}
+	return held
+}
+
+// seatHolder returns who holds the ticket of a seat of the booking.
+func seatHolder(booking *entities.Transaction, item entities.TransactionItem) uuid.UUID {
+	if item.HolderID != nil {
+		return *item.HolderID
+	}
+	return booking.UserID
+}
+
+// seatAdmissionError explains why none of the held seats can be admitted, or
+// returns nil if some can.
+func seatAdmissionError(held []entities.TransactionItem) error {
+	if len(held) == 0 {
+		return ErrTicketNotValid
+	}
+	for _, item := range held {
+		if item.CheckedInAt == nil {
+			return nil
+		}
+	}
+	return ErrTicketAlreadyUsed
+}
+
+// unpaidAmendment reports whether a change of the booking's seats is waiting
+// for its difference to be paid. The seats move once it is, so they are left
+// alone until then.
+func unpaidAmendment(booking *entities.Transaction) bool {
+	amendment := outstandingAmendment(booking)
+	return amendment != nil && amendment.Settlement == entities.AmendmentSettlementCharge
+}
+
+// admissionError explains why a booking in the given status cannot be
+// admitted, or returns nil if it can.
+func admissionError(status entities.TransactionStatus) error {
+	switch status {
+	case entities.TransactionStatusPaid:
+		return nil
+	case entities.TransactionStatusUsed:
+		return ErrTicketAlreadyUsed
+	default:
+		return ErrTicketNotValid
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/integrations/notification"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+var (
+	ErrTransferSeatNotFound  = errors.New("seat is not part of this booking")
+	ErrSeatNotHeld           = errors.New("seat's ticket is held by someone else")
+	ErrTicketNotTransferable = errors.New("ticket can no longer be transferred")
+	ErrRecipientNotFound     = errors.New("recipient is not a registered user")
+	ErrInvalidTransfer       = errors.New("invalid ticket transfer")
+	ErrTransferPending       = errors.New("seat already has a pending transfer")
+	ErrTransferNotFound      = errors.New("transfer not found")
+	ErrTransferNotOpen       = errors.New("transfer is no longer open")
+)
+
+type CreateTransferInput struct {
+	BookingID uuid.UUID
+	SeatID    uuid.UUID
+	// UserID is the current holder of the seat's ticket.
+	UserID uuid.UUID
+	Email  string
+}
+
+type ITransferService interface {
+	Create(ctx context.Context, input CreateTransferInput) (*entities.TicketTransfer, error)
+	GetUserTransfers(ctx context.Context, userID uuid.UUID) ([]entities.TicketTransfer, error)
+	GetBookingTransfers(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID) ([]entities.TicketTransfer, error)
+	Accept(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.TicketTransfer, error)
+	Decline(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.TicketTransfer, error)
+	Cancel(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.TicketTransfer, error)
+}
+
+type TransferService struct {
+	transferRepo repositories.ITransferRepository
+	bookingRepo  repositories.IBookingRepository
+	userRepo     repositories.IUserRepository
+	notifier     notification.Notifier
+	location     *time.Location
+}
+
+func NewTransferService(
+	transferRepo repositories.ITransferRepository,
+	bookingRepo repositories.IBookingRepository,
+	userRepo repositories.IUserRepository,
+	notifier notification.Notifier,
+	location *time.Location,
+) ITransferService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &TransferService{
+		transferRepo: transferRepo,
+		bookingRepo:  bookingRepo,
+		userRepo:     userRepo,
+		notifier:     notifier,
+		location:     location,
+	}
+}
+
+// Create offers the ticket of one seat to another registered user, who can
+// accept or decline it. Only the seat's current holder can pass it on, and
+// only while the booking is paid, the seat not yet admitted and the showtime
+// not started.
+func (s *TransferService) Create(ctx context.Context, input CreateTransferInput) (*entities.TicketTransfer, error) {
+	booking, err := s.bookingRepo.FindByID(ctx, input.BookingID)
+	if err != nil {
+		return nil, ErrBookingNotFound
+	}
+
+	held := heldItems(booking, input.UserID)
+	if booking.UserID != input.UserID && len(held) == 0 {
+		return nil, ErrBookingNotFound
+	}
+
+	var item *entities.TransactionItem
+	for i := range booking.Items {
+		if booking.Items[i].SeatID == input.SeatID {
+			item = &booking.Items[i]
+			break
+		}
+	}
+	if item == nil {
+		return nil, ErrTransferSeatNotFound
+	}
+	if seatHolder(booking, *item) != input.UserID {
+		return nil, ErrSeatNotHeld
+	}
+
+	if booking.Status != entities.TransactionStatusPaid || unpaidAmendment(booking) ||
+		item.CheckedInAt != nil || !booking.Showtime.Time.After(time.Now()) {
+		return nil, ErrTicketNotTransferable
+	}
+
+	recipient, err := s.userRepo.FindByEmail(ctx, strings.TrimSpace(input.Email))
+	if err != nil {
+		return nil, ErrRecipientNotFound
+	}
+	if recipient.ID == input.UserID {
+		return nil, ErrInvalidTransfer
+	}
+
+	transfer := &entities.TicketTransfer{
+		ID:                uuid.New(),
+		TransactionID:     booking.ID,
+		TransactionItemID: item.ID,
+		FromUserID:        input.UserID,
+		ToUserID:          recipient.ID,
+		Status:            entities.TransferStatusPending,
+	}
+
+	created, err := s.transferRepo.Create(ctx, transfer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transfer: %w", err)
+	}
+	if !created {
+		return nil, ErrTransferPending
+	}
+
+	transfer, err = s.transferRepo.FindByID(ctx, transfer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer: %w", err)
+	}
+
+	// The transfer stands even if the notice can't be delivered; it is listed
+	// under the recipient's transfers either way.
+	_ = s.notifier.Notify(ctx, notification.Message{
+		UserID:  transfer.ToUserID,
+		Subject: fmt.Sprintf("%s sent you a ticket for %s", transfer.FromUser.Name, transfer.Showtime.Movie.Title),
+		Body: fmt.Sprintf("%s wants to give you seat %s for %s on %s. Accept it to get the ticket.",
+			transfer.FromUser.Name, seatLabel(transfer.Seat), transfer.Showtime.Movie.Title,
+			transfer.Showtime.Time.In(s.location).Format("Mon 2 Jan 15:04")),
+	})
+
+	return transfer, nil
+}
+
+// GetUserTransfers returns the transfers a user sent or received.
+func (s *TransferService) GetUserTransfers(ctx context.Context, userID uuid.UUID) ([]entities.TicketTransfer, error) {
+	return s.transferRepo.FindByUserID(ctx, userID)
+}
+
+// GetBookingTransfers returns the chain of custody of a booking's seats. Only
+// the booking owner can see it.
+func (s *TransferService) GetBookingTransfers(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID) ([]entities.TicketTransfer, error) {
+	booking, err := s.bookingRepo.FindByID(ctx, bookingID)
+	if err != nil || booking.UserID != userID {
+		return nil, ErrBookingNotFound
+	}
+
+	return s.transferRepo.FindByTransactionID(ctx, bookingID)
+}
+
+// Accept makes the recipient the holder of the seat's ticket; the sender's
+// ticket no longer admits it. A transfer whose seat changed hands, was
+// admitted or was cancelled in the meantime is closed instead.
+func (s *TransferService) Accept(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.TicketTransfer, error) {
+	transfer, err := s.transferRepo.FindByID(ctx, id)
+	if err != nil || transfer.ToUserID != userID {
+		return nil, ErrTransferNotFound
+	}
+	if transfer.Status != entities.TransferStatusPending {
+		return nil, ErrTransferNotOpen
+	}
+	if !transfer.Showtime.Time.After(time.Now()) {
+		return nil, ErrTicketNotTransferable
+	}
+
+	ok, err := s.transferRepo.Accept(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept transfer: %w", err)
+	}
+	if !ok {
+		return nil, ErrTransferNotOpen
+	}
+
+	return s.respond(ctx, id, "accepted")
+}
+
+// Decline turns down a transfer; the seat stays with the sender.
+func (s *TransferService) Decline(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.TicketTransfer, error) {
+	transfer, err := s.transferRepo.FindByID(ctx, id)
+	if err != nil || transfer.ToUserID != userID {
+		return nil, ErrTransferNotFound
+	}
+
+	if err := s.close(ctx, id, entities.TransferStatusDeclined); err != nil {
+		return nil, err
+	}
+
+	return s.respond(ctx, id, "declined")
+}
+
+// Cancel withdraws a transfer the user sent before it is answered.
+func (s *TransferService) Cancel(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.TicketTransfer, error) {
+	transfer, err := s.transferRepo.FindByID(ctx, id)
+	if err != nil || transfer.FromUserID != userID {
+		return nil, ErrTransferNotFound
+	}
+
+	if err := s.close(ctx, id, entities.TransferStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	return s.transferRepo.FindByID(ctx, id)
+}
+
+func (s *TransferService) close(ctx context.Context, id uuid.UUID, status string) error {
+	ok, err := s.transferRepo.Close(ctx, id, status)
+	if err != nil {
+		return fmt.Errorf("failed to close transfer: %w", err)
+	}
+	if !ok {
+		return ErrTransferNotOpen
+	}
+	return nil
+}
+
+// respond tells the sender how the recipient answered and returns the
+// updated transfer.
+func (s *TransferService) respond(ctx context.Context, id uuid.UUID, answer string) (*entities.TicketTransfer, error) {
+	transfer, err := s.transferRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer: %w", err)
+	}
+
+	_ = s.notifier.Notify(ctx, notification.Message{
+		UserID:  transfer.FromUserID,
+		Subject: fmt.Sprintf("%s %s your ticket", transfer.ToUser.Name, answer),
+		Body: fmt.Sprintf("%s %s seat %s for %s on %s.",
+			transfer.ToUser.Name, answer, seatLabel(transfer.Seat), transfer.Showtime.Movie.Title,
+			transfer.Showtime.Time.In(s.location).Format("Mon 2 Jan 15:04")),
+	})
+
+	return transfer, nil
+}
+
+func seatLabel(seat *entities.Seat) string {
+	return fmt.Sprintf("%s%d", seat.Row, seat.Number)
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/senatroxx/filmix-backend/internal/database/entities"
+	"github.com/senatroxx/filmix-backend/internal/integrations/notification"
+	"github.com/senatroxx/filmix-backend/internal/integrations/realtime"
+	"github.com/senatroxx/filmix-backend/internal/repositories"
+)
+
+var (
+	ErrAlreadyWaitlisted     = errors.New("already on the waitlist for this showtime")
+	ErrSeatsStillAvailable   = errors.New("showtime still has enough seats available")
+	ErrShowtimeStarted       = errors.New("showtime has already started")
+	ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+	ErrWaitlistEntryNotOpen  = errors.New("waitlist entry is no longer open")
+)
+
+type JoinWaitlistInput struct {
+	UserID     uuid.UUID
+	ShowtimeID uuid.UUID
+	SeatCount  int
+}
+
+type IWaitlistService interface {
+	Join(ctx context.Context, input JoinWaitlistInput) (*entities.WaitlistEntry, error)
+	GetUserEntries(ctx context.Context, userID uuid.UUID) ([]entities.WaitlistEntry, error)
+	Leave(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.WaitlistEntry, error)
+	ProcessWaitlists(ctx context.Context) (int, error)
+}
+
+type WaitlistService struct {
+	waitlistRepo   repositories.IWaitlistRepository
+	showtimeRepo   repositories.IShowtimeRepository
+	seatRepo       repositories.ISeatRepository
+	bookingService IBookingService
+	notifier       notification.Notifier
+	seatEvents     realtime.Publisher
+	holdTTL        time.Duration
+	location       *time.Location
+}
+
+func NewWaitlistService(
+	waitlistRepo repositories.IWaitlistRepository,
+	showtimeRepo repositories.IShowtimeRepository,
+	seatRepo repositories.ISeatRepository,
+	bookingService IBookingService,
+	notifier notification.Notifier,
+	seatEvents realtime.Publisher,
+	holdTTL time.Duration,
+	location *time.Location,
+) IWaitlistService {
+	if holdTTL <= 0 {
+		holdTTL = 10 * time.Minute
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &WaitlistService{
+		waitlistRepo:   waitlistRepo,
+		showtimeRepo:   showtimeRepo,
+		seatRepo:       seatRepo,
+		bookingService: bookingService,
+		notifier:       notifier,
+		seatEvents:     seatEvents,
+		holdTTL:        holdTTL,
+		location:       location,
+	}
+}
+
+// Join puts a user in line for a showtime that doesn't have seatCount seats
+// left. They are offered seats in the order they joined.
+func (s *WaitlistService) Join(ctx context.Context, input JoinWaitlistInput) (*entities.WaitlistEntry, error) {
+	if input.SeatCount < 1 || input.SeatCount > MaxSuggestedSeats {
+		return nil, ErrInvalidSeatCount
+	}
+
+	showtime, err := s.showtimeRepo.FindByID(ctx, input.ShowtimeID)
+	if err != nil {
+		return nil, ErrShowtimeNotFound
+	}
+	if !showtime.Time.After(time.Now()) {
+		return nil, ErrShowtimeStarted
+	}
+
+	seats, err := s.seatRepo.FindByStudioID(ctx, showtime.StudioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seats: %w", err)
+	}
+	bookedIDs, err := s.seatRepo.FindBookedSeatIDs(ctx, showtime.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booked seats: %w", err)
+	}
+
+	// Taken seats can include ones taken off sale since they were booked or
+	// blocked, which aren't among the seats for sale, so the free seats are
+	// counted one by one rather than by subtraction.
+	taken := make(map[uuid.UUID]bool, len(bookedIDs))
+	for _, id := range bookedIDs {
+		taken[id] = true
+	}
+	free := 0
+	for _, seat := range seats {
+		if !taken[seat.ID] {
+			free++
+		}
+	}
+	if free >= input.SeatCount {
+		return nil, ErrSeatsStillAvailable
+	}
+
+	entry := &entities.WaitlistEntry{
+		ID:         uuid.New(),
+		ShowtimeID: input.ShowtimeID,
+		UserID:     input.UserID,
+		SeatCount:  input.SeatCount,
+		Status:     entities.WaitlistStatusWaiting,
+	}
+
+	ok, err := s.waitlistRepo.Create(ctx, entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join waitlist: %w", err)
+	}
+	if !ok {
+		return nil, ErrAlreadyWaitlisted
+	}
+
+	return s.waitlistRepo.FindByID(ctx, entry.ID)
+}
+
+func (s *WaitlistService) GetUserEntries(ctx context.Context, userID uuid.UUID) ([]entities.WaitlistEntry, error) {
+	return s.waitlistRepo.FindByUserID(ctx, userID)
+}
+
+// Leave takes a user off the waitlist, giving back any seats held for them.
+func (s *WaitlistService) Leave(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entities.WaitlistEntry, error) {
+	entry, err := s.waitlistRepo.FindByID(ctx, id)
+	if err != nil || entry.UserID != userID {
+		return nil, ErrWaitlistEntryNotFound
+	}
+
+	ok, err := s.waitlistRepo.Cancel(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to leave waitlist: %w", err)
+	}
+	if !ok {
+		return nil, ErrWaitlistEntryNotOpen
+	}
+
+	var released []uuid.UUID
+	for _, hold := range entry.Holds {
+		if hold.ReleasedAt == nil && hold.ExpiresAt.After(time.Now()) {
+			released = append(released, hold.SeatID)
+		}
+	}
+	publishSeats(ctx, s.seatEvents, entry.ShowtimeID, realtime.SeatReleased, released, nil)
+
+	return s.waitlistRepo.FindByID(ctx, id)
+}
+
+// ProcessWaitlists closes lapsed offers and then, for every showtime with
+// people waiting, offers priority holds to the front of the line for as long
+// as seats are free. Seats come free when bookings expire or are cancelled,
+// when earlier holds lapse, or when staff release a seat block. The line is
+// strictly first come, first served: if the first entry can't be served,
+// nobody behind it is. It returns how many offers were made.
+func (s *WaitlistService) ProcessWaitlists(ctx context.Context) (int, error) {
+	if _, err := s.waitlistRepo.LapseEntries(ctx); err != nil {
+		return 0, fmt.Errorf("failed to lapse waitlist entries: %w", err)
+	}
+
+	showtimeIDs, err := s.waitlistRepo.FindWaitingShowtimeIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get waitlisted showtimes: %w", err)
+	}
+
+	offered := 0
+	var notifyErrs []error
+	for _, showtimeID := range showtimeIDs {
+		for {
+			entry, err := s.waitlistRepo.NextWaiting(ctx, showtimeID)
+			if err != nil {
+				return offered, fmt.Errorf("failed to get next waitlist entry: %w", err)
+			}
+			if entry == nil {
+				break
+			}
+
+			err = s.bookingService.CreatePriorityHold(ctx, entry, time.Now().Add(s.holdTTL))
+			if errors.Is(err, ErrNotEnoughSeats) || errors.Is(err, ErrSeatsNotAvailable) {
+				break
+			}
+			if errors.Is(err, ErrWaitlistEntryNotOpen) {
+				// The user left the line meanwhile; serve the next one.
+				continue
+			}
+			if err != nil {
+				return offered, err
+			}
+			offered++
+
+			if err := s.notifyOffer(ctx, entry.ID); err != nil {
+				notifyErrs = append(notifyErrs, err)
+			}
+		}
+	}
+
+	return offered, errors.Join(notifyErrs...)
+}
+
+func (s *WaitlistService) notifyOffer(ctx context.Context, entryID uuid.UUID) error {
+	entry, err := s.waitlistRepo.FindByID(ctx, entryID)
+	if err != nil {
+		return fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+
+	seats := make([]string, len(entry.Holds))
+	for i, hold := range entry.Holds {
+		seats[i] = fmt.Sprintf("%s%d", hold.Seat.Row, hold.Seat.Number)
+	}
+
+	err = s.notifier.Notify(ctx, notification.Message{
+		UserID:  entry.UserID,
+		Subject: fmt.Sprintf("Seats available for %s", entry.Showtime.Movie.Title),
+		Body: fmt.Sprintf("Seats %s for %s on %s are held for you until %s. Book them before then to keep them.",
+			strings.Join(seats, ", "), entry.Showtime.Movie.Title,
+			entry.Showtime.Time.In(s.location).Format("Mon 2 Jan 15:04"), entry.OfferExpiresAt.In(s.location).Format("15:04")),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to notify user %s of waitlist offer: %w", entry.UserID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/senatroxx/filmix-backend/internal/services"
+)
+
+// ExpiryWorker periodically sweeps pending bookings whose payment window has
+// lapsed and moves them to the expired status, freeing their seats. Booking
+// changes whose extra cost went unpaid are expired the same way.
+type ExpiryWorker struct {
+	bookingService services.IBookingService
+	interval       time.Duration
+	batchSize      int
+	logger         zerolog.Logger
+
+	totalExpired int
+}
+
+func NewExpiryWorker(bookingService services.IBookingService, interval time.Duration, batchSize int, logger zerolog.Logger) *ExpiryWorker {
+	return &ExpiryWorker{
+		bookingService: bookingService,
+		interval:       interval,
+		batchSize:      batchSize,
+		logger:         logger.With().Str("worker", "expiry").Logger(),
+	}
+}
+
+// Run sweeps once immediately and then on every tick until ctx is cancelled.
+func (w *ExpiryWorker) Run(ctx context.Context) {
+	w.logger.Info().Msgf("Expiry worker started (interval %s, batch %d)", w.interval, w.batchSize)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.sweep(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info().Msg("Expiry worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *ExpiryWorker) sweep(ctx context.Context) {
+	start := time.Now()
+	expired, err := w.bookingService.ExpireStaleBookings(ctx, w.batchSize)
+	elapsed := time.Since(start)
+	w.totalExpired += expired
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.Error().Err(err).
+			Int("expired", expired).
+			Dur("duration", elapsed).
+			Msgf("Expiry sweep failed after expiring %d bookings: %v", expired, err)
+		return
+	}
+
+	event := w.logger.Debug()
+	if expired > 0 {
+		event = w.logger.Info()
+	}
+	event.
+		Int("expired", expired).
+		Int("expired_total", w.totalExpired).
+		Dur("duration", elapsed).
+		Msgf("Expiry sweep: %d bookings expired in %s (%d total)", expired, elapsed, w.totalExpired)
+
+	amendments, err := w.bookingService.ExpireStaleAmendments(ctx, w.batchSize)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.Error().Err(err).
+			Int("expired", amendments).
+			Msgf("Booking change sweep failed after expiring %d changes", amendments)
+		return
+	}
+	if amendments > 0 {
+		w.logger.Info().
+			Int("expired", amendments).
+			Msgf("Expiry sweep: %d unpaid booking changes expired", amendments)
+	}
+}
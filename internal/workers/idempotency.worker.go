@@ -0,0 +1,61 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/senatroxx/filmix-backend/internal/services"
+)
+
+// IdempotencyPurgeWorker periodically deletes idempotency keys whose replay
+// window has passed.
+type IdempotencyPurgeWorker struct {
+	idempotencyService services.IIdempotencyService
+	interval           time.Duration
+	logger             zerolog.Logger
+}
+
+func NewIdempotencyPurgeWorker(idempotencyService services.IIdempotencyService, interval time.Duration, logger zerolog.Logger) *IdempotencyPurgeWorker {
+	return &IdempotencyPurgeWorker{
+		idempotencyService: idempotencyService,
+		interval:           interval,
+		logger:             logger.With().Str("worker", "idempotency_purge").Logger(),
+	}
+}
+
+// Run purges once immediately and then on every tick until ctx is cancelled.
+func (w *IdempotencyPurgeWorker) Run(ctx context.Context) {
+	w.logger.Info().Msgf("Idempotency purge worker started (interval %s)", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info().Msg("Idempotency purge worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *IdempotencyPurgeWorker) purge(ctx context.Context) {
+	purged, err := w.idempotencyService.PurgeExpired(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.Error().Err(err).Msg("Idempotency purge failed")
+		return
+	}
+
+	event := w.logger.Debug()
+	if purged > 0 {
+		event = w.logger.Info()
+	}
+	event.Int64("purged", purged).Msgf("Idempotency purge: %d expired keys deleted", purged)
+}
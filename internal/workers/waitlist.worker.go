@@ -0,0 +1,62 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/senatroxx/filmix-backend/internal/services"
+)
+
+// WaitlistWorker periodically offers seats that came free to the users
+// waiting for them and closes offers that were not taken up in time.
+type WaitlistWorker struct {
+	waitlistService services.IWaitlistService
+	interval        time.Duration
+	logger          zerolog.Logger
+}
+
+func NewWaitlistWorker(waitlistService services.IWaitlistService, interval time.Duration, logger zerolog.Logger) *WaitlistWorker {
+	return &WaitlistWorker{
+		waitlistService: waitlistService,
+		interval:        interval,
+		logger:          logger.With().Str("worker", "waitlist").Logger(),
+	}
+}
+
+// Run processes the waitlists once immediately and then on every tick until
+// ctx is cancelled.
+func (w *WaitlistWorker) Run(ctx context.Context) {
+	w.logger.Info().Msgf("Waitlist worker started (interval %s)", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info().Msg("Waitlist worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *WaitlistWorker) process(ctx context.Context) {
+	offered, err := w.waitlistService.ProcessWaitlists(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.Error().Err(err).Int("offered", offered).Msgf("Waitlist run failed after %d offers: %v", offered, err)
+		return
+	}
+
+	event := w.logger.Debug()
+	if offered > 0 {
+		event = w.logger.Info()
+	}
+	event.Int("offered", offered).Msgf("Waitlist run: %d priority holds offered", offered)
+}